@@ -13,6 +13,7 @@ import (
 	"github.com/hoshea/orion-backend/internal/api"
 	"github.com/hoshea/orion-backend/internal/infra/config"
 	"github.com/hoshea/orion-backend/internal/infra/database"
+	"github.com/hoshea/orion-backend/internal/infra/metrics"
 )
 
 func main() {
@@ -21,6 +22,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
 
 	// 初始化数据库
 	db, err := database.Open(cfg.Database)
@@ -56,6 +60,24 @@ func main() {
 		}
 	}()
 
+	// 监控指标单独监听，不随业务端口对外开放
+	var metricsSrv *http.Server
+	if cfg.Server.MetricsEnabled {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsSrv = &http.Server{
+			Addr:              cfg.Server.MetricsAddr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			log.Printf("Metrics server starting on %s", cfg.Server.MetricsAddr)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start metrics server: %v", err)
+			}
+		}()
+	}
+
 	// 优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -66,6 +88,11 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			log.Printf("Metrics server forced to shutdown: %v", err)
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/hoshea/orion-backend/internal/infra/google"
+	"github.com/hoshea/orion-backend/internal/infra/stt"
 )
 
 func main() {
@@ -48,7 +49,7 @@ func testSTTAPI(ctx context.Context, apiKey string) {
 	defer client.Close()
 
 	audioStream := make(chan []byte, 1)
-	results := make(chan google.RecognitionResult, 10)
+	results := make(chan stt.RecognitionResult, 10)
 
 	// 构造 0.5 秒的静音音频 (16kHz, 16bit LINEAR16)
 	silentSamples := make([]byte, 16000) // 8000 采样点 * 2 字节
@@ -58,7 +59,7 @@ func testSTTAPI(ctx context.Context, apiKey string) {
 	err = client.StreamingRecognize(
 		ctx,
 		audioStream,
-		google.StreamingRecognizeConfig{
+		stt.StreamingRecognizeConfig{
 			LanguageCode:               "en-US",
 			SampleRateHertz:            16000,
 			EnableAutomaticPunctuation: true,
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -52,7 +53,7 @@ func (h *ActivityHandler) ListActivities(c *gin.Context) {
 	}
 
 	// 调用服务层
-	activities, err := h.service.ListActivities(statusFilter)
+	activities, err := h.service.ListActivities(c.GetString("organization_id"), statusFilter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
@@ -90,7 +91,7 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 	}
 
 	// 调用服务层创建活动
-	activity, err := h.service.CreateActivity(&req)
+	activity, err := h.service.CreateActivity(c.GetString("organization_id"), &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    "CREATE_FAILED",
@@ -116,7 +117,7 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 func (h *ActivityHandler) GetActivity(c *gin.Context) {
 	id := c.Param("id")
 
-	activity, err := h.service.GetActivity(id)
+	activity, err := h.service.GetActivity(c.GetString("organization_id"), id)
 	if err != nil {
 		if err == domain.ErrActivityNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -148,6 +149,7 @@ func (h *ActivityHandler) GetActivity(c *gin.Context) {
 // @Success 200 {object} domain.Activity
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Router /api/v1/activities/{id} [put]
 func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 	id := c.Param("id")
@@ -162,7 +164,7 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 		return
 	}
 
-	activity, err := h.service.UpdateActivity(id, &req)
+	activity, err := h.service.UpdateActivity(c.GetString("organization_id"), id, &req)
 	if err != nil {
 		if err == domain.ErrActivityNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -180,6 +182,14 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, domain.ErrActivityStatusChanged) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Code:    "ACTIVITY_STATUS_CHANGED",
+				Message: domain.ErrActivityStatusChanged.Error(),
+				Data:    nil,
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    "UPDATE_FAILED",
 			Message: err.Error(),
@@ -205,7 +215,7 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 func (h *ActivityHandler) DeleteActivity(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.service.DeleteActivity(id)
+	err := h.service.DeleteActivity(c.GetString("organization_id"), id)
 	if err != nil {
 		if err == domain.ErrActivityNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -240,7 +250,7 @@ func (h *ActivityHandler) DeleteActivity(c *gin.Context) {
 func (h *ActivityHandler) PublishActivity(c *gin.Context) {
 	id := c.Param("id")
 
-	activity, err := h.service.PublishActivity(id)
+	activity, err := h.service.PublishActivity(c.GetString("organization_id"), id)
 	if err != nil {
 		if err == domain.ErrActivityNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -275,7 +285,7 @@ func (h *ActivityHandler) PublishActivity(c *gin.Context) {
 func (h *ActivityHandler) CloseActivity(c *gin.Context) {
 	id := c.Param("id")
 
-	activity, err := h.service.CloseActivity(id)
+	activity, err := h.service.CloseActivity(c.GetString("organization_id"), id)
 	if err != nil {
 		if err == domain.ErrActivityNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -296,6 +306,28 @@ func (h *ActivityHandler) CloseActivity(c *gin.Context) {
 	c.JSON(http.StatusOK, activity)
 }
 
+// ListTransitions 获取活动状态变更记录
+// @Summary 获取活动状态变更记录
+// @Description 包括手动发布、关闭以及调度器自动发布、自动关闭
+// @Tags activities
+// @Produce json
+// @Param id path string true "活动 ID"
+// @Success 200 {array} domain.ActivityTransition
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/transitions [get]
+func (h *ActivityHandler) ListTransitions(c *gin.Context) {
+	transitions, err := h.service.ListTransitions(c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		if err == domain.ErrActivityNotFound {
+			writeError(c, http.StatusNotFound, "ACTIVITY_NOT_FOUND", "活动不存在")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "获取状态变更记录失败")
+		return
+	}
+	c.JSON(http.StatusOK, transitions)
+}
+
 // ErrorResponse 错误响应结构
 type ErrorResponse struct {
 	Code    string      `json:"code"`
@@ -1,13 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
 )
 
-// AuthHandler 管理员认证接口
+// AuthHandler 管理后台认证接口
 type AuthHandler struct {
 	authService *app.AuthService
 }
@@ -21,6 +23,7 @@ func NewAuthHandler(authService *app.AuthService) *AuthHandler {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Device   string `json:"device"` // 可选，客户端自定义的设备名称，便于在会话列表中识别
 }
 
 // RefreshRequest 刷新令牌请求体
@@ -40,8 +43,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.Authenticate(req.Username, req.Password)
+	meta := sessionMetadata(c)
+	meta.Device = req.Device
+	tokens, err := h.authService.Authenticate(req.Username, req.Password, meta)
 	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "登录失败，请稍后重试",
+				"data":    nil,
+			})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    "UNAUTHORIZED",
 			"message": err.Error(),
@@ -65,15 +78,111 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.Refresh(req.RefreshToken)
+	tokens, err := h.authService.Refresh(req.RefreshToken, sessionMetadata(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    "UNAUTHORIZED",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		switch {
+		case errors.Is(err, domain.ErrRefreshTokenReused):
+			writeError(c, http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", err.Error())
+		case errors.Is(err, domain.ErrRefreshTokenInvalid):
+			writeError(c, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		default:
+			writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "刷新令牌失败，请稍后重试")
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, tokens)
 }
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 注销刷新令牌所属的会话；已签发的访问令牌在过期前仍然有效
+// @Tags auth
+// @Accept json
+// @Param body body RefreshRequest true "刷新令牌"
+// @Success 204
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求参数错误")
+		return
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "退出登录失败，请稍后重试")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListSessions 列出当前用户的登录会话
+// @Summary 获取我的登录会话
+// @Tags auth
+// @Produce json
+// @Success 200 {array} domain.SessionInfo
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.authService.ListSessions(c.GetString("user_id"), c.GetString("session_id"))
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "获取会话失败")
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession 注销当前用户的指定会话
+// @Summary 注销指定会话
+// @Tags auth
+// @Param sessionId path string true "会话 ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/auth/sessions/{sessionId}/revoke [post]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	err := h.authService.RevokeSession(c.GetString("user_id"), c.Param("sessionId"))
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			writeError(c, http.StatusNotFound, "SESSION_NOT_FOUND", "会话不存在")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "注销会话失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions 注销当前用户的全部会话
+// @Summary 注销全部会话
+// @Description 包括当前会话，所有设备需重新登录
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Router /api/v1/auth/sessions/revoke [post]
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	count, err := h.authService.RevokeAllSessions(c.GetString("user_id"))
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "注销会话失败")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": count})
+}
+
+// JWKS 公开访问令牌验证公钥
+// @Summary 获取 JWKS
+// @Description 返回当前签名公钥及轮换期间仍有效的旧公钥；使用 HS256 共享密钥时为空集合
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+// sessionMetadata 从请求中提取客户端信息
+func sessionMetadata(c *gin.Context) domain.SessionMetadata {
+	return domain.SessionMetadata{
+		UserAgent: c.GetHeader("User-Agent"),
+		IP:        c.ClientIP(),
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// GlossaryHandler 活动术语表接口
+type GlossaryHandler struct {
+	service *app.GlossaryService
+}
+
+// NewGlossaryHandler 创建术语表处理器
+func NewGlossaryHandler(service *app.GlossaryService) *GlossaryHandler {
+	return &GlossaryHandler{service: service}
+}
+
+// ListTerms 列出活动术语
+// @Summary 获取活动术语表
+// @Tags glossary
+// @Produce json
+// @Param id path string true "活动 ID"
+// @Success 200 {array} domain.GlossaryTerm
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/glossary [get]
+func (h *GlossaryHandler) ListTerms(c *gin.Context) {
+	terms, err := h.service.ListTerms(c.Param("id"))
+	if err != nil {
+		writeGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, terms)
+}
+
+// CreateTerm 新增术语
+// @Summary 新增术语
+// @Description 术语会作为识别短语提示，并在译文中替换为指定译法
+// @Tags glossary
+// @Accept json
+// @Produce json
+// @Param id path string true "活动 ID"
+// @Param term body domain.CreateGlossaryTermRequest true "术语"
+// @Success 201 {object} domain.GlossaryTerm
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/glossary [post]
+func (h *GlossaryHandler) CreateTerm(c *gin.Context) {
+	var req domain.CreateGlossaryTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求数据格式错误: "+err.Error())
+		return
+	}
+
+	term, err := h.service.CreateTerm(c.Param("id"), &req)
+	if err != nil {
+		writeGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, term)
+}
+
+// UpdateTerm 更新术语
+// @Summary 更新术语
+// @Tags glossary
+// @Accept json
+// @Produce json
+// @Param id path string true "活动 ID"
+// @Param termId path string true "术语 ID"
+// @Param term body domain.UpdateGlossaryTermRequest true "术语"
+// @Success 200 {object} domain.GlossaryTerm
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/glossary/{termId} [put]
+func (h *GlossaryHandler) UpdateTerm(c *gin.Context) {
+	var req domain.UpdateGlossaryTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求数据格式错误: "+err.Error())
+		return
+	}
+
+	term, err := h.service.UpdateTerm(c.Param("id"), c.Param("termId"), &req)
+	if err != nil {
+		writeGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, term)
+}
+
+// DeleteTerm 删除术语
+// @Summary 删除术语
+// @Tags glossary
+// @Param id path string true "活动 ID"
+// @Param termId path string true "术语 ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/glossary/{termId} [delete]
+func (h *GlossaryHandler) DeleteTerm(c *gin.Context) {
+	if err := h.service.DeleteTerm(c.Param("id"), c.Param("termId")); err != nil {
+		writeGlossaryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeGlossaryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrActivityNotFound):
+		writeError(c, http.StatusNotFound, "ACTIVITY_NOT_FOUND", "活动不存在")
+	case errors.Is(err, domain.ErrGlossaryTermNotFound):
+		writeError(c, http.StatusNotFound, "GLOSSARY_TERM_NOT_FOUND", "术语不存在")
+	case errors.Is(err, domain.ErrGlossaryTermExists):
+		writeError(c, http.StatusConflict, "GLOSSARY_TERM_EXISTS", "术语已存在")
+	case errors.Is(err, domain.ErrUnsupportedLanguage):
+		writeError(c, http.StatusBadRequest, "INVALID_LANGUAGE", err.Error())
+	default:
+		writeError(c, http.StatusBadRequest, "GLOSSARY_UPDATE_FAILED", err.Error())
+	}
+}
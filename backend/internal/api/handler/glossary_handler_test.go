@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+	memrepo "github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+// glossaryTestRepo 内存术语表，source 重复时返回 ErrGlossaryTermExists
+type glossaryTestRepo struct {
+	terms []*domain.GlossaryTerm
+}
+
+func (r *glossaryTestRepo) CreateTerm(_ context.Context, term *domain.GlossaryTerm) error {
+	for _, existing := range r.terms {
+		if existing.ActivityID == term.ActivityID && existing.Source == term.Source {
+			return domain.ErrGlossaryTermExists
+		}
+	}
+	r.terms = append(r.terms, term)
+	return nil
+}
+
+func (r *glossaryTestRepo) UpdateTerm(context.Context, *domain.GlossaryTerm) error { return nil }
+
+func (r *glossaryTestRepo) DeleteTerm(ctx context.Context, activityID, id string) error {
+	_, err := r.FindTerm(ctx, activityID, id)
+	return err
+}
+
+func (r *glossaryTestRepo) FindTerm(_ context.Context, activityID, id string) (*domain.GlossaryTerm, error) {
+	for _, term := range r.terms {
+		if term.ActivityID == activityID && term.ID == id {
+			return term, nil
+		}
+	}
+	return nil, domain.ErrGlossaryTermNotFound
+}
+
+func (r *glossaryTestRepo) ListTerms(_ context.Context, activityID string) ([]*domain.GlossaryTerm, error) {
+	var result []*domain.GlossaryTerm
+	for _, term := range r.terms {
+		if term.ActivityID == activityID {
+			result = append(result, term)
+		}
+	}
+	return result, nil
+}
+
+func TestGlossaryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	activityRepo := memrepo.NewMemoryActivityRepository()
+	activity := &domain.Activity{ID: "act-glossary", InputLanguage: "zh-CN", TargetLanguages: []string{"en"}}
+	if err := activityRepo.Create(activity); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	repo := &glossaryTestRepo{terms: []*domain.GlossaryTerm{{ID: "term-1", ActivityID: activity.ID, Source: "奥瑞恩"}}}
+	handler := NewGlossaryHandler(app.NewGlossaryService(activityRepo, repo))
+
+	router := gin.New()
+	router.GET("/activities/:id/glossary", handler.ListTerms)
+	router.POST("/activities/:id/glossary", handler.CreateTerm)
+	router.PUT("/activities/:id/glossary/:termId", handler.UpdateTerm)
+	router.DELETE("/activities/:id/glossary/:termId", handler.DeleteTerm)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"list", http.MethodGet, "/activities/act-glossary/glossary", "", http.StatusOK, ""},
+		{"list missing activity", http.MethodGet, "/activities/missing/glossary", "", http.StatusNotFound, "ACTIVITY_NOT_FOUND"},
+		{"create", http.MethodPost, "/activities/act-glossary/glossary", `{"source":"猎户座","translations":{"en":"Orion"}}`, http.StatusCreated, ""},
+		{"create invalid body", http.MethodPost, "/activities/act-glossary/glossary", `{"translations":{}}`, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"create duplicate", http.MethodPost, "/activities/act-glossary/glossary", `{"source":"奥瑞恩"}`, http.StatusConflict, "GLOSSARY_TERM_EXISTS"},
+		{"create bad language", http.MethodPost, "/activities/act-glossary/glossary", `{"source":"云","translations":{"fr":"nuage"}}`, http.StatusBadRequest, "INVALID_LANGUAGE"},
+		{"update", http.MethodPut, "/activities/act-glossary/glossary/term-1", `{"translations":{"en":"Orion"}}`, http.StatusOK, ""},
+		{"update missing term", http.MethodPut, "/activities/act-glossary/glossary/missing", `{}`, http.StatusNotFound, "GLOSSARY_TERM_NOT_FOUND"},
+		{"delete", http.MethodDelete, "/activities/act-glossary/glossary/term-1", "", http.StatusNoContent, ""},
+		{"delete missing term", http.MethodDelete, "/activities/act-glossary/glossary/missing", "", http.StatusNotFound, "GLOSSARY_TERM_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.code != "" {
+				var resp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != tt.code {
+					t.Fatalf("error response = %s, want code %s", w.Body.String(), tt.code)
+				}
+			}
+		})
+	}
+}
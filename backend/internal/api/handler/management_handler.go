@@ -11,6 +11,11 @@ import (
 	"github.com/hoshea/orion-backend/internal/domain"
 )
 
+const (
+	minQRCodeSize = 128
+	maxQRCodeSize = 2048
+)
+
 // ManagementHandler 管理端辅助接口
 type ManagementHandler struct {
 	accessService *app.AccessService
@@ -104,6 +109,9 @@ func (h *ManagementHandler) ListTokens(c *gin.Context) {
 		if token.MaxAudience != nil {
 			item["maxAudience"] = *token.MaxAudience
 		}
+		if token.Type == domain.TokenTypeViewer {
+			item["currentAudience"] = token.CurrentAudience
+		}
 		response = append(response, item)
 	}
 
@@ -122,6 +130,32 @@ func (h *ManagementHandler) GetViewerEntry(c *gin.Context) {
 	c.JSON(http.StatusOK, entry)
 }
 
+// GetViewerEntryQRCode 获取观众入口二维码 PNG，可通过 size 指定边长（128-2048）
+func (h *ManagementHandler) GetViewerEntryQRCode(c *gin.Context) {
+	activityID := c.Param("id")
+	size, err := parseOptionalInt(c.Query("size"))
+	if err != nil || (size != 0 && (size < minQRCodeSize || size > maxQRCodeSize)) {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "size 参数错误（128-2048）")
+		return
+	}
+
+	data, err := h.accessService.ViewerEntryQRCode(activityID, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrActivityNotFound):
+			writeError(c, http.StatusNotFound, "ACTIVITY_NOT_FOUND", "活动不存在")
+		case errors.Is(err, domain.ErrViewerEntryInactive):
+			writeError(c, http.StatusConflict, "VIEWER_ENTRY_INACTIVE", "观众入口未启用，请先生成邀请码")
+		default:
+			writeError(c, http.StatusInternalServerError, "QR_GENERATE_FAILED", "二维码生成失败")
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", data)
+}
+
 // RevokeViewerEntry 撤销观众入口
 func (h *ManagementHandler) RevokeViewerEntry(c *gin.Context) {
 	activityID := c.Param("id")
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// OrganizationHandler 组织管理接口，仅超级管理员可访问
+type OrganizationHandler struct {
+	service     *app.OrganizationService
+	authService *app.AuthService
+}
+
+// NewOrganizationHandler 创建组织处理器
+func NewOrganizationHandler(service *app.OrganizationService, authService *app.AuthService) *OrganizationHandler {
+	return &OrganizationHandler{service: service, authService: authService}
+}
+
+// SwitchOrganizationRequest 切换组织请求体
+type SwitchOrganizationRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// ListOrganizations 列出组织
+// @Summary 获取组织列表
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} domain.Organization
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.service.ListOrganizations()
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+// GetOrganization 获取组织
+// @Summary 获取组织详情
+// @Tags organizations
+// @Produce json
+// @Param orgId path string true "组织 ID"
+// @Success 200 {object} domain.Organization
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{orgId} [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	org, err := h.service.GetOrganization(c.Param("orgId"))
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// CreateOrganization 创建组织
+// @Summary 创建组织
+// @Description 创建后可切换到该组织，再通过 /api/v1/users 为其创建所有者
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body domain.CreateOrganizationRequest true "组织"
+// @Success 201 {object} domain.Organization
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req domain.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求数据格式错误: "+err.Error())
+		return
+	}
+
+	org, err := h.service.CreateOrganization(&req)
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// UpdateOrganization 更新组织
+// @Summary 更新组织名称
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param orgId path string true "组织 ID"
+// @Param organization body domain.UpdateOrganizationRequest true "组织"
+// @Success 200 {object} domain.Organization
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/organizations/{orgId} [put]
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	var req domain.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求数据格式错误: "+err.Error())
+		return
+	}
+
+	org, err := h.service.UpdateOrganization(c.Param("orgId"), &req)
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// SwitchOrganization 切换当前组织
+// @Summary 超级管理员切换组织
+// @Description 轮换刷新令牌并签发携带目标组织的新令牌对，之后的管理接口均作用于该组织
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param orgId path string true "组织 ID"
+// @Param body body SwitchOrganizationRequest true "当前刷新令牌"
+// @Success 200 {object} app.AuthTokens
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{orgId}/switch [post]
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	var req SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求参数错误")
+		return
+	}
+
+	org, err := h.service.GetOrganization(c.Param("orgId"))
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	tokens, err := h.authService.SwitchOrganization(c.GetString("user_id"), req.RefreshToken, org.ID, sessionMetadata(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRefreshTokenReused):
+			writeError(c, http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", err.Error())
+		case errors.Is(err, domain.ErrRefreshTokenInvalid):
+			writeError(c, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		case errors.Is(err, domain.ErrRoleNotAllowed):
+			writeError(c, http.StatusForbidden, "FORBIDDEN", "权限不足")
+		default:
+			writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "切换组织失败，请稍后重试")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func writeOrganizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrOrganizationNotFound):
+		writeError(c, http.StatusNotFound, "ORGANIZATION_NOT_FOUND", "组织不存在")
+	case errors.Is(err, domain.ErrOrganizationExists):
+		writeError(c, http.StatusConflict, "ORGANIZATION_EXISTS", "组织名称已存在")
+	default:
+		writeError(c, http.StatusBadRequest, "ORGANIZATION_UPDATE_FAILED", err.Error())
+	}
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+	ws "github.com/hoshea/orion-backend/internal/infra/websocket"
+)
+
+// audioAckInterval 向演讲者确认已接收音频序列号的最短间隔
+const audioAckInterval = time.Second
+
+var (
+	errSpeakerLanguageMismatch = errors.New("活动已有其他语言的翻译会话")
+	errSpeakerStreamStopped    = errors.New("翻译会话正在结束，请稍后重试")
+)
+
+// speakerStream 单个活动的推流状态
+// 演讲者断线后在宽限期内保留翻译会话与观众订阅，用同一令牌重连即可接管
+type speakerStream struct {
+	activityID string
+	session    *app.PipelineSession
+	forwarded  chan struct{} // 字幕转发结束（会话输出已关闭）时关闭
+	done       chan struct{} // 推流结束、资源释放后关闭
+	closeOnce  sync.Once
+
+	mu       sync.Mutex
+	conn     *ws.Connection // 当前演讲者连接，断线期间为 nil
+	grace    *time.Timer    // 断线宽限期计时
+	stopped  bool           // 已停止（STOP、活动关闭或宽限期到期），不再允许接管
+	sequence app.AudioSequenceTracker
+	ackedAt  time.Time
+}
+
+// currentConn 返回当前演讲者连接，断线期间为 nil
+func (s *speakerStream) currentConn() *ws.Connection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn
+}
+
+// observe 检查音频序列号
+func (s *speakerStream) observe(seq uint32) app.AudioSequenceResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sequence.Observe(seq)
+}
+
+// commit 记录音频块已送入识别，返回距上次 ACK 是否已超过 audioAckInterval
+func (s *speakerStream) commit(seq uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sequence.Commit(seq)
+	return time.Since(s.ackedAt) >= audioAckInterval
+}
+
+// drop 记录音频块未能送入识别
+func (s *speakerStream) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sequence.Drop()
+}
+
+// acked ACK 发送成功后更新节流时间
+func (s *speakerStream) acked() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ackedAt = time.Now()
+}
+
+// lastAccepted 返回最近成功送入识别的音频序列号
+func (s *speakerStream) lastAccepted() (uint32, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sequence.LastAccepted()
+}
+
+// stats 返回累计的音频序列统计
+func (s *speakerStream) stats() app.AudioSequenceTracker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sequence
+}
+
+// attachStream 为演讲者连接创建推流，活动已有推流时接管原会话，resumed 表示接管
+func (h *SpeakerWebSocketHandler) attachStream(auth *domain.AuthPayload, activity *domain.Activity, format domain.AudioFormat, conn *ws.Connection) (stream *speakerStream, resumed bool, err error) {
+	h.mu.Lock()
+	if existing, ok := h.streams[auth.ActivityID]; ok {
+		h.mu.Unlock()
+		if err := h.resumeStream(existing, auth.Language, format, conn); err != nil {
+			return nil, false, err
+		}
+		return existing, true, nil
+	}
+
+	session, err := h.pipeline.StartSession(
+		auth.ActivityID,
+		auth.Language,
+		activity.TargetLanguages,
+		app.SessionOptions{
+			InterimTranslationInterval: time.Duration(activity.InterimTranslationIntervalMs) * time.Millisecond,
+			AudioFormat:                format,
+		},
+	)
+	if err != nil {
+		h.mu.Unlock()
+		return nil, false, err
+	}
+	stream = &speakerStream{
+		activityID: auth.ActivityID,
+		session:    session,
+		forwarded:  make(chan struct{}),
+		done:       make(chan struct{}),
+		conn:       conn,
+	}
+	h.streams[auth.ActivityID] = stream
+	h.mu.Unlock()
+
+	// 注册活动到广播器；活动被关闭时通知演讲者并结束推流
+	h.broadcaster.RegisterActivity(auth.ActivityID)
+	go h.forwardSubtitles(stream)
+	go h.watchEnded(stream, h.broadcaster.Ended(auth.ActivityID))
+	return stream, false, nil
+}
+
+// resumeStream 新连接接管推流：取消宽限期计时，原连接仍在时将其断开
+func (h *SpeakerWebSocketHandler) resumeStream(stream *speakerStream, language string, format domain.AudioFormat, conn *ws.Connection) error {
+	stream.mu.Lock()
+	if stream.stopped {
+		stream.mu.Unlock()
+		return errSpeakerStreamStopped
+	}
+	if stream.session.SourceLanguage != language {
+		stream.mu.Unlock()
+		return errSpeakerLanguageMismatch
+	}
+	// 新连接的 Opus 流带有新的容器头，需要重建识别流
+	format = format.Normalize()
+	if format != stream.session.AudioFormat() || format.Encoding.IsOpus() {
+		if err := h.pipeline.SetAudioFormat(stream.session, format); err != nil {
+			stream.mu.Unlock()
+			return err
+		}
+	}
+
+	if stream.grace != nil {
+		stream.grace.Stop()
+		stream.grace = nil
+	}
+	previous := stream.conn
+	stream.conn = conn
+	stream.mu.Unlock()
+
+	if previous != nil {
+		// 原连接可能是尚未检测到的半开连接，直接断开
+		log.Printf("Speaker connection %s replaced by %s for activity %s", previous.ID, conn.ID, stream.activityID)
+		previous.Drain()
+		return nil
+	}
+
+	log.Printf("Speaker reconnected for activity %s", stream.activityID)
+	state := domain.StatePayload{Status: "STREAMING", Message: "演讲者已重新连接"}
+	if stream.session.Paused() {
+		state = domain.StatePayload{Status: "PAUSED", Message: "演讲已暂停"}
+	}
+	h.broadcaster.BroadcastState(stream.activityID, state)
+	return nil
+}
+
+// detachStream 演讲者连接断开：已停止的推流立即释放，否则进入宽限期等待重连
+func (h *SpeakerWebSocketHandler) detachStream(stream *speakerStream, conn *ws.Connection) {
+	stream.mu.Lock()
+	if stream.conn != conn {
+		// 已被新连接接管
+		stream.mu.Unlock()
+		return
+	}
+	stream.conn = nil
+	if stream.stopped || h.reconnectGrace <= 0 {
+		stream.stopped = true
+		stream.mu.Unlock()
+		h.closeStream(stream)
+		return
+	}
+	stream.grace = time.AfterFunc(h.reconnectGrace, func() {
+		h.expireStream(stream)
+	})
+	stream.mu.Unlock()
+
+	log.Printf("Speaker disconnected from activity %s, keeping session for %s", stream.activityID, h.reconnectGrace)
+	h.broadcaster.BroadcastState(stream.activityID, domain.StatePayload{
+		Status:  "RECONNECTING",
+		Message: "演讲者连接中断，等待重连",
+	})
+}
+
+// expireStream 宽限期内未重连，结束推流
+func (h *SpeakerWebSocketHandler) expireStream(stream *speakerStream) {
+	stream.mu.Lock()
+	if stream.conn != nil || stream.stopped {
+		stream.mu.Unlock()
+		return
+	}
+	stream.stopped = true
+	stream.mu.Unlock()
+
+	log.Printf("Speaker did not reconnect to activity %s within %s", stream.activityID, h.reconnectGrace)
+	h.closeStream(stream)
+}
+
+// closeStream 停止翻译会话并断开观众，只执行一次
+func (h *SpeakerWebSocketHandler) closeStream(stream *speakerStream) {
+	stream.closeOnce.Do(func() {
+		h.mu.Lock()
+		if h.streams[stream.activityID] == stream {
+			delete(h.streams, stream.activityID)
+		}
+		h.mu.Unlock()
+
+		h.pipeline.StopSession(stream.activityID)
+		h.broadcaster.UnregisterActivity(stream.activityID)
+		close(stream.done)
+	})
+}
+
+// watchEnded 活动关闭后发送 ENDED 状态并断开演讲者；断线宽限期内直接结束推流
+func (h *SpeakerWebSocketHandler) watchEnded(stream *speakerStream, ended <-chan struct{}) {
+	select {
+	case <-ended:
+	case <-stream.done:
+		return
+	}
+
+	stream.mu.Lock()
+	stream.stopped = true
+	if stream.grace != nil {
+		stream.grace.Stop()
+		stream.grace = nil
+	}
+	conn := stream.conn
+	stream.mu.Unlock()
+
+	if conn == nil {
+		h.closeStream(stream)
+		return
+	}
+	log.Printf("Activity closed, disconnecting speaker %s", conn.ID)
+	conn.SendJSON(domain.MessageTypeState, domain.StatePayload{
+		Status:  "ENDED",
+		Message: "活动已结束",
+	})
+	conn.Drain()
+}
@@ -3,15 +3,21 @@ package handler
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/hoshea/orion-backend/internal/app"
 	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/metrics"
 	ws "github.com/hoshea/orion-backend/internal/infra/websocket"
 )
 
@@ -23,23 +29,34 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// sessionFlushTimeout STOP 后等待剩余字幕输出的最长时间
+const sessionFlushTimeout = 10 * time.Second
+
 // SpeakerWebSocketHandler 演讲者 WebSocket 处理器
 type SpeakerWebSocketHandler struct {
-	pipeline      *app.TranslationPipeline
-	broadcaster   *app.SubtitleBroadcaster
-	accessService *app.AccessService
+	pipeline       *app.TranslationPipeline
+	broadcaster    *app.SubtitleBroadcaster
+	accessService  *app.AccessService
+	reconnectGrace time.Duration
+
+	mu      sync.Mutex
+	streams map[string]*speakerStream // activityID -> 推流状态
 }
 
 // NewSpeakerWebSocketHandler 创建演讲者处理器
+// reconnectGrace 为演讲者断线后保留翻译会话的时长，0 表示断线立即结束
 func NewSpeakerWebSocketHandler(
 	pipeline *app.TranslationPipeline,
 	broadcaster *app.SubtitleBroadcaster,
 	accessService *app.AccessService,
+	reconnectGrace time.Duration,
 ) *SpeakerWebSocketHandler {
 	return &SpeakerWebSocketHandler{
-		pipeline:      pipeline,
-		broadcaster:   broadcaster,
-		accessService: accessService,
+		pipeline:       pipeline,
+		broadcaster:    broadcaster,
+		accessService:  accessService,
+		reconnectGrace: reconnectGrace,
+		streams:        make(map[string]*speakerStream),
 	}
 }
 
@@ -70,45 +87,62 @@ func (h *SpeakerWebSocketHandler) HandleSpeakerWebSocket(c *gin.Context) {
 		return
 	}
 
-	// 启动翻译会话
-	session, err := h.pipeline.StartSession(
-		authPayload.ActivityID,
-		authPayload.Language,
-		activity.TargetLanguages,
-	)
+	// 启动翻译会话，断线宽限期内重连时接管原会话
+	stream, resumed, err := h.attachStream(authPayload, activity, audioFormatFromQuery(c), wsConn)
 	if err != nil {
 		log.Printf("Failed to start translation session: %v", err)
-		wsConn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
-			Code:    "SESSION_FAILED",
-			Message: "启动翻译会话失败: " + err.Error(),
-		})
+		if errors.Is(err, domain.ErrUnsupportedAudioFormat) {
+			wsConn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+				Code:    "UNSUPPORTED_AUDIO_FORMAT",
+				Message: err.Error(),
+			})
+		} else {
+			wsConn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+				Code:    "SESSION_FAILED",
+				Message: "启动翻译会话失败: " + err.Error(),
+			})
+		}
 		wsConn.Close()
 		return
 	}
 
-	// 注册活动到广播器
-	h.broadcaster.RegisterActivity(authPayload.ActivityID)
-
-	// 发送就绪状态
-	wsConn.SendJSON(domain.MessageTypeState, domain.StatePayload{
-		Status:  "READY",
-		Message: "已连接，准备接收音频",
-	})
-
-	// 启动字幕转发 goroutine
-	go h.forwardSubtitles(wsConn, session, authPayload.ActivityID)
+	// 发送就绪状态，随后总是发送 ACK：接管原会话时附带已接收的音频序列号（尚未接收为 0），客户端从其后续传；
+	// 新会话（首次连接、宽限期已过、服务重启或连到其他实例）为 0 且 resumed 为 false
+	if resumed {
+		wsConn.SendJSON(domain.MessageTypeState, domain.StatePayload{
+			Status:  "READY",
+			Message: "已重新连接，继续接收音频",
+		})
+	} else {
+		wsConn.SendJSON(domain.MessageTypeState, domain.StatePayload{
+			Status:  "READY",
+			Message: "已连接，准备接收音频",
+		})
+	}
+	sequence, _ := stream.lastAccepted()
+	wsConn.SendJSON(domain.MessageTypeAck, domain.AckPayload{Sequence: sequence, Resumed: resumed})
+	if resumed && stream.session.Paused() {
+		wsConn.SendJSON(domain.MessageTypeState, domain.StatePayload{Status: "PAUSED", Message: "演讲已暂停"})
+	}
 
 	// 启动写入 pump
 	go wsConn.WritePump()
 
-	// 读取音频数据
-	wsConn.ReadPump(func(message []byte) {
-		h.handleSpeakerMessage(wsConn, session, message)
+	// 读取音频数据：二进制帧为音频，文本帧为 JSON 消息
+	wsConn.ReadFrames(func(binary bool, message []byte) {
+		if binary {
+			h.handleAudioFrame(stream, wsConn, message)
+			return
+		}
+		h.handleSpeakerMessage(stream, wsConn, message)
 	})
+	if stats := stream.stats(); stats.Received > 0 {
+		log.Printf("Activity %s audio stats: received %d, missing %d, out of order %d, dropped %d",
+			stream.activityID, stats.Received, stats.Missing, stats.OutOfOrder, stats.Dropped)
+	}
 
-	// 连接关闭，停止会话
-	h.pipeline.StopSession(authPayload.ActivityID)
-	h.broadcaster.UnregisterActivity(authPayload.ActivityID)
+	// 连接关闭：已停止时结束会话，否则保留会话等待重连
+	h.detachStream(stream, wsConn)
 	log.Printf("Speaker disconnected: %s", connectionID)
 }
 
@@ -135,9 +169,36 @@ func (h *SpeakerWebSocketHandler) authenticate(conn *ws.Connection, c *gin.Conte
 	}, activity, nil
 }
 
+// audioFormatFromQuery 读取握手参数中声明的音频格式，缺省项由管线补全
+// 无法解析的数值保留为负数，由格式校验拒绝
+func audioFormatFromQuery(c *gin.Context) domain.AudioFormat {
+	parse := func(key string) int32 {
+		value := strings.TrimSpace(c.Query(key))
+		if value == "" {
+			return 0
+		}
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return -1
+		}
+		return int32(n)
+	}
+	return domain.AudioFormat{
+		Encoding:        domain.AudioEncoding(c.Query("encoding")),
+		SampleRateHertz: parse("sampleRate"),
+		Channels:        parse("channels"),
+	}
+}
+
+// clientMessage 客户端（演讲者、观众）JSON 消息，负载按类型延迟解析
+type clientMessage struct {
+	Type    domain.MessageType `json:"type"`
+	Payload json.RawMessage    `json:"payload"`
+}
+
 // handleSpeakerMessage 处理演讲者消息
-func (h *SpeakerWebSocketHandler) handleSpeakerMessage(conn *ws.Connection, session *app.PipelineSession, message []byte) {
-	var msg domain.WebSocketMessage
+func (h *SpeakerWebSocketHandler) handleSpeakerMessage(stream *speakerStream, conn *ws.Connection, message []byte) {
+	var msg clientMessage
 	if err := json.Unmarshal(message, &msg); err != nil {
 		log.Printf("Failed to parse message: %v", err)
 		return
@@ -145,12 +206,12 @@ func (h *SpeakerWebSocketHandler) handleSpeakerMessage(conn *ws.Connection, sess
 
 	switch msg.Type {
 	case domain.MessageTypeAudio:
-		// 处理音频数据
-		h.handleAudio(session, msg.Payload)
+		// 处理 JSON + Base64 音频（兼容旧客户端）
+		h.handleAudio(stream, conn, msg.Payload)
 
 	case domain.MessageTypeControl:
 		// 处理控制消息
-		h.handleControl(conn, msg.Payload)
+		h.handleControl(stream, conn, msg.Payload)
 
 	case domain.MessageTypePong:
 		// 心跳响应，不需要处理
@@ -161,69 +222,186 @@ func (h *SpeakerWebSocketHandler) handleSpeakerMessage(conn *ws.Connection, sess
 	}
 }
 
-// handleAudio 处理音频数据
-func (h *SpeakerWebSocketHandler) handleAudio(session *app.PipelineSession, payload interface{}) {
-	audioPayload, ok := payload.(map[string]interface{})
-	if !ok {
-		log.Printf("Invalid audio payload")
+// handleAudioFrame 处理二进制音频帧
+func (h *SpeakerWebSocketHandler) handleAudioFrame(stream *speakerStream, conn *ws.Connection, message []byte) {
+	frame, err := domain.ParseAudioFrame(message)
+	if err != nil {
+		conn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+			Code:    "INVALID_AUDIO_FRAME",
+			Message: err.Error(),
+		})
 		return
 	}
+	h.sendAudio(stream, conn, frame.Sequence, frame.Data)
+}
 
-	chunkStr, ok := audioPayload["chunk"].(string)
-	if !ok {
-		log.Printf("Invalid audio chunk")
+// handleAudio 处理 JSON 音频消息
+func (h *SpeakerWebSocketHandler) handleAudio(stream *speakerStream, conn *ws.Connection, payload json.RawMessage) {
+	var audioPayload domain.AudioPayload
+	if err := json.Unmarshal(payload, &audioPayload); err != nil || audioPayload.Chunk == "" {
+		log.Printf("Invalid audio payload")
 		return
 	}
 
 	// 解码 Base64 音频数据
-	audioData, err := base64.StdEncoding.DecodeString(chunkStr)
+	audioData, err := base64.StdEncoding.DecodeString(audioPayload.Chunk)
 	if err != nil {
 		log.Printf("Failed to decode audio: %v", err)
 		return
 	}
 
-	// 发送音频到翻译管线
-	if err := session.SendAudio(audioData); err != nil {
-		log.Printf("Failed to send audio: %v", err)
+	if audioPayload.Sequence == nil {
+		h.pushAudio(stream, audioData)
+		return
 	}
+	h.sendAudio(stream, conn, *audioPayload.Sequence, audioData)
 }
 
-// handleControl 处理控制消息
-func (h *SpeakerWebSocketHandler) handleControl(conn *ws.Connection, payload interface{}) {
-	controlPayload, ok := payload.(map[string]interface{})
-	if !ok {
+// sendAudio 检查序列号后发送音频，缺失或乱序时通知演讲者，迟到或重复的块直接丢弃
+// 送入识别失败的块同样以 AUDIO_GAP（dropped）通知，且不计入 ACK；成功送入的音频按 audioAckInterval 节流回复 ACK
+func (h *SpeakerWebSocketHandler) sendAudio(stream *speakerStream, conn *ws.Connection, seq uint32, audioData []byte) {
+	result := stream.observe(seq)
+	if result.Missing > 0 || result.OutOfOrder {
+		log.Printf("Audio sequence anomaly for activity %s: expected %d, received %d (missing %d, out of order %t)",
+			stream.activityID, result.Expected, seq, result.Missing, result.OutOfOrder)
+		conn.SendJSON(domain.MessageTypeAudioGap, domain.AudioGapPayload{
+			Expected:   result.Expected,
+			Received:   seq,
+			Missing:    result.Missing,
+			OutOfOrder: result.OutOfOrder,
+		})
+	}
+	if !result.Accept {
+		return
+	}
+	if !h.pushAudio(stream, audioData) {
+		stream.drop()
+		conn.SendJSON(domain.MessageTypeAudioGap, domain.AudioGapPayload{
+			Expected: seq,
+			Received: seq,
+			Dropped:  true,
+		})
 		return
 	}
+	if stream.commit(seq) && conn.SendJSON(domain.MessageTypeAck, domain.AckPayload{Sequence: seq}) == nil {
+		stream.acked()
+	}
+}
+
+// pushAudio 发送音频到翻译管线，返回是否成功
+func (h *SpeakerWebSocketHandler) pushAudio(stream *speakerStream, audioData []byte) bool {
+	if err := stream.session.SendAudio(audioData); err != nil {
+		log.Printf("Failed to send audio: %v", err)
+		return false
+	}
+	return true
+}
 
-	action, ok := controlPayload["action"].(string)
-	if !ok {
+// handleControl 处理控制消息
+// PAUSE 暂停识别但保留会话，RESUME / START 重建识别流，STOP 输出剩余字幕后结束会话；状态变化同步给观众
+func (h *SpeakerWebSocketHandler) handleControl(stream *speakerStream, conn *ws.Connection, payload json.RawMessage) {
+	var control domain.ControlPayload
+	if err := json.Unmarshal(payload, &control); err != nil || control.Action == "" {
 		return
 	}
+	action := control.Action
 
-	log.Printf("Control action: %s", action)
+	log.Printf("Control action for activity %s: %s", stream.activityID, action)
 
-	// TODO: 根据 action 执行相应操作
 	switch action {
-	case "START":
-		conn.SendJSON(domain.MessageTypeState, domain.StatePayload{
-			Status:  "STREAMING",
-			Message: "开始接收音频",
-		})
+	case "START", "RESUME":
+		state := domain.StatePayload{Status: "STREAMING", Message: "开始接收音频"}
+		if stream.session.Resume() {
+			state.Message = "演讲已恢复"
+			h.broadcaster.BroadcastState(stream.activityID, state)
+		}
+		conn.SendJSON(domain.MessageTypeState, state)
+	case "PAUSE":
+		state := domain.StatePayload{Status: "PAUSED", Message: "演讲已暂停"}
+		if stream.session.Pause() {
+			h.broadcaster.BroadcastState(stream.activityID, state)
+		}
+		conn.SendJSON(domain.MessageTypeState, state)
 	case "STOP":
-		conn.SendJSON(domain.MessageTypeState, domain.StatePayload{
-			Status:  "STOPPED",
-			Message: "停止接收音频",
+		h.stopStream(stream, conn)
+	case "FORMAT":
+		h.changeAudioFormat(stream, conn, control.Format)
+	}
+}
+
+// stopStream 结束会话：等待剩余字幕转发完毕后通知观众与演讲者，并断开演讲者连接
+func (h *SpeakerWebSocketHandler) stopStream(stream *speakerStream, conn *ws.Connection) {
+	stream.mu.Lock()
+	stream.stopped = true
+	stream.mu.Unlock()
+
+	activityID := stream.activityID
+	if err := h.pipeline.FinishSession(activityID, sessionFlushTimeout); err != nil {
+		log.Printf("Failed to finish translation session for activity %s: %v", activityID, err)
+	}
+	<-stream.forwarded
+
+	state := domain.StatePayload{Status: "STOPPED", Message: "演讲已结束"}
+	h.broadcaster.BroadcastState(activityID, state)
+	conn.SendJSON(domain.MessageTypeState, state)
+	conn.Drain()
+}
+
+// changeAudioFormat 切换音频格式，成功后回复 READY 状态
+func (h *SpeakerWebSocketHandler) changeAudioFormat(stream *speakerStream, conn *ws.Connection, format *domain.AudioFormat) {
+	if format == nil {
+		conn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+			Code:    "UNSUPPORTED_AUDIO_FORMAT",
+			Message: "缺少音频格式",
 		})
+		return
 	}
+	if err := h.pipeline.SetAudioFormat(stream.session, *format); err != nil {
+		conn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+			Code:    "UNSUPPORTED_AUDIO_FORMAT",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	current := stream.session.AudioFormat()
+	conn.SendJSON(domain.MessageTypeState, domain.StatePayload{
+		Status:  "READY",
+		Message: fmt.Sprintf("音频格式已切换为 %s %dHz %d 声道", current.Encoding, current.SampleRateHertz, current.Channels),
+	})
 }
 
 // forwardSubtitles 转发字幕到广播器
-func (h *SpeakerWebSocketHandler) forwardSubtitles(conn *ws.Connection, session *app.PipelineSession, activityID string) {
-	for subtitle := range session.SubtitleOutput {
-		// 广播字幕给所有观众
+func (h *SpeakerWebSocketHandler) forwardSubtitles(stream *speakerStream) {
+	defer close(stream.forwarded)
+
+	// 演讲者断线期间字幕照常广播给观众
+	for subtitle := range stream.session.SubtitleOutput {
+		activityID, conn := stream.activityID, stream.currentConn()
+		if subtitle.Partial {
+			// 中间结果不进入历史缓存，客户端按句子 ID 替换显示
+			h.broadcaster.BroadcastPartial(activityID, subtitle)
+			if conn == nil {
+				continue
+			}
+			conn.SendJSON(domain.MessageTypePartial, domain.SubtitlePayload{
+				ID:         subtitle.ID,
+				Original:   subtitle.Original,
+				SourceLang: subtitle.SourceLang,
+				Timestamp:  subtitle.Timestamp,
+				Confidence: subtitle.Confidence,
+			})
+			continue
+		}
+
+		// 广播字幕给所有观众，广播器负责写入历史缓存
 		h.broadcaster.BroadcastSubtitle(activityID, subtitle)
+		metrics.ObserveBroadcastLatency(h.pipeline.SpeechProvider(), subtitle.SourceLang, time.Since(subtitle.Timestamp))
 
 		// 同时也发送给演讲者（显示原文和翻译）
+		if conn == nil {
+			continue
+		}
 		conn.SendJSON(domain.MessageTypeSubtitle, domain.SubtitlePayload{
 			ID:         subtitle.ID,
 			Original:   subtitle.Original,
@@ -102,14 +102,24 @@ func (r *wsTestAccessRepo) GetViewerEntry(_ context.Context, activityID string)
 	return &cloned, nil
 }
 
-func TestSpeakerWebSocket_WithMockPipeline(t *testing.T) {
+type speakerTestEnv struct {
+	activity    *domain.Activity
+	pipeline    *app.TranslationPipeline
+	broadcaster *app.SubtitleBroadcaster
+	handler     *SpeakerWebSocketHandler
+	wsURL       string
+}
+
+// newSpeakerTestEnv 创建活动、演讲者令牌与使用 mock 管线的演讲者 WebSocket 服务
+func newSpeakerTestEnv(t *testing.T, reconnectGrace time.Duration) *speakerTestEnv {
+	t.Helper()
 	gin.SetMode(gin.TestMode)
 
 	activityRepo := memrepo.NewMemoryActivityRepository()
 	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
-	activityService := app.NewActivityService(activityRepo, cfg)
+	activityService := app.NewActivityService(activityRepo, nil, cfg)
 
-	activity, err := activityService.CreateActivity(&domain.CreateActivityRequest{
+	activity, err := activityService.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
 		Title:           "测试活动",
 		Description:     "",
 		Speaker:         "演讲者",
@@ -122,26 +132,39 @@ func TestSpeakerWebSocket_WithMockPipeline(t *testing.T) {
 	}
 
 	accessRepo := newWsTestAccessRepo()
-	accessService := app.NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL)
+	accessService := app.NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, nil)
 
 	token, err := accessService.GenerateSpeakerToken(activity.ID)
 	if err != nil {
 		t.Fatalf("generate speaker token failed: %v", err)
 	}
 
-	pipeline := app.NewMockTranslationPipeline()
-	broadcaster := app.NewSubtitleBroadcaster()
-	handler := NewSpeakerWebSocketHandler(pipeline, broadcaster, accessService)
+	pipeline := app.NewMockTranslationPipeline(nil, nil)
+	history := app.NewSubtitleHistory(time.Minute, 10)
+	broadcaster := app.NewSubtitleBroadcaster(nil, history)
+	handler := NewSpeakerWebSocketHandler(pipeline, broadcaster, accessService, reconnectGrace)
 
 	router := gin.New()
 	router.GET("/ws/speaker", handler.HandleSpeakerWebSocket)
 
 	server := httptest.NewServer(router)
-	defer server.Close()
+	t.Cleanup(server.Close)
+
+	return &speakerTestEnv{
+		activity:    activity,
+		pipeline:    pipeline,
+		broadcaster: broadcaster,
+		handler:     handler,
+		wsURL:       "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/speaker?activityId=" + activity.ID + "&token=" + token.Value + "&language=zh-CN",
+	}
+}
+
+func TestSpeakerWebSocket_WithMockPipeline(t *testing.T) {
+	env := newSpeakerTestEnv(t, time.Minute)
+	activity, pipeline, broadcaster := env.activity, env.pipeline, env.broadcaster
 
-	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/speaker?activityId=" + activity.ID + "&token=" + token.Value + "&language=zh-CN"
 	dialer := websocket.Dialer{}
-	conn, _, err := dialer.Dial(wsURL, nil)
+	conn, _, err := dialer.Dial(env.wsURL, nil)
 	if err != nil {
 		t.Fatalf("dial websocket failed: %v", err)
 	}
@@ -165,4 +188,267 @@ func TestSpeakerWebSocket_WithMockPipeline(t *testing.T) {
 	if err != nil {
 		t.Fatalf("send audio chunk failed: %v", err)
 	}
+
+	// 二进制帧：连续的块正常接收，跳号与迟到的块会收到 AUDIO_GAP
+	for _, seq := range []uint32{2, 5, 3} {
+		if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(seq, []byte{0, 1, 2, 3})); err != nil {
+			t.Fatalf("send audio frame failed: %v", err)
+		}
+	}
+	gap := readSpeakerMessage(t, conn, domain.MessageTypeAudioGap)
+	if gap.Payload.Expected != 3 || gap.Payload.Received != 5 || gap.Payload.Missing != 2 || gap.Payload.OutOfOrder {
+		t.Fatalf("unexpected gap payload: %+v", gap.Payload)
+	}
+	late := readSpeakerMessage(t, conn, domain.MessageTypeAudioGap)
+	if late.Payload.Received != 3 || !late.Payload.OutOfOrder {
+		t.Fatalf("unexpected out-of-order payload: %+v", late.Payload)
+	}
+
+	if err := conn.WriteMessage(websocket.BinaryMessage, []byte{9}); err != nil {
+		t.Fatalf("send invalid frame failed: %v", err)
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeError)
+
+	// 通过 CONTROL 切换音频格式，不支持的格式返回错误
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"CONTROL","payload":{"action":"FORMAT","format":{"encoding":"AAC"}}}`)); err != nil {
+		t.Fatalf("send format control failed: %v", err)
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeError)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"CONTROL","payload":{"action":"FORMAT","format":{"encoding":"WEBM_OPUS","sampleRate":48000}}}`)); err != nil {
+		t.Fatalf("send format control failed: %v", err)
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeState)
+	if got := pipelineFormat(t, pipeline, activity.ID); got.Encoding != domain.AudioEncodingWebMOpus {
+		t.Fatalf("session format = %+v, want WEBM_OPUS", got)
+	}
+
+	// 暂停、恢复与停止同步给观众；停止后服务端关闭连接
+	viewer, err := broadcaster.AddViewer(activity.ID, "viewer-en", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	for _, step := range []struct{ action, status string }{
+		{"PAUSE", "PAUSED"},
+		{"RESUME", "STREAMING"},
+		{"STOP", "STOPPED"},
+	} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"CONTROL","payload":{"action":"`+step.action+`"}}`)); err != nil {
+			t.Fatalf("send %s failed: %v", step.action, err)
+		}
+		readSpeakerMessage(t, conn, domain.MessageTypeState)
+		if status := readViewerState(t, viewer); status != step.status {
+			t.Fatalf("viewer state after %s = %s, want %s", step.action, status, step.status)
+		}
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	if _, err := pipeline.GetSession(activity.ID); err == nil {
+		t.Fatal("session should be finished after STOP")
+	}
+}
+
+func TestSpeakerWebSocket_Reconnect(t *testing.T) {
+	env := newSpeakerTestEnv(t, time.Minute)
+	viewer, err := env.broadcaster.AddViewer(env.activity.ID, "viewer-en", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(env.wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial websocket failed: %v", err)
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeState)
+	if ack := readSpeakerMessage(t, conn, domain.MessageTypeAck); ack.Payload.Sequence != 0 || ack.Payload.Resumed {
+		t.Fatalf("connect ACK = %+v, want new session at 0", ack.Payload)
+	}
+	for seq := uint32(1); seq <= 3; seq++ {
+		if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(seq, []byte{0, 1, 2, 3})); err != nil {
+			t.Fatalf("send audio frame failed: %v", err)
+		}
+	}
+	if ack := readSpeakerMessage(t, conn, domain.MessageTypeAck); ack.Payload.Sequence != 1 {
+		t.Fatalf("first ACK = %d, want 1", ack.Payload.Sequence)
+	}
+	// 确认 3 个块都已处理后再断开
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"CONTROL","payload":{"action":"START"}}`)); err != nil {
+		t.Fatalf("send START failed: %v", err)
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeState)
+	conn.Close()
+
+	// 断线后会话保留，观众收到重连中状态
+	if status := readViewerState(t, viewer); status != "RECONNECTING" {
+		t.Fatalf("viewer state after disconnect = %s, want RECONNECTING", status)
+	}
+	if _, err := env.pipeline.GetSession(env.activity.ID); err != nil {
+		t.Fatalf("session should survive disconnect: %v", err)
+	}
+
+	// 同一令牌重连接管会话，并收到最近接收的序列号
+	conn, _, err = websocket.DefaultDialer.Dial(env.wsURL, nil)
+	if err != nil {
+		t.Fatalf("redial websocket failed: %v", err)
+	}
+	defer conn.Close()
+	readSpeakerMessage(t, conn, domain.MessageTypeState)
+	if ack := readSpeakerMessage(t, conn, domain.MessageTypeAck); ack.Payload.Sequence != 3 || !ack.Payload.Resumed {
+		t.Fatalf("ACK after reconnect = %+v, want resumed at 3", ack.Payload)
+	}
+	if status := readViewerState(t, viewer); status != "STREAMING" {
+		t.Fatalf("viewer state after reconnect = %s, want STREAMING", status)
+	}
+
+	// 序列号延续断线前的进度
+	if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(5, []byte{0, 1, 2, 3})); err != nil {
+		t.Fatalf("send audio frame failed: %v", err)
+	}
+	if gap := readSpeakerMessage(t, conn, domain.MessageTypeAudioGap); gap.Payload.Expected != 4 {
+		t.Fatalf("gap after reconnect expects %d, want 4", gap.Payload.Expected)
+	}
+}
+
+func TestSpeakerWebSocket_ReconnectAfterGrace(t *testing.T) {
+	env := newSpeakerTestEnv(t, 100*time.Millisecond)
+
+	conn, _, err := websocket.DefaultDialer.Dial(env.wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial websocket failed: %v", err)
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeAck)
+	for seq := uint32(1); seq <= 3; seq++ {
+		if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(seq, []byte{0, 1, 2, 3})); err != nil {
+			t.Fatalf("send audio frame failed: %v", err)
+		}
+	}
+	readSpeakerMessage(t, conn, domain.MessageTypeAck)
+	conn.Close()
+
+	// 宽限期到期后会话结束
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := env.pipeline.GetSession(env.activity.ID); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("session should end after the reconnect grace period")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// 重连得到新会话：同样收到 ACK，客户端据此补发缓存的音频而不会一直等待
+	conn, _, err = websocket.DefaultDialer.Dial(env.wsURL, nil)
+	if err != nil {
+		t.Fatalf("redial websocket failed: %v", err)
+	}
+	defer conn.Close()
+	if ack := readSpeakerMessage(t, conn, domain.MessageTypeAck); ack.Payload.Sequence != 0 || ack.Payload.Resumed {
+		t.Fatalf("ACK after expired grace = %+v, want new session at 0", ack.Payload)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(2, []byte{0, 1, 2, 3})); err != nil {
+		t.Fatalf("send audio frame failed: %v", err)
+	}
+	if ack := readSpeakerMessage(t, conn, domain.MessageTypeAck); ack.Payload.Sequence != 2 {
+		t.Fatalf("ACK for resent audio = %d, want 2", ack.Payload.Sequence)
+	}
+	if _, err := env.pipeline.GetSession(env.activity.ID); err != nil {
+		t.Fatalf("new session should be started: %v", err)
+	}
+}
+
+// readViewerState 读取观众收到的下一条状态消息
+func TestSpeakerWebSocket_DroppedAudioNotAcknowledged(t *testing.T) {
+	env := newSpeakerTestEnv(t, time.Minute)
+	conn, _, err := websocket.DefaultDialer.Dial(env.wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial websocket failed: %v", err)
+	}
+	defer conn.Close()
+	readSpeakerMessage(t, conn, domain.MessageTypeState)
+	readSpeakerMessage(t, conn, domain.MessageTypeAck)
+
+	if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(1, []byte{0, 1, 2, 3})); err != nil {
+		t.Fatalf("send audio frame failed: %v", err)
+	}
+	if ack := readSpeakerMessage(t, conn, domain.MessageTypeAck); ack.Payload.Sequence != 1 {
+		t.Fatalf("ACK = %d, want 1", ack.Payload.Sequence)
+	}
+
+	// 会话已停止，音频块无法送入识别
+	env.handler.mu.Lock()
+	stream := env.handler.streams[env.activity.ID]
+	env.handler.mu.Unlock()
+	if err := env.pipeline.StopSession(env.activity.ID); err != nil {
+		t.Fatalf("StopSession() error = %v", err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, domain.EncodeAudioFrame(2, []byte{0, 1, 2, 3})); err != nil {
+		t.Fatalf("send audio frame failed: %v", err)
+	}
+	gap := readSpeakerMessage(t, conn, domain.MessageTypeAudioGap)
+	if !gap.Payload.Dropped || gap.Payload.Received != 2 {
+		t.Fatalf("dropped chunk notification = %+v", gap.Payload.AudioGapPayload)
+	}
+
+	// 重连确认的序列号不包含未送入识别的块
+	if sequence, _ := stream.lastAccepted(); sequence != 1 {
+		t.Fatalf("lastAccepted() = %d, want 1", sequence)
+	}
+	if stats := stream.stats(); stats.Dropped != 1 {
+		t.Fatalf("dropped = %d, want 1", stats.Dropped)
+	}
+}
+
+func readViewerState(t *testing.T, viewer *app.ViewerConnection) string {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg, ok := <-viewer.SendChannel:
+			if !ok {
+				t.Fatal("viewer channel closed")
+			}
+			if state, isState := msg.Payload.(domain.StatePayload); isState {
+				return state.Status
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for viewer state")
+		}
+	}
+}
+
+// pipelineFormat 读取会话当前的音频格式
+func pipelineFormat(t *testing.T, pipeline *app.TranslationPipeline, activityID string) domain.AudioFormat {
+	t.Helper()
+	session, err := pipeline.GetSession(activityID)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	return session.AudioFormat()
+}
+
+type speakerTestMessage struct {
+	Type    domain.MessageType `json:"type"`
+	Payload struct {
+		domain.AudioGapPayload
+		domain.AckPayload
+	} `json:"payload"`
+}
+
+// readSpeakerMessage 读取到指定类型的消息为止（跳过字幕等其他消息）
+func readSpeakerMessage(t *testing.T, conn *websocket.Conn, want domain.MessageType) *speakerTestMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg speakerTestMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for %s: %v", want, err)
+		}
+		if msg.Type == want {
+			return &msg
+		}
+	}
 }
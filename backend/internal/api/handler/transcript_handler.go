@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/caption"
+)
+
+// TranscriptHandler 活动文字稿接口
+type TranscriptHandler struct {
+	service *app.TranscriptService
+}
+
+// NewTranscriptHandler 创建文字稿处理器
+func NewTranscriptHandler(service *app.TranscriptService) *TranscriptHandler {
+	return &TranscriptHandler{service: service}
+}
+
+// GetTranscript 获取活动完整文字稿
+// @Summary 获取活动文字稿
+// @Description 按时间顺序分页返回活动的全部字幕，指定 lang 时只返回该语言译文
+// @Tags activities
+// @Produce json
+// @Param id path string true "活动 ID"
+// @Param lang query string false "目标语言"
+// @Param page query int false "页码（从 1 开始）"
+// @Param pageSize query int false "每页条数（默认 100，最大 500）"
+// @Success 200 {object} domain.TranscriptPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/transcript [get]
+func (h *TranscriptHandler) GetTranscript(c *gin.Context) {
+	activityID := c.Param("id")
+	language := strings.TrimSpace(c.Query("lang"))
+
+	page, err := parseOptionalInt(c.Query("page"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "page 参数错误")
+		return
+	}
+	pageSize, err := parseOptionalInt(c.Query("pageSize"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "pageSize 参数错误")
+		return
+	}
+
+	transcript, err := h.service.GetTranscript(activityID, language, page, pageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrActivityNotFound):
+			writeError(c, http.StatusNotFound, "ACTIVITY_NOT_FOUND", "活动不存在")
+		case errors.Is(err, domain.ErrUnsupportedLanguage):
+			writeError(c, http.StatusBadRequest, "INVALID_LANGUAGE", err.Error())
+		default:
+			writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "获取文字稿失败")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transcript)
+}
+
+// ExportCaptions 导出活动字幕文件
+// @Summary 导出字幕文件
+// @Description 将活动归档字幕导出为 SRT / WebVTT / TTML，时间轴以首个音频块为零点，不传 lang 时导出原文
+// @Tags activities
+// @Produce plain
+// @Param id path string true "活动 ID"
+// @Param lang query string false "目标语言"
+// @Success 200 {string} string "字幕文件"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/activities/{id}/captions.{format} [get]
+func (h *TranscriptHandler) ExportCaptions(format caption.Format) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		activityID := c.Param("id")
+		language := strings.TrimSpace(c.Query("lang"))
+
+		var buf bytes.Buffer
+		if err := h.service.ExportCaptions(&buf, activityID, language, format); err != nil {
+			switch {
+			case errors.Is(err, domain.ErrActivityNotFound):
+				writeError(c, http.StatusNotFound, "ACTIVITY_NOT_FOUND", "活动不存在")
+			case errors.Is(err, domain.ErrUnsupportedLanguage):
+				writeError(c, http.StatusBadRequest, "INVALID_LANGUAGE", err.Error())
+			default:
+				writeError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "导出字幕失败")
+			}
+			return
+		}
+
+		filename := activityID
+		if language != "" {
+			filename += "." + language
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+		c.Data(http.StatusOK, format.ContentType(), buf.Bytes())
+	}
+}
+
+// parseOptionalInt 解析可选的整型查询参数，空字符串返回 0
+func parseOptionalInt(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid integer")
+	}
+	return n, nil
+}
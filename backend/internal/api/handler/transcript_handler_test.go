@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+	memrepo "github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+// transcriptTestRepo 只读的内存字幕归档
+type transcriptTestRepo struct {
+	subtitles []*domain.Subtitle
+}
+
+func (r *transcriptTestRepo) SaveSubtitle(context.Context, *domain.Subtitle) error { return nil }
+
+func (r *transcriptTestRepo) ListSubtitles(_ context.Context, _ string, offset, limit int) ([]*domain.Subtitle, error) {
+	if offset >= len(r.subtitles) {
+		return nil, nil
+	}
+	items := r.subtitles[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *transcriptTestRepo) CountSubtitles(context.Context, string) (int, error) {
+	return len(r.subtitles), nil
+}
+
+func (r *transcriptTestRepo) MarkAudioStarted(context.Context, string, time.Time) error  { return nil }
+func (r *transcriptTestRepo) MarkAudioActivity(context.Context, string, time.Time) error { return nil }
+func (r *transcriptTestRepo) GetAudioStartedAt(context.Context, string) (*time.Time, error) {
+	return nil, nil
+}
+
+func TestTranscriptHandler_GetTranscript(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	activityRepo := memrepo.NewMemoryActivityRepository()
+	activity := &domain.Activity{ID: "act-transcript", InputLanguage: "zh-CN", TargetLanguages: []string{"en"}}
+	if err := activityRepo.Create(activity); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	repo := &transcriptTestRepo{subtitles: []*domain.Subtitle{{
+		ID:           "sub-1",
+		ActivityID:   activity.ID,
+		Original:     "你好",
+		SourceLang:   "zh-CN",
+		Translations: map[string]string{"en": "hello"},
+		Timestamp:    time.Now(),
+	}}}
+	handler := NewTranscriptHandler(app.NewTranscriptService(activityRepo, repo))
+
+	router := gin.New()
+	router.GET("/activities/:id/transcript", handler.GetTranscript)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		code   string
+	}{
+		{"ok", "/activities/act-transcript/transcript?lang=en", http.StatusOK, ""},
+		{"bad page", "/activities/act-transcript/transcript?page=abc", http.StatusBadRequest, "INVALID_REQUEST"},
+		{"bad language", "/activities/act-transcript/transcript?lang=fr", http.StatusBadRequest, "INVALID_LANGUAGE"},
+		{"missing activity", "/activities/missing/transcript", http.StatusNotFound, "ACTIVITY_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.code != "" {
+				var resp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != tt.code {
+					t.Fatalf("error response = %s, want code %s", w.Body.String(), tt.code)
+				}
+				return
+			}
+			var page domain.TranscriptPage
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("decode transcript: %v", err)
+			}
+			if page.Total != 1 || len(page.Items) != 1 || page.Items[0].Text != "hello" {
+				t.Fatalf("transcript = %+v", page)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// UserHandler 管理后台用户接口，仅所有者可访问，只能管理当前组织的用户
+type UserHandler struct {
+	service *app.UserService
+}
+
+// NewUserHandler 创建用户处理器
+func NewUserHandler(service *app.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// ListUsers 列出用户
+// @Summary 获取用户列表
+// @Tags users
+// @Produce json
+// @Success 200 {array} domain.User
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	users, err := h.service.ListUsers(c.GetString("organization_id"))
+	if err != nil {
+		writeUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser 获取用户
+// @Summary 获取用户详情
+// @Tags users
+// @Produce json
+// @Param userId path string true "用户 ID"
+// @Success 200 {object} domain.User
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/users/{userId} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	user, err := h.service.GetOrganizationUser(c.GetString("organization_id"), c.Param("userId"))
+	if err != nil {
+		writeUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// CreateUser 创建用户
+// @Summary 创建用户
+// @Description 在当前组织创建用户，角色可选 owner / organizer / operator / read-only（super-admin 仅超级管理员可授予），密码使用 bcrypt 哈希存储
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body domain.CreateUserRequest true "用户"
+// @Success 201 {object} domain.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req domain.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求数据格式错误: "+err.Error())
+		return
+	}
+
+	user, err := h.service.CreateUser(c.GetString("organization_id"), callerRole(c), &req)
+	if err != nil {
+		writeUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser 更新用户
+// @Summary 更新用户资料、角色或重置密码
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param userId path string true "用户 ID"
+// @Param user body domain.UpdateUserRequest true "用户"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users/{userId} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	var req domain.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求数据格式错误: "+err.Error())
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.GetString("organization_id"), callerRole(c), c.Param("userId"), &req)
+	if err != nil {
+		writeUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser 删除用户
+// @Summary 删除用户
+// @Tags users
+// @Param userId path string true "用户 ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users/{userId} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	if err := h.service.DeleteUser(c.GetString("organization_id"), callerRole(c), c.Param("userId")); err != nil {
+		writeUserError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		writeError(c, http.StatusNotFound, "USER_NOT_FOUND", "用户不存在")
+	case errors.Is(err, domain.ErrUserExists):
+		writeError(c, http.StatusConflict, "USER_EXISTS", "用户名已存在")
+	case errors.Is(err, domain.ErrLastOwner):
+		writeError(c, http.StatusConflict, "LAST_OWNER", err.Error())
+	case errors.Is(err, domain.ErrLastSuperAdmin):
+		writeError(c, http.StatusConflict, "LAST_SUPER_ADMIN", err.Error())
+	case errors.Is(err, domain.ErrRoleNotAllowed):
+		writeError(c, http.StatusForbidden, "FORBIDDEN", err.Error())
+	case errors.Is(err, domain.ErrInvalidUserRole):
+		writeError(c, http.StatusBadRequest, "INVALID_ROLE", err.Error())
+	default:
+		writeError(c, http.StatusBadRequest, "USER_UPDATE_FAILED", err.Error())
+	}
+}
+
+// callerRole 当前登录用户的角色
+func callerRole(c *gin.Context) domain.UserRole {
+	return domain.UserRole(c.GetString("user_role"))
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+const (
+	// sseHeartbeatInterval SSE 心跳间隔，避免代理因连接空闲将其断开
+	sseHeartbeatInterval = 15 * time.Second
+	// pollTimeout 长轮询没有新消息时的最长等待时间
+	pollTimeout = 25 * time.Second
+	// pollLeaseTTL 长轮询观众的名额租约有效期，覆盖一次等待与客户端发起下一次请求的间隔
+	pollLeaseTTL = pollTimeout + 10*time.Second
+)
+
+// viewerPollResponse 长轮询响应
+type viewerPollResponse struct {
+	Cursor   string                     `json:"cursor"`   // 最后一条字幕的句子 ID，下次请求原样带回
+	Lease    string                     `json:"lease"`    // 观众名额租约 ID，下次请求原样带回
+	Reset    bool                       `json:"reset"`    // 游标已不在历史缓存中，messages 从最近的字幕重新开始
+	Closed   bool                       `json:"closed"`   // 活动已结束或演讲已停止，无需继续轮询
+	Messages []*domain.WebSocketMessage `json:"messages"` // 与 WebSocket 通道结构相同的消息
+}
+
+// HandleViewerSSE 通过 Server-Sent Events 推送字幕，供无法建立 WebSocket 的观众使用
+// 事件名为消息类型，data 为消息负载；字幕事件以句子 ID 作为事件 ID，浏览器重连时携带
+// Last-Event-ID，服务端从该句之后续传
+func (h *ViewerWebSocketHandler) HandleViewerSSE(c *gin.Context) {
+	authPayload, admission, err := h.authenticateViewer(c)
+	if err != nil {
+		log.Printf("SSE authentication failed: %v", err)
+		writeViewerAuthError(c, err)
+		return
+	}
+	defer admission.Release()
+
+	viewerID := uuid.New().String()
+	viewerConn, err := h.broadcaster.AddViewer(authPayload.ActivityID, viewerID, authPayload.Languages...)
+	if err != nil {
+		log.Printf("Failed to add viewer: %v", err)
+		writeError(c, http.StatusServiceUnavailable, "ADD_VIEWER_FAILED", "添加观众失败: "+err.Error())
+		return
+	}
+	defer h.broadcaster.RemoveViewer(authPayload.ActivityID, viewerID)
+	log.Printf("Viewer SSE connected: %s", viewerID)
+
+	// 服务端写超时面向普通请求，SSE 长连接需取消
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 响应缓冲，事件立即送达
+
+	writeSSE(c, &domain.WebSocketMessage{
+		Type:    domain.MessageTypeState,
+		Payload: domain.StatePayload{Status: "CONNECTED", Message: "已连接，准备接收字幕"},
+	})
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		// 断线续传：只补发上次收到的字幕之后的部分
+		items, _ := h.history.After(authPayload.ActivityID, authPayload.Languages, lastEventID, h.historySize)
+		for _, item := range items {
+			writeSSE(c, &domain.WebSocketMessage{Type: domain.MessageTypeSubtitle, Payload: item})
+		}
+	} else {
+		writeSSE(c, &domain.WebSocketMessage{
+			Type: domain.MessageTypeHistory,
+			Payload: domain.HistoryPayload{
+				Language:  authPayload.Language,
+				Languages: authPayload.Languages,
+				Subtitles: h.history.RecentFor(authPayload.ActivityID, authPayload.Languages, h.historySize),
+			},
+		})
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case msg, ok := <-viewerConn.SendChannel:
+			if !ok {
+				// 活动结束或被注销，ENDED / STOPPED 已作为最后一条消息发送
+				log.Printf("Viewer SSE closed by server: %s", viewerID)
+				return
+			}
+			writeSSE(c, msg)
+		case <-heartbeat.C:
+			c.Render(-1, sse.Event{Event: string(domain.MessageTypePing), Data: time.Now().Unix()})
+		case <-c.Request.Context().Done():
+			log.Printf("Viewer SSE disconnected: %s", viewerID)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// HandleViewerPoll 长轮询获取字幕，供 SSE 也无法使用的观众
+// cursor 为上次响应的游标，state 为客户端已知的演讲状态，lease 为上次响应的名额租约；
+// 没有新消息时最多等待 pollTimeout。长轮询只返回最终字幕与状态变化，不返回中间结果。
+// 每位轮询观众以租约占用一个观众名额，停止轮询后 pollLeaseTTL 内自动归还
+func (h *ViewerWebSocketHandler) HandleViewerPoll(c *gin.Context) {
+	authPayload, err := viewerAuthFromQuery(c)
+	var lease string
+	if err == nil {
+		_, lease, err = h.accessService.AdmitPollingViewer(authPayload.ActivityID, authPayload.Token,
+			strings.TrimSpace(c.Query("lease")), pollLeaseTTL, authPayload.Languages...)
+	}
+	if err != nil {
+		writeViewerAuthError(c, err)
+		return
+	}
+	activityID, languages := authPayload.ActivityID, authPayload.Languages
+	cursor := strings.TrimSpace(c.Query("cursor"))
+	knownState := strings.ToUpper(strings.TrimSpace(c.Query("state")))
+
+	// 先订阅再读取历史，两者之间到达的字幕不会遗漏，重复的按句子 ID 去除
+	viewerID := "poll-" + uuid.New().String()
+	viewerConn, err := h.broadcaster.AddViewer(activityID, viewerID, languages...)
+	if err != nil {
+		log.Printf("Failed to add viewer: %v", err)
+		writeError(c, http.StatusServiceUnavailable, "ADD_VIEWER_FAILED", "添加观众失败: "+err.Error())
+		return
+	}
+	defer h.broadcaster.RemoveViewer(activityID, viewerID)
+
+	resp := viewerPollResponse{Cursor: cursor, Lease: lease, Messages: []*domain.WebSocketMessage{}}
+	seen := make(map[string]bool)
+	accept := func(msg *domain.WebSocketMessage) {
+		switch payload := msg.Payload.(type) {
+		case domain.SubtitlePayload:
+			if msg.Type != domain.MessageTypeSubtitle || seen[payload.ID] {
+				return
+			}
+			seen[payload.ID] = true
+			resp.Cursor = payload.ID
+		case domain.StatePayload:
+			if payload.Status == knownState {
+				return
+			}
+			knownState = payload.Status
+		default:
+			return
+		}
+		resp.Messages = append(resp.Messages, &domain.WebSocketMessage{Type: msg.Type, Payload: msg.Payload, Seq: msg.Seq, Timestamp: time.Now()})
+	}
+
+	items, found := h.history.After(activityID, languages, cursor, h.historySize)
+	resp.Reset = cursor != "" && !found
+	for _, item := range items {
+		accept(&domain.WebSocketMessage{Type: domain.MessageTypeSubtitle, Payload: item})
+	}
+
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(pollTimeout + 5*time.Second))
+	timeout := time.NewTimer(pollTimeout)
+	defer timeout.Stop()
+
+	for {
+		var msg *domain.WebSocketMessage
+		ok := true
+		if len(resp.Messages) == 0 {
+			// 没有可返回的消息时等待新消息
+			select {
+			case msg, ok = <-viewerConn.SendChannel:
+			case <-timeout.C:
+				c.JSON(http.StatusOK, resp)
+				return
+			case <-c.Request.Context().Done():
+				return
+			}
+		} else {
+			// 已有消息时只取出已到达的部分
+			select {
+			case msg, ok = <-viewerConn.SendChannel:
+			default:
+				c.JSON(http.StatusOK, resp)
+				return
+			}
+		}
+		if !ok {
+			resp.Closed = true
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+		accept(msg)
+	}
+}
+
+// writeSSE 写入一条 SSE 事件，字幕以句子 ID 作为事件 ID
+// 带序列号的消息在 data 中附加 seq 字段，客户端据此发现缺失
+func writeSSE(c *gin.Context, msg *domain.WebSocketMessage) {
+	event := sse.Event{Event: string(msg.Type), Data: msg.Payload}
+	if payload, ok := msg.Payload.(domain.SubtitlePayload); ok && msg.Type == domain.MessageTypeSubtitle {
+		event.Id = payload.ID
+	}
+	if msg.Seq > 0 {
+		if data, err := withSeq(msg.Payload, msg.Seq); err == nil {
+			event.Data = data
+		}
+	}
+	c.Render(-1, event)
+}
+
+// withSeq 将 seq 字段写入负载 JSON 对象
+func withSeq(payload interface{}, seq uint64) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[0] != '{' {
+		return nil, errors.New("payload is not a JSON object")
+	}
+	prefix := `{"seq":` + strconv.FormatUint(seq, 10)
+	if string(data) == "{}" {
+		return []byte(prefix + "}"), nil
+	}
+	return append([]byte(prefix+","), data[1:]...), nil
+}
+
+// writeViewerAuthError SSE 与长轮询的认证失败响应
+func writeViewerAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errViewerQueryMissing):
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	case errors.Is(err, domain.ErrAudienceLimitReached):
+		writeError(c, http.StatusTooManyRequests, "AUDIENCE_LIMIT_REACHED", err.Error())
+	default:
+		writeError(c, http.StatusForbidden, "AUTH_FAILED", "认证失败: "+err.Error())
+	}
+}
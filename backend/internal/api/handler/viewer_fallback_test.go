@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/config"
+	memrepo "github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+type viewerTestEnv struct {
+	activityID  string
+	access      *app.AccessService
+	broadcaster *app.SubtitleBroadcaster
+	history     *app.SubtitleHistory
+	server      *httptest.Server
+	query       url.Values
+}
+
+// newViewerTestEnv 创建已发布的活动与观众邀请码，并启动 WebSocket、SSE、长轮询服务
+func newViewerTestEnv(t *testing.T) *viewerTestEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	activityRepo := memrepo.NewMemoryActivityRepository()
+	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
+	activityService := app.NewActivityService(activityRepo, nil, cfg)
+
+	activity, err := activityService.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
+		Title:           "测试活动",
+		Speaker:         "演讲者",
+		StartTime:       time.Now(),
+		InputLanguage:   "zh-CN",
+		TargetLanguages: []string{"en"},
+	})
+	if err != nil {
+		t.Fatalf("create activity failed: %v", err)
+	}
+	if _, err := activityService.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("publish activity failed: %v", err)
+	}
+
+	accessService := app.NewAccessService(activityRepo, newWsTestAccessRepo(), cfg.ViewerBaseURL, nil)
+	token, err := accessService.GenerateViewerToken(activity.ID, &domain.GenerateViewerTokenRequest{TTLMinutes: 5})
+	if err != nil {
+		t.Fatalf("generate viewer token failed: %v", err)
+	}
+
+	history := app.NewSubtitleHistory(time.Minute, 10)
+	broadcaster := app.NewSubtitleBroadcaster(nil, history)
+	broadcaster.SetIdleRelease(100 * time.Millisecond)
+	handler := NewViewerWebSocketHandler(broadcaster, history, 10, accessService)
+
+	router := gin.New()
+	router.GET("/ws/viewer", handler.HandleViewerWebSocket)
+	router.GET("/sse/viewer", handler.HandleViewerSSE)
+	router.GET("/poll/viewer", handler.HandleViewerPoll)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &viewerTestEnv{
+		activityID:  activity.ID,
+		access:      accessService,
+		broadcaster: broadcaster,
+		history:     history,
+		server:      server,
+		query:       url.Values{"activityId": {activity.ID}, "token": {token.Value}, "language": {"en"}},
+	}
+}
+
+func (e *viewerTestEnv) subtitle(id, text string) *domain.Subtitle {
+	return &domain.Subtitle{ID: id, ActivityID: e.activityID, Original: "原文", SourceLang: "zh-CN",
+		Translations: map[string]string{"en": text}, Timestamp: time.Now()}
+}
+
+func TestViewerWebSocket_HistoryNumbered(t *testing.T) {
+	env := newViewerTestEnv(t)
+	env.history.Append(env.subtitle("s1", "first"))
+	env.broadcaster.RegisterActivity(env.activityID)
+	defer env.broadcaster.UnregisterActivity(env.activityID)
+
+	wsURL := "ws" + strings.TrimPrefix(env.server.URL, "http") + "/ws/viewer?" + env.query.Encode()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial websocket failed: %v", err)
+	}
+	defer conn.Close()
+
+	read := func() (domain.MessageType, uint64, json.RawMessage) {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg struct {
+			Type    domain.MessageType `json:"type"`
+			Seq     uint64             `json:"seq"`
+			Payload json.RawMessage    `json:"payload"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read message failed: %v", err)
+		}
+		return msg.Type, msg.Seq, msg.Payload
+	}
+
+	if msgType, _, _ := read(); msgType != domain.MessageTypeState {
+		t.Fatalf("first message = %s, want STATE", msgType)
+	}
+
+	// 连接时的历史与之后的字幕共用一个序列号
+	msgType, seq, payload := read()
+	var history domain.HistoryPayload
+	if err := json.Unmarshal(payload, &history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if msgType != domain.MessageTypeHistory || seq != 1 || len(history.Subtitles) != 1 || history.Subtitles[0].ID != "s1" {
+		t.Fatalf("history = %s seq %d %+v", msgType, seq, history)
+	}
+	env.broadcaster.BroadcastSubtitle(env.activityID, env.subtitle("s2", "second"))
+	if msgType, seq, _ := read(); msgType != domain.MessageTypeSubtitle || seq != 2 {
+		t.Fatalf("subtitle = %s seq %d, want seq 2", msgType, seq)
+	}
+}
+
+func TestViewerPoll(t *testing.T) {
+	env := newViewerTestEnv(t)
+	env.history.Append(env.subtitle("s1", "first"))
+
+	poll := func(extra url.Values) viewerPollResponse {
+		t.Helper()
+		query := url.Values{}
+		for key, values := range env.query {
+			query[key] = values
+		}
+		for key, values := range extra {
+			query[key] = values
+		}
+		resp, err := http.Get(env.server.URL + "/poll/viewer?" + query.Encode())
+		if err != nil {
+			t.Fatalf("poll failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("poll status = %d", resp.StatusCode)
+		}
+		var body viewerPollResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode poll response: %v", err)
+		}
+		return body
+	}
+
+	// 首次请求立即返回历史字幕
+	first := poll(nil)
+	if first.Cursor != "s1" || len(first.Messages) != 1 || first.Messages[0].Type != domain.MessageTypeSubtitle {
+		t.Fatalf("first poll = %+v", first)
+	}
+
+	// 带游标的请求等待新字幕
+	env.broadcaster.RegisterActivity(env.activityID)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		env.broadcaster.BroadcastSubtitle(env.activityID, env.subtitle("s2", "second"))
+	}()
+	next := poll(url.Values{"cursor": {first.Cursor}})
+	if next.Cursor != "s2" || next.Reset || len(next.Messages) != 1 || next.Messages[0].Seq == 0 {
+		t.Fatalf("next poll = %+v", next)
+	}
+
+	// 未知游标从最近的历史重新开始；状态变化随字幕返回，已知状态不重复返回
+	env.broadcaster.BroadcastState(env.activityID, domain.StatePayload{Status: "PAUSED"})
+	reset := poll(url.Values{"cursor": {"expired"}})
+	if !reset.Reset || reset.Cursor != "s2" || len(reset.Messages) != 3 {
+		t.Fatalf("reset poll = %+v", reset)
+	}
+	known := poll(url.Values{"cursor": {"s1"}, "state": {"paused"}})
+	if len(known.Messages) != 1 || known.Cursor != "s2" {
+		t.Fatalf("poll with known state = %+v", known)
+	}
+
+	resp, err := http.Get(env.server.URL + "/poll/viewer?activityId=" + env.activityID)
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("poll without token status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestViewerSSE(t *testing.T) {
+	env := newViewerTestEnv(t)
+	env.history.Append(env.subtitle("s1", "first"))
+	env.broadcaster.RegisterActivity(env.activityID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, env.server.URL+"/sse/viewer?"+env.query.Encode(), nil)
+	req.Header.Set("Last-Event-ID", "s0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("sse request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		t.Fatalf("content type = %q", contentType)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var data string
+	readEvent := func() (id, event string) {
+		t.Helper()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read sse: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "":
+				return id, event
+			case strings.HasPrefix(line, "id:"):
+				id = strings.TrimPrefix(line, "id:")
+			case strings.HasPrefix(line, "event:"):
+				event = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				data = strings.TrimPrefix(line, "data:")
+			}
+		}
+	}
+
+	if _, event := readEvent(); event != "STATE" {
+		t.Fatalf("first event = %s, want STATE", event)
+	}
+	// Last-Event-ID 不在缓存中时补发最近的字幕
+	if id, event := readEvent(); event != "SUBTITLE" || id != "s1" {
+		t.Fatalf("resumed event = %s (%s), want SUBTITLE s1", event, id)
+	}
+
+	env.broadcaster.BroadcastSubtitle(env.activityID, env.subtitle("s2", "second"))
+	if id, event := readEvent(); event != "SUBTITLE" || id != "s2" {
+		t.Fatalf("live event = %s (%s), want SUBTITLE s2", event, id)
+	}
+	// 实时消息的 data 带序列号，其余字段与负载一致
+	var live struct {
+		Seq  uint64 `json:"seq"`
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(data), &live); err != nil || live.Seq == 0 || live.ID != "s2" || live.Text != "second" {
+		t.Fatalf("live event data = %s", data)
+	}
+
+	env.broadcaster.EndActivity(env.activityID)
+	if _, event := readEvent(); event != "STATE" {
+		t.Fatalf("final event = %s, want STATE", event)
+	}
+}
+
+func TestViewerPoll_AudienceLease(t *testing.T) {
+	env := newViewerTestEnv(t)
+	env.history.Append(env.subtitle("s1", "first"))
+	token, err := env.access.GenerateViewerToken(env.activityID, &domain.GenerateViewerTokenRequest{TTLMinutes: 5, MaxAudience: 1})
+	if err != nil {
+		t.Fatalf("generate viewer token failed: %v", err)
+	}
+
+	poll := func(lease string) (int, viewerPollResponse) {
+		t.Helper()
+		query := url.Values{"activityId": {env.activityID}, "token": {token.Value}, "language": {"en"}, "lease": {lease}}
+		resp, err := http.Get(env.server.URL + "/poll/viewer?" + query.Encode())
+		if err != nil {
+			t.Fatalf("poll failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body viewerPollResponse
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode poll response: %v", err)
+			}
+		}
+		return resp.StatusCode, body
+	}
+
+	// 首次轮询分配租约并占用唯一名额，其他观众被拒绝
+	status, first := poll("")
+	if status != http.StatusOK || first.Lease == "" {
+		t.Fatalf("first poll = %d %+v", status, first)
+	}
+	if status, _ := poll(""); status != http.StatusTooManyRequests {
+		t.Fatalf("second viewer status = %d, want 429", status)
+	}
+	if _, err := env.access.AdmitViewer(env.activityID, token.Value, "en"); err == nil {
+		t.Fatal("websocket viewer should be rejected while poll lease is held")
+	}
+
+	// 带回租约的轮询续期同一名额
+	if status, next := poll(first.Lease); status != http.StatusOK || next.Lease != first.Lease {
+		t.Fatalf("renewed poll = %d %+v", status, next)
+	}
+
+	// 轮询间隔内保留广播订阅，停止轮询后释放
+	if ids := env.broadcaster.ActivityIDs(); len(ids) != 1 {
+		t.Fatalf("activities after poll = %v, want subscription kept", ids)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(env.broadcaster.ActivityIDs()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("poll subscription was not released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,8 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -13,19 +13,27 @@ import (
 	ws "github.com/hoshea/orion-backend/internal/infra/websocket"
 )
 
-// ViewerWebSocketHandler 观众 WebSocket 处理器
+var errViewerQueryMissing = errors.New("缺少 token、activityId 或 language 参数")
+
+// ViewerWebSocketHandler 观众处理器，WebSocket 之外也提供 SSE 与长轮询降级通道
 type ViewerWebSocketHandler struct {
 	broadcaster   *app.SubtitleBroadcaster
+	history       *app.SubtitleHistory
+	historySize   int
 	accessService *app.AccessService
 }
 
 // NewViewerWebSocketHandler 创建观众处理器
 func NewViewerWebSocketHandler(
 	broadcaster *app.SubtitleBroadcaster,
+	history *app.SubtitleHistory,
+	historySize int,
 	accessService *app.AccessService,
 ) *ViewerWebSocketHandler {
 	return &ViewerWebSocketHandler{
 		broadcaster:   broadcaster,
+		history:       history,
+		historySize:   historySize,
 		accessService: accessService,
 	}
 }
@@ -46,19 +54,24 @@ func (h *ViewerWebSocketHandler) HandleViewerWebSocket(c *gin.Context) {
 	log.Printf("Viewer WebSocket connected: %s", viewerID)
 
 	// 等待认证消息
-	authPayload, err := h.authenticateViewer(wsConn, c)
+	authPayload, admission, err := h.authenticateViewer(c)
 	if err != nil {
 		log.Printf("Authentication failed: %v", err)
+		code := "AUTH_FAILED"
+		if errors.Is(err, domain.ErrAudienceLimitReached) {
+			code = "AUDIENCE_LIMIT_REACHED"
+		}
 		wsConn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
-			Code:    "AUTH_FAILED",
+			Code:    code,
 			Message: "认证失败: " + err.Error(),
 		})
 		wsConn.Close()
 		return
 	}
+	defer admission.Release()
 
 	// 添加观众到广播器
-	viewerConn, err := h.broadcaster.AddViewer(authPayload.ActivityID, viewerID, authPayload.Language)
+	viewerConn, err := h.broadcaster.AddViewer(authPayload.ActivityID, viewerID, authPayload.Languages...)
 	if err != nil {
 		log.Printf("Failed to add viewer: %v", err)
 		wsConn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
@@ -75,8 +88,10 @@ func (h *ViewerWebSocketHandler) HandleViewerWebSocket(c *gin.Context) {
 		Message: "已连接，准备接收字幕",
 	})
 
-	// TODO: 发送历史字幕
-	// h.sendHistory(wsConn, authPayload.ActivityID, authPayload.Language)
+	// 经编号发送队列回放历史字幕，便于迟到或重连的观众补齐上下文
+	if err := h.broadcaster.ResendHistory(authPayload.ActivityID, viewerID, "", h.historySize); err != nil {
+		log.Printf("Failed to send history to viewer: %v", err)
+	}
 
 	// 启动字幕转发 goroutine
 	go h.forwardSubtitlesToViewer(wsConn, viewerConn)
@@ -84,9 +99,9 @@ func (h *ViewerWebSocketHandler) HandleViewerWebSocket(c *gin.Context) {
 	// 启动写入 pump
 	go wsConn.WritePump()
 
-	// 读取客户端消息（心跳等）
+	// 读取客户端消息（心跳、切换语言等）
 	wsConn.ReadPump(func(message []byte) {
-		h.handleViewerMessage(wsConn, message)
+		h.handleViewerMessage(wsConn, viewerConn, authPayload.ActivityID, message)
 	})
 
 	// 连接关闭，移除观众
@@ -94,31 +109,65 @@ func (h *ViewerWebSocketHandler) HandleViewerWebSocket(c *gin.Context) {
 	log.Printf("Viewer disconnected: %s", viewerID)
 }
 
-// authenticateViewer 认证观众
-func (h *ViewerWebSocketHandler) authenticateViewer(conn *ws.Connection, c *gin.Context) (*domain.AuthPayload, error) {
-	// 从查询参数获取认证信息
-	token := strings.TrimSpace(c.Query("token"))
-	activityID := strings.TrimSpace(c.Query("activityId"))
-	language := strings.TrimSpace(c.Query("language"))
+// authenticateViewer 认证观众并占用观众名额，调用方需在断开时归还名额
+func (h *ViewerWebSocketHandler) authenticateViewer(c *gin.Context) (*domain.AuthPayload, *app.ViewerAdmission, error) {
+	authPayload, err := viewerAuthFromQuery(c)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if token == "" || activityID == "" || language == "" {
-		return nil, http.ErrAbortHandler
+	admission, err := h.accessService.AdmitViewer(authPayload.ActivityID, authPayload.Token, authPayload.Languages...)
+	if err != nil {
+		return nil, nil, err
 	}
+	return authPayload, admission, nil
+}
+
+// viewerAuthFromQuery 从查询参数获取认证信息，language 可用逗号分隔或重复传入以同时订阅多个语言
+func viewerAuthFromQuery(c *gin.Context) (*domain.AuthPayload, error) {
+	token := strings.TrimSpace(c.Query("token"))
+	activityID := strings.TrimSpace(c.Query("activityId"))
+	languages := parseLanguages(c.QueryArray("language")...)
 
-	if _, err := h.accessService.ValidateViewerSession(activityID, token, language); err != nil {
-		return nil, err
+	if token == "" || activityID == "" || len(languages) == 0 {
+		return nil, errViewerQueryMissing
 	}
 
 	return &domain.AuthPayload{
 		Token:      token,
 		ActivityID: activityID,
-		Language:   language,
+		Language:   languages[0],
+		Languages:  languages,
 	}, nil
 }
 
+// parseLanguages 拆分逗号分隔的语言列表，去除空白与重复项，保留首次出现的顺序
+func parseLanguages(values ...string) []string {
+	var languages []string
+	for _, value := range values {
+		for _, language := range strings.Split(value, ",") {
+			language = strings.TrimSpace(language)
+			if language == "" || containsLanguage(languages, language) {
+				continue
+			}
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+func containsLanguage(languages []string, language string) bool {
+	for _, existing := range languages {
+		if strings.EqualFold(existing, language) {
+			return true
+		}
+	}
+	return false
+}
+
 // handleViewerMessage 处理观众消息
-func (h *ViewerWebSocketHandler) handleViewerMessage(conn *ws.Connection, message []byte) {
-	var msg domain.WebSocketMessage
+func (h *ViewerWebSocketHandler) handleViewerMessage(conn *ws.Connection, viewerConn *app.ViewerConnection, activityID string, message []byte) {
+	var msg clientMessage
 	if err := json.Unmarshal(message, &msg); err != nil {
 		log.Printf("Failed to parse message: %v", err)
 		return
@@ -129,32 +178,81 @@ func (h *ViewerWebSocketHandler) handleViewerMessage(conn *ws.Connection, messag
 		// 心跳响应，不需要处理
 		break
 
+	case domain.MessageTypeSubscribe:
+		h.handleSubscribe(conn, viewerConn, activityID, msg.Payload)
+
+	case domain.MessageTypeResend:
+		h.handleResend(conn, viewerConn, activityID, msg.Payload)
+
 	default:
 		log.Printf("Unknown viewer message type: %s", msg.Type)
 	}
 }
 
+// handleSubscribe 切换订阅语言（可为多个），无需重新连接；成功后以新语言的 HISTORY 作为确认
+func (h *ViewerWebSocketHandler) handleSubscribe(conn *ws.Connection, viewerConn *app.ViewerConnection, activityID string, payload json.RawMessage) {
+	var subscribe domain.SubscribePayload
+	if err := json.Unmarshal(payload, &subscribe); err != nil {
+		log.Printf("Failed to parse subscribe payload: %v", err)
+		return
+	}
+
+	languages := parseLanguages(subscribe.Languages...)
+	if len(subscribe.Languages) == 0 {
+		languages = parseLanguages(subscribe.Language)
+	}
+	if err := h.accessService.CheckViewerLanguages(activityID, languages); err != nil {
+		conn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+			Code:    "INVALID_LANGUAGE",
+			Message: "切换语言失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.broadcaster.SwitchViewerLanguages(activityID, viewerConn.ID, languages, h.historySize); err != nil {
+		log.Printf("Failed to switch viewer language: %v", err)
+		conn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+			Code:    "SUBSCRIBE_FAILED",
+			Message: "切换语言失败: " + err.Error(),
+		})
+	}
+}
+
+// handleResend 观众发现序列号缺失后请求补发，以 HISTORY 返回缺失句子之后的字幕
+func (h *ViewerWebSocketHandler) handleResend(conn *ws.Connection, viewerConn *app.ViewerConnection, activityID string, payload json.RawMessage) {
+	var resend domain.ResendPayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &resend); err != nil {
+			log.Printf("Failed to parse resend payload: %v", err)
+			return
+		}
+	}
+
+	if err := h.broadcaster.ResendHistory(activityID, viewerConn.ID, resend.After, h.historySize); err != nil {
+		log.Printf("Failed to resend history to viewer: %v", err)
+		conn.SendJSON(domain.MessageTypeError, domain.ErrorPayload{
+			Code:    "RESEND_FAILED",
+			Message: "补发字幕失败: " + err.Error(),
+		})
+	}
+}
+
 // forwardSubtitlesToViewer 转发字幕给观众
 func (h *ViewerWebSocketHandler) forwardSubtitlesToViewer(conn *ws.Connection, viewerConn *app.ViewerConnection) {
-	for subtitle := range viewerConn.SendChannel {
+	for msg := range viewerConn.SendChannel {
 		if conn.IsClosed() {
 			return
 		}
 
-		// 发送字幕给观众
-		if err := conn.SendJSON(domain.MessageTypeSubtitle, subtitle); err != nil {
-			log.Printf("Failed to send subtitle to viewer: %v", err)
+		// 发送缓冲已满时等待而不丢弃，积压留在观众发送队列中，由广播器按积压时长断开慢速连接
+		if err := conn.SendMessageWait(msg); err != nil {
+			if !errors.Is(err, ws.ErrConnectionClosed) {
+				log.Printf("Failed to send subtitle to viewer: %v", err)
+			}
 			return
 		}
 	}
-}
-
-// sendHistory 发送历史字幕（TODO）
-func (h *ViewerWebSocketHandler) sendHistory(conn *ws.Connection, activityID, language string) {
-	// TODO: 从缓存中获取历史字幕
-	historyPayload := domain.HistoryPayload{
-		Subtitles: []domain.SubtitlePayload{},
-	}
 
-	conn.SendJSON(domain.MessageTypeHistory, historyPayload)
+	// 活动结束或被注销，发送完剩余消息后断开
+	conn.Drain()
 }
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
 )
 
 // AuthRequired JWT 认证中间件
@@ -46,6 +48,62 @@ func AuthRequired(authService *app.AuthService) gin.HandlerFunc {
 
 		c.Set("user_id", claims.UserID)
 		c.Set("user_role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		// 升级前签发的令牌不含组织，归入默认组织
+		orgID := claims.OrganizationID
+		if orgID == "" {
+			orgID = domain.DefaultOrganizationID
+		}
+		c.Set("organization_id", orgID)
+		c.Next()
+	}
+}
+
+// RequireRole 角色校验中间件，需在 AuthRequired 之后使用
+// 当前用户角色（user_role）低于 minimum 时返回 403
+func RequireRole(minimum domain.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := domain.UserRole(c.GetString("user_role"))
+		if !role.AtLeast(minimum) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "权限不足",
+				"data":    nil,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ActivityFinder 在组织内查找活动
+type ActivityFinder interface {
+	GetActivity(orgID, id string) (*domain.Activity, error)
+}
+
+// ActivityInOrganization 校验路径参数 id 对应的活动属于当前组织（organization_id），需在 AuthRequired 之后使用
+// 活动属于其他组织时与不存在一样返回 404，避免泄露其他组织的活动
+func ActivityInOrganization(activities ActivityFinder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, err := activities.GetActivity(c.GetString("organization_id"), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, domain.ErrActivityNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"code":    "ACTIVITY_NOT_FOUND",
+					"message": "活动不存在",
+					"data":    nil,
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "获取活动失败",
+					"data":    nil,
+				})
+			}
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -3,65 +3,145 @@ package api
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/hoshea/orion-backend/internal/api/handler"
 	"github.com/hoshea/orion-backend/internal/api/middleware"
 	"github.com/hoshea/orion-backend/internal/app"
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
+	"github.com/hoshea/orion-backend/internal/infra/caption"
 	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/database"
+	"github.com/hoshea/orion-backend/internal/infra/metrics"
+	"github.com/hoshea/orion-backend/internal/infra/qrcode"
 	"github.com/hoshea/orion-backend/internal/infra/repository"
 )
 
 // SetupRouter 设置路由
 func SetupRouter(cfg *config.Config, db *sql.DB) (*gin.Engine, error) {
-	// 初始化认证服务
-	authService, err := app.NewAuthService(cfg)
+	// 初始化用户与认证服务，首次启动时使用 ADMIN_USERNAME / ADMIN_PASSWORD 创建超级管理员
+	userService := app.NewUserService(repository.NewPostgresUserRepository(db))
+	initialized, err := userService.EnsureSuperAdmin(cfg.Auth.AdminUsername, cfg.Auth.AdminPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize super admin account: %w", err)
+	}
+	if initialized {
+		log.Printf("Initialized super admin account %q", cfg.Auth.AdminUsername)
+	}
+	userHandler := handler.NewUserHandler(userService)
+	organizationService := app.NewOrganizationService(repository.NewPostgresOrganizationRepository(db))
+
+	sessionRepo := repository.NewPostgresSessionRepository(db)
+	userService.SetSessions(sessionRepo)
+	authService, err := app.NewAuthService(cfg, userService, sessionRepo)
 	if err != nil {
 		log.Fatalf("Failed to initialize auth service: %v", err)
 	}
+	if purged, err := authService.PurgeExpiredSessions(); err != nil {
+		log.Printf("Warning: failed to purge expired refresh sessions: %v", err)
+	} else if purged > 0 {
+		log.Printf("Purged %d expired refresh sessions", purged)
+	}
 	authHandler := handler.NewAuthHandler(authService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService, authService)
 
 	// 初始化依赖
 	activityRepo := repository.NewPostgresActivityRepository(db)
-	activityService := app.NewActivityService(activityRepo, cfg)
+	activityService := app.NewActivityService(activityRepo, repository.NewPostgresActivityTransitionRepository(db), cfg)
 	activityHandler := handler.NewActivityHandler(activityService)
 	accessRepo := repository.NewPostgresAccessRepository(db)
-	accessService := app.NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL)
+	qrGenerator, err := qrcode.NewGenerator(qrcode.Options{
+		Size:     cfg.QRCode.Size,
+		Recovery: cfg.QRCode.RecoveryLevel,
+		LogoPath: cfg.QRCode.LogoPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize QR code generator: %w", err)
+	}
+	accessService := app.NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, qrGenerator)
 	managementHandler := handler.NewManagementHandler(accessService)
 	consoleHandler := handler.NewSpeakerConsoleHandler()
+	subtitleRepo := repository.NewPostgresSubtitleRepository(db)
+	transcriptService := app.NewTranscriptService(activityRepo, subtitleRepo)
+	transcriptHandler := handler.NewTranscriptHandler(transcriptService)
+	glossaryRepo := repository.NewPostgresGlossaryRepository(db)
+	glossaryService := app.NewGlossaryService(activityRepo, glossaryRepo)
+	glossaryHandler := handler.NewGlossaryHandler(glossaryService)
 
-	// 初始化翻译管线（如果 API Key 存在）
+	// 初始化翻译管线（识别引擎由 STT_PROVIDER 选择，翻译引擎由 MT_PROVIDER / MT_ROUTES 选择）
 	var translationPipeline *app.TranslationPipeline
-	if cfg.Google.STTAPIKey != "" && cfg.Google.TranslateAPIKey != "" {
-		tp, err := app.NewTranslationPipeline(
-			context.Background(),
-			cfg.Google.STTAPIKey,
-			cfg.Google.TranslateAPIKey,
-		)
-		if err != nil {
-			log.Printf("Warning: Failed to initialize translation pipeline: %v", err)
-		} else {
-			translationPipeline = tp
-			log.Println("Translation pipeline initialized successfully")
-		}
+	tp, err := app.NewTranslationPipeline(
+		context.Background(),
+		cfg,
+		app.DefaultSpeechProviders(),
+		app.DefaultTranslationProviders(),
+		subtitleRepo,
+		glossaryRepo,
+	)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize translation pipeline: %v", err)
 	} else {
-		translationPipeline = app.NewMockTranslationPipeline()
-		log.Println("Translation pipeline initialized in mock mode")
+		translationPipeline = tp
+		log.Printf("Translation pipeline initialized with STT provider %q, MT provider %q, routes %v",
+			cfg.Speech.Provider, cfg.Translation.Provider, cfg.Translation.Routes)
+	}
+
+	// 初始化历史字幕缓存
+	historyTTL, err := time.ParseDuration(cfg.Cache.HistoryTTL)
+	if err != nil || historyTTL <= 0 {
+		log.Printf("Warning: invalid HISTORY_CACHE_TTL %q, fallback to 5m", cfg.Cache.HistoryTTL)
+		historyTTL = 5 * time.Minute
+	}
+	subtitleHistory := app.NewSubtitleHistory(historyTTL, cfg.Cache.HistorySize)
+
+	// 初始化字幕广播器，多实例部署时通过 Redis pub/sub 同步
+	broadcastBackend, err := broadcast.New(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize broadcast backend: %w", err)
+	}
+	log.Printf("Subtitle broadcaster initialized with %q backend", cfg.Broadcast.Backend)
+	// 跨实例广播时历史字幕同样保存在共享存储中，任一实例都能回放
+	if store, ok := broadcastBackend.(broadcast.HistoryStore); ok {
+		subtitleHistory.SetStore(store)
+	}
+	// 观众名额同样需要跨实例统计
+	if store, ok := broadcastBackend.(broadcast.LeaseStore); ok {
+		accessService.SetAudienceStore(store)
 	}
+	subtitleBroadcaster := app.NewSubtitleBroadcaster(broadcastBackend, subtitleHistory)
+	subtitleBroadcaster.SetLagWindow(cfg.Broadcast.ViewerLagWindow)
+	subtitleBroadcaster.SetIdleRelease(cfg.Broadcast.IdleRelease)
 
-	// 初始化字幕广播器
-	subtitleBroadcaster := app.NewSubtitleBroadcaster()
+	// 活动关闭（手动或调度器自动）时撤销令牌与观众入口，并通知各实例结束演讲者与观众连接
+	activityService.OnClose(func(activity *domain.Activity) {
+		if err := accessService.RevokeActivityAccess(activity.ID); err != nil {
+			log.Printf("Warning: failed to revoke access for closed activity %s: %v", activity.ID, err)
+		}
+		subtitleBroadcaster.EndActivity(activity.ID)
+	})
+	if cfg.Scheduler.Enabled && cfg.Scheduler.Interval > 0 {
+		// 多实例部署时通过 advisory lock 保证同一时刻只有一个实例执行调度
+		scheduler := app.NewActivityScheduler(activityService, activityRepo,
+			database.NewAdvisoryLock(db, database.LockKeyActivityScheduler),
+			cfg.Scheduler.Interval, cfg.Scheduler.PublishLeadTime)
+		go scheduler.Run(context.Background())
+		log.Printf("Activity scheduler started (interval %s, publish lead time %s)",
+			cfg.Scheduler.Interval, cfg.Scheduler.PublishLeadTime)
+	}
 
 	// 初始化 WebSocket 处理器
 	var speakerWSHandler *handler.SpeakerWebSocketHandler
 	var viewerWSHandler *handler.ViewerWebSocketHandler
 
 	if translationPipeline != nil {
-		speakerWSHandler = handler.NewSpeakerWebSocketHandler(translationPipeline, subtitleBroadcaster, accessService)
-		viewerWSHandler = handler.NewViewerWebSocketHandler(subtitleBroadcaster, accessService)
+		speakerWSHandler = handler.NewSpeakerWebSocketHandler(translationPipeline, subtitleBroadcaster, accessService, cfg.Speaker.ReconnectGrace)
+		viewerWSHandler = handler.NewViewerWebSocketHandler(subtitleBroadcaster, subtitleHistory, cfg.Cache.HistorySize, accessService)
 		log.Println("WebSocket handlers initialized")
 	}
 	// 根据环境设置 Gin 模式
@@ -75,6 +155,16 @@ func SetupRouter(cfg *config.Config, db *sql.DB) (*gin.Engine, error) {
 	router.Use(middleware.CORS(cfg.Server.AllowedOrigins))
 	router.Use(middleware.RequestID())
 
+	// 监控指标：翻译会话、观众、音频与字幕链路延迟、HTTP 请求耗时
+	// /metrics 由 main 在 METRICS_ADDR 上单独监听，这里只采集
+	if cfg.Server.MetricsEnabled {
+		router.Use(middleware.Metrics())
+		if translationPipeline != nil {
+			metrics.SetPipeline(translationPipeline)
+		}
+		metrics.SetBroadcaster(subtitleBroadcaster)
+	}
+
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -83,62 +173,116 @@ func SetupRouter(cfg *config.Config, db *sql.DB) (*gin.Engine, error) {
 		})
 	})
 
+	// 访问令牌验证公钥，供其他服务离线验证令牌
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// API v1 路由组
 	v1 := router.Group("/api/v1")
 	{
+		// 角色校验：只读 < 现场操作员 < 组织者 < 所有者 < 超级管理员
+		readOnly := middleware.RequireRole(domain.UserRoleReadOnly)
+		operator := middleware.RequireRole(domain.UserRoleOperator)
+		organizer := middleware.RequireRole(domain.UserRoleOrganizer)
+		owner := middleware.RequireRole(domain.UserRoleOwner)
+		superAdmin := middleware.RequireRole(domain.UserRoleSuperAdmin)
+		// 活动及其令牌、观众入口等子资源只能在当前组织内访问
+		inOrganization := middleware.ActivityInOrganization(activityService)
+
 		// 认证路由
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/logout", authHandler.Logout)
+		}
+
+		// 当前用户的登录会话
+		sessions := v1.Group("/auth/sessions")
+		sessions.Use(middleware.AuthRequired(authService), readOnly)
+		{
+			sessions.GET("", authHandler.ListSessions)
+			sessions.POST("/revoke", authHandler.RevokeAllSessions)
+			sessions.POST("/:sessionId/revoke", authHandler.RevokeSession)
 		}
 
 		// 活动路由（需要认证）
 		activities := v1.Group("/activities")
-		activities.Use(middleware.AuthRequired(authService))
+		activities.Use(middleware.AuthRequired(authService), readOnly)
 		{
 			activities.GET("", activityHandler.ListActivities)
-			activities.POST("", activityHandler.CreateActivity)
+			activities.POST("", organizer, activityHandler.CreateActivity)
 			activities.GET("/:id", activityHandler.GetActivity)
-			activities.PUT("/:id", activityHandler.UpdateActivity)
-			activities.DELETE("/:id", activityHandler.DeleteActivity)
-			activities.POST("/:id/publish", activityHandler.PublishActivity)
-			activities.POST("/:id/close", activityHandler.CloseActivity)
+			activities.PUT("/:id", organizer, activityHandler.UpdateActivity)
+			activities.DELETE("/:id", organizer, activityHandler.DeleteActivity)
+			activities.POST("/:id/publish", operator, activityHandler.PublishActivity)
+			activities.POST("/:id/close", operator, activityHandler.CloseActivity)
+			activities.GET("/:id/transitions", activityHandler.ListTransitions)
+			activities.GET("/:id/transcript", inOrganization, transcriptHandler.GetTranscript)
+			activities.GET("/:id/captions.srt", inOrganization, transcriptHandler.ExportCaptions(caption.FormatSRT))
+			activities.GET("/:id/captions.vtt", inOrganization, transcriptHandler.ExportCaptions(caption.FormatVTT))
+			activities.GET("/:id/captions.ttml", inOrganization, transcriptHandler.ExportCaptions(caption.FormatTTML))
+			activities.GET("/:id/glossary", inOrganization, glossaryHandler.ListTerms)
+			activities.POST("/:id/glossary", organizer, inOrganization, glossaryHandler.CreateTerm)
+			activities.PUT("/:id/glossary/:termId", organizer, inOrganization, glossaryHandler.UpdateTerm)
+			activities.DELETE("/:id/glossary/:termId", organizer, inOrganization, glossaryHandler.DeleteTerm)
 		}
 
 		// 令牌路由
 		tokens := v1.Group("/activities/:id/tokens")
-		tokens.Use(middleware.AuthRequired(authService))
+		tokens.Use(middleware.AuthRequired(authService), readOnly, inOrganization)
 		{
-			tokens.POST("/speaker", managementHandler.GenerateSpeakerToken)
-			tokens.POST("/speaker/revoke", managementHandler.RevokeSpeakerTokens)
-			tokens.POST("/speaker/:tokenId/revoke", managementHandler.RevokeSpeakerToken)
-			tokens.POST("/viewer", managementHandler.GenerateViewerToken)
+			tokens.POST("/speaker", operator, managementHandler.GenerateSpeakerToken)
+			tokens.POST("/speaker/revoke", operator, managementHandler.RevokeSpeakerTokens)
+			tokens.POST("/speaker/:tokenId/revoke", operator, managementHandler.RevokeSpeakerToken)
+			tokens.POST("/viewer", operator, managementHandler.GenerateViewerToken)
 			tokens.GET("", managementHandler.ListTokens)
 		}
 
 		// 观众入口路由
 		viewerEntry := v1.Group("/activities/:id/viewer-entry")
-		viewerEntry.Use(middleware.AuthRequired(authService))
+		viewerEntry.Use(middleware.AuthRequired(authService), readOnly, inOrganization)
 		{
 			viewerEntry.GET("", managementHandler.GetViewerEntry)
-			viewerEntry.POST("/revoke", managementHandler.RevokeViewerEntry)
-			viewerEntry.POST("/activate", managementHandler.ActivateViewerEntry)
+			viewerEntry.GET("/qr.png", managementHandler.GetViewerEntryQRCode)
+			viewerEntry.POST("/revoke", operator, managementHandler.RevokeViewerEntry)
+			viewerEntry.POST("/activate", operator, managementHandler.ActivateViewerEntry)
 		}
 
 		// 文件上传
 		uploads := v1.Group("/uploads")
-		uploads.Use(middleware.AuthRequired(authService))
+		uploads.Use(middleware.AuthRequired(authService), organizer)
 		{
 			uploads.POST("/cover", managementHandler.UploadCover)
 		}
 
+		// 组织管理（仅超级管理员）
+		organizations := v1.Group("/organizations")
+		organizations.Use(middleware.AuthRequired(authService), superAdmin)
+		{
+			organizations.GET("", organizationHandler.ListOrganizations)
+			organizations.POST("", organizationHandler.CreateOrganization)
+			organizations.GET("/:orgId", organizationHandler.GetOrganization)
+			organizations.PUT("/:orgId", organizationHandler.UpdateOrganization)
+			organizations.POST("/:orgId/switch", organizationHandler.SwitchOrganization)
+		}
+
+		// 用户管理（仅所有者，限当前组织）
+		users := v1.Group("/users")
+		users.Use(middleware.AuthRequired(authService), owner)
+		{
+			users.GET("", userHandler.ListUsers)
+			users.POST("", userHandler.CreateUser)
+			users.GET("/:userId", userHandler.GetUser)
+			users.PUT("/:userId", userHandler.UpdateUser)
+			users.DELETE("/:userId", userHandler.DeleteUser)
+		}
+
 		// 语言列表
 		v1.GET("/languages", managementHandler.GetLanguages)
 
 		// 演讲者控制台辅助数据
 		console := v1.Group("/speaker-console")
-		console.Use(middleware.AuthRequired(authService))
+		console.Use(middleware.AuthRequired(authService), readOnly)
 		{
 			console.GET("/hero-insights", consoleHandler.GetHeroInsights)
 			console.GET("/subtitle-history", consoleHandler.GetSubtitleHistory)
@@ -166,5 +310,19 @@ func SetupRouter(cfg *config.Config, db *sql.DB) (*gin.Engine, error) {
 		}
 	}
 
+	// 观众降级通道：网络拦截 WebSocket 升级时使用 SSE 或长轮询
+	if viewerWSHandler != nil {
+		router.GET("/sse/viewer", viewerWSHandler.HandleViewerSSE)
+		router.GET("/poll/viewer", viewerWSHandler.HandleViewerPoll)
+	} else {
+		unavailable := func(c *gin.Context) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Viewer service not available - Translation pipeline not initialized",
+			})
+		}
+		router.GET("/sse/viewer", unavailable)
+		router.GET("/poll/viewer", unavailable)
+	}
+
 	return router, nil
 }
@@ -5,19 +5,27 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
+	"github.com/hoshea/orion-backend/internal/infra/qrcode"
 )
 
 const (
 	defaultSpeakerTokenTTL = 24 * time.Hour
 	defaultViewerTokenTTL  = 120 * time.Minute
 	viewerInviteCodeLength = 6
+	// maxViewerLanguages 单个观众最多同时订阅的语言数
+	maxViewerLanguages = 4
+	// viewerLeaseTTL 观众名额租约的有效期，连接期间每三分之一有效期续期一次
+	viewerLeaseTTL = time.Minute
 )
 
 // AccessRepository 定义令牌与入口的持久化接口
@@ -37,33 +45,60 @@ type AccessService struct {
 	activityRepo domain.ActivityRepository
 	repo         AccessRepository
 	viewerBase   string
+	audience     *AudienceCounter
+	qr           *qrcode.Generator
 }
 
-// NewAccessService 创建访问控制服务
-func NewAccessService(activityRepo domain.ActivityRepository, repo AccessRepository, viewerBaseURL string) *AccessService {
+// ViewerAdmission 观众接入凭证，断开连接时必须调用 Release 归还名额
+type ViewerAdmission struct {
+	Activity *domain.Activity
+	TokenID  string
+	release  func()
+	once     sync.Once
+}
+
+// Release 归还观众名额，可重复调用
+func (a *ViewerAdmission) Release() {
+	a.once.Do(a.release)
+}
+
+// NewAccessService 创建访问控制服务，qr 为空时使用默认配置生成二维码
+func NewAccessService(activityRepo domain.ActivityRepository, repo AccessRepository, viewerBaseURL string, qr *qrcode.Generator) *AccessService {
 	base := strings.TrimRight(viewerBaseURL, "/")
+	if qr == nil {
+		qr, _ = qrcode.NewGenerator(qrcode.Options{})
+	}
 	return &AccessService{
 		activityRepo: activityRepo,
 		repo:         repo,
 		viewerBase:   base,
+		audience:     NewAudienceCounter(),
+		qr:           qr,
 	}
 }
 
+// SetAudienceStore 多实例部署时使用共享存储统计观众名额，需在接入观众前调用
+func (s *AccessService) SetAudienceStore(store broadcast.LeaseStore) {
+	s.audience.SetStore(store)
+}
+
 // GenerateSpeakerToken 生成演讲者令牌
 func (s *AccessService) GenerateSpeakerToken(activityID string) (*domain.ActivityToken, error) {
-	if _, err := s.activityRepo.FindByID(activityID); err != nil {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
 		return nil, err
 	}
 
 	now := time.Now()
 	token := &domain.ActivityToken{
-		ID:         uuid.NewString(),
-		ActivityID: activityID,
-		Type:       domain.TokenTypeSpeaker,
-		Value:      uuid.NewString(),
-		CreatedAt:  now,
-		ExpiresAt:  now.Add(defaultSpeakerTokenTTL),
-		Status:     domain.TokenStatusActive,
+		ID:             uuid.NewString(),
+		OrganizationID: activity.OrganizationID,
+		ActivityID:     activityID,
+		Type:           domain.TokenTypeSpeaker,
+		Value:          uuid.NewString(),
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(defaultSpeakerTokenTTL),
+		Status:         domain.TokenStatusActive,
 	}
 
 	if err := s.repo.CreateToken(context.Background(), token); err != nil {
@@ -109,7 +144,8 @@ func (s *AccessService) RevokeSpeakerToken(activityID, tokenID string) error {
 
 // GenerateViewerToken 生成观众邀请码
 func (s *AccessService) GenerateViewerToken(activityID string, req *domain.GenerateViewerTokenRequest) (*domain.ActivityToken, error) {
-	if _, err := s.activityRepo.FindByID(activityID); err != nil {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -121,13 +157,14 @@ func (s *AccessService) GenerateViewerToken(activityID string, req *domain.Gener
 	now := time.Now()
 	code := strings.ToUpper(generateInviteCode(viewerInviteCodeLength))
 	token := &domain.ActivityToken{
-		ID:         uuid.NewString(),
-		ActivityID: activityID,
-		Type:       domain.TokenTypeViewer,
-		Value:      code,
-		CreatedAt:  now,
-		ExpiresAt:  now.Add(ttl),
-		Status:     domain.TokenStatusActive,
+		ID:             uuid.NewString(),
+		OrganizationID: activity.OrganizationID,
+		ActivityID:     activityID,
+		Type:           domain.TokenTypeViewer,
+		Value:          code,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+		Status:         domain.TokenStatusActive,
 	}
 	if req != nil && req.MaxAudience > 0 {
 		token.MaxAudience = ptr(req.MaxAudience)
@@ -137,11 +174,10 @@ func (s *AccessService) GenerateViewerToken(activityID string, req *domain.Gener
 	entry := &domain.ViewerEntry{
 		ActivityID: activityID,
 		ShareURL:   shareURL,
-		QRType:     "text",
-		QRContent:  encodeTextAsDataURL(shareURL),
 		Status:     domain.ViewerEntryStatusActive,
 		UpdatedAt:  now,
 	}
+	s.renderQRCode(entry)
 
 	ctx := context.Background()
 
@@ -177,6 +213,9 @@ func (s *AccessService) ListTokens(activityID string) ([]*domain.ActivityToken,
 			_ = s.repo.UpdateTokenStatus(ctx, token.ID, domain.TokenStatusExpired)
 			token.Status = domain.TokenStatusExpired
 		}
+		if token.Type == domain.TokenTypeViewer {
+			token.CurrentAudience = s.audience.Count(token.ID)
+		}
 		result = append(result, cloneToken(token))
 	}
 
@@ -195,17 +234,21 @@ func (s *AccessService) GetViewerEntry(activityID string) (*domain.ViewerEntry,
 		return nil, err
 	}
 	if entry != nil {
-		return cloneViewerEntry(entry), nil
+		entry = cloneViewerEntry(entry)
+		// 按当前二维码配置重新渲染，兼容早期保存的文本格式
+		if entry.Status == domain.ViewerEntryStatusActive {
+			s.renderQRCode(entry)
+		}
+		return entry, nil
 	}
 
 	defaultEntry := &domain.ViewerEntry{
 		ActivityID: activityID,
 		ShareURL:   activity.ViewerURL,
-		QRType:     "text",
-		QRContent:  encodeTextAsDataURL(activity.ViewerURL),
 		Status:     domain.ViewerEntryStatusInactive,
 		UpdatedAt:  time.Now(),
 	}
+	s.renderQRCode(defaultEntry)
 	return defaultEntry, nil
 }
 
@@ -237,6 +280,29 @@ func (s *AccessService) RevokeViewerEntry(activityID string) (*domain.ViewerEntr
 	return cloneViewerEntry(entry), nil
 }
 
+// RevokeActivityAccess 活动关闭时撤销全部演讲者、观众令牌并失效观众入口
+func (s *AccessService) RevokeActivityAccess(activityID string) error {
+	ctx := context.Background()
+	if err := s.repo.RevokeTokens(ctx, activityID, domain.TokenTypeSpeaker); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeTokens(ctx, activityID, domain.TokenTypeViewer); err != nil {
+		return err
+	}
+
+	entry, err := s.repo.GetViewerEntry(ctx, activityID)
+	if err != nil {
+		return err
+	}
+	if entry == nil || entry.Status == domain.ViewerEntryStatusRevoked {
+		return nil
+	}
+	entry.Status = domain.ViewerEntryStatusRevoked
+	entry.QRContent = ""
+	entry.UpdatedAt = time.Now()
+	return s.repo.UpsertViewerEntry(ctx, entry)
+}
+
 // ActivateViewerEntry 重新启用观众入口
 func (s *AccessService) ActivateViewerEntry(activityID string) (*domain.ViewerEntry, error) {
 	if _, err := s.activityRepo.FindByID(activityID); err != nil {
@@ -275,8 +341,7 @@ func (s *AccessService) ActivateViewerEntry(activityID string) (*domain.ViewerEn
 
 	entry.Status = domain.ViewerEntryStatusActive
 	entry.ShareURL = s.buildShareURL(activityID, latest.Value)
-	entry.QRType = "text"
-	entry.QRContent = encodeTextAsDataURL(entry.ShareURL)
+	s.renderQRCode(entry)
 	entry.UpdatedAt = time.Now()
 
 	if err := s.repo.UpsertViewerEntry(ctx, entry); err != nil {
@@ -285,6 +350,31 @@ func (s *AccessService) ActivateViewerEntry(activityID string) (*domain.ViewerEn
 	return cloneViewerEntry(entry), nil
 }
 
+// ViewerEntryQRCode 生成观众入口二维码 PNG，size <= 0 时使用默认边长
+func (s *AccessService) ViewerEntryQRCode(activityID string, size int) ([]byte, error) {
+	entry, err := s.GetViewerEntry(activityID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != domain.ViewerEntryStatusActive {
+		return nil, domain.ErrViewerEntryInactive
+	}
+	return s.qr.PNG(entry.ShareURL, size)
+}
+
+// renderQRCode 生成 SVG 二维码，失败时退化为文本 data URL
+func (s *AccessService) renderQRCode(entry *domain.ViewerEntry) {
+	content, err := s.qr.SVGDataURL(entry.ShareURL)
+	if err != nil {
+		log.Printf("Failed to render QR code for activity %s: %v", entry.ActivityID, err)
+		entry.QRType = "text"
+		entry.QRContent = encodeTextAsDataURL(entry.ShareURL)
+		return
+	}
+	entry.QRType = "svg"
+	entry.QRContent = content
+}
+
 func (s *AccessService) buildShareURL(activityID, code string) string {
 	return fmt.Sprintf("%s/activity/%s?code=%s", s.viewerBase, activityID, code)
 }
@@ -335,45 +425,127 @@ func (s *AccessService) ValidateSpeakerSession(activityID, tokenValue, language
 	}
 }
 
-// ValidateViewerSession 校验观众接入令牌与语言
-func (s *AccessService) ValidateViewerSession(activityID, tokenValue, language string) (*domain.Activity, error) {
+// ValidateViewerSession 校验观众接入令牌与语言，人数已满时返回 domain.ErrAudienceLimitReached
+func (s *AccessService) ValidateViewerSession(activityID, tokenValue string, languages ...string) (*domain.Activity, error) {
+	activity, token, err := s.validateViewerToken(activityID, tokenValue, languages)
+	if err != nil {
+		return nil, err
+	}
+	if token.MaxAudience != nil && s.audience.Count(token.ID) >= *token.MaxAudience {
+		return nil, domain.ErrAudienceLimitReached
+	}
+	return activity, nil
+}
+
+// CheckViewerLanguages 校验已接入的观众切换到的语言是否均为活动启用的语言
+func (s *AccessService) CheckViewerLanguages(activityID string, languages []string) error {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return err
+	}
+	return checkViewerLanguages(activity, languages)
+}
+
+// AdmitViewer 校验观众令牌并占用一个观众名额，观众可同时订阅多个语言
+func (s *AccessService) AdmitViewer(activityID, tokenValue string, languages ...string) (*ViewerAdmission, error) {
+	activity, token, err := s.validateViewerToken(activityID, tokenValue, languages)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if token.MaxAudience != nil {
+		limit = *token.MaxAudience
+	}
+	tokenID, leaseID := token.ID, uuid.NewString()
+	if !s.audience.Acquire(tokenID, leaseID, limit, viewerLeaseTTL) {
+		return nil, domain.ErrAudienceLimitReached
+	}
+
+	// 连接期间定期续期，实例异常退出时租约到期后名额自动归还
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(viewerLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.audience.Acquire(tokenID, leaseID, 0, viewerLeaseTTL)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return &ViewerAdmission{
+		Activity: activity,
+		TokenID:  tokenID,
+		release: func() {
+			close(done)
+			s.audience.Release(tokenID, leaseID)
+		},
+	}, nil
+}
+
+// AdmitPollingViewer 长轮询观众接入：占用或续期 leaseID 对应的名额租约，leaseID 为空或无效时分配新租约
+// 每次请求续期一次，租约不主动释放，客户端停止轮询后 ttl 到期即归还名额
+func (s *AccessService) AdmitPollingViewer(activityID, tokenValue, leaseID string, ttl time.Duration, languages ...string) (*domain.Activity, string, error) {
+	activity, token, err := s.validateViewerToken(activityID, tokenValue, languages)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := uuid.Parse(leaseID); err != nil {
+		leaseID = uuid.NewString()
+	}
+	limit := 0
+	if token.MaxAudience != nil {
+		limit = *token.MaxAudience
+	}
+	if !s.audience.Acquire(token.ID, leaseID, limit, ttl) {
+		return nil, "", domain.ErrAudienceLimitReached
+	}
+	return activity, leaseID, nil
+}
+
+func (s *AccessService) validateViewerToken(activityID, tokenValue string, languages []string) (*domain.Activity, *domain.ActivityToken, error) {
 	tokenValue = strings.TrimSpace(tokenValue)
 	if tokenValue == "" {
-		return nil, errors.New("观众令牌不能为空")
+		return nil, nil, errors.New("观众令牌不能为空")
 	}
-	if language == "" {
-		return nil, errors.New("观众订阅语言不能为空")
+	if len(languages) == 0 {
+		return nil, nil, errors.New("观众订阅语言不能为空")
 	}
 
 	normalizedCode := strings.ToUpper(tokenValue)
 
 	activity, err := s.activityRepo.FindByID(activityID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if activity.Status != domain.ActivityStatusPublished {
-		return nil, errors.New("活动尚未发布，暂不支持观众接入")
+		return nil, nil, errors.New("活动尚未发布，暂不支持观众接入")
 	}
 
-	if !supportsLanguage(activity, language) {
-		return nil, fmt.Errorf("活动未启用语言: %s", language)
+	if err := checkViewerLanguages(activity, languages); err != nil {
+		return nil, nil, err
 	}
 
 	ctx := context.Background()
 	entry, err := s.repo.GetViewerEntry(ctx, activityID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if entry == nil || entry.Status != domain.ViewerEntryStatusActive {
-		return nil, errors.New("观众入口未启用，请联系主办方")
+		return nil, nil, errors.New("观众入口未启用，请联系主办方")
 	}
 
 	token, err := s.repo.FindToken(ctx, activityID, domain.TokenTypeViewer, normalizedCode)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if token == nil {
-		return nil, errors.New("观众令牌无效")
+		return nil, nil, errors.New("观众令牌无效")
 	}
 
 	now := time.Now()
@@ -384,13 +556,13 @@ func (s *AccessService) ValidateViewerSession(activityID, tokenValue, language s
 
 	switch token.Status {
 	case domain.TokenStatusActive:
-		return activity, nil
+		return activity, token, nil
 	case domain.TokenStatusRevoked:
-		return nil, errors.New("观众令牌已被撤销")
+		return nil, nil, errors.New("观众令牌已被撤销")
 	case domain.TokenStatusExpired:
-		return nil, errors.New("观众令牌已过期")
+		return nil, nil, errors.New("观众令牌已过期")
 	default:
-		return nil, errors.New("观众令牌状态异常")
+		return nil, nil, errors.New("观众令牌状态异常")
 	}
 }
 
@@ -432,6 +604,25 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+// checkViewerLanguages 校验观众订阅的语言：非空、不超过上限且均为活动启用的语言
+func checkViewerLanguages(activity *domain.Activity, languages []string) error {
+	if len(languages) == 0 {
+		return errors.New("观众订阅语言不能为空")
+	}
+	if len(languages) > maxViewerLanguages {
+		return fmt.Errorf("最多同时订阅 %d 个语言", maxViewerLanguages)
+	}
+	for _, language := range languages {
+		if language == "" {
+			return errors.New("观众订阅语言不能为空")
+		}
+		if !supportsLanguage(activity, language) {
+			return fmt.Errorf("活动未启用语言: %s", language)
+		}
+	}
+	return nil
+}
+
 func supportsLanguage(activity *domain.Activity, language string) bool {
 	if strings.EqualFold(language, activity.InputLanguage) {
 		return true
@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
 	"github.com/hoshea/orion-backend/internal/infra/config"
 	"github.com/hoshea/orion-backend/internal/infra/repository"
 )
@@ -101,9 +104,9 @@ func (f *fakeAccessRepo) GetViewerEntry(_ context.Context, activityID string) (*
 func TestAccessService_GenerateAndValidateViewerToken(t *testing.T) {
 	activityRepo := repository.NewMemoryActivityRepository()
 	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
-	service := NewActivityService(activityRepo, cfg)
+	service := NewActivityService(activityRepo, nil, cfg)
 
-	activity, err := service.CreateActivity(&domain.CreateActivityRequest{
+	activity, err := service.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
 		Title:           "Demo",
 		Description:     "Demo activity",
 		Speaker:         "Tester",
@@ -115,12 +118,12 @@ func TestAccessService_GenerateAndValidateViewerToken(t *testing.T) {
 		t.Fatalf("create activity failed: %v", err)
 	}
 
-	if _, err := service.PublishActivity(activity.ID); err != nil {
+	if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
 		t.Fatalf("publish activity failed: %v", err)
 	}
 
 	accessRepo := newFakeAccessRepo()
-	accessService := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL)
+	accessService := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, nil)
 
 	token, err := accessService.GenerateViewerToken(activity.ID, &domain.GenerateViewerTokenRequest{TTLMinutes: 5})
 	if err != nil {
@@ -141,6 +144,13 @@ func TestAccessService_GenerateAndValidateViewerToken(t *testing.T) {
 	if _, err := accessService.ValidateViewerSession(activity.ID, token.Value, "en"); err != nil {
 		t.Fatalf("validate viewer session failed: %v", err)
 	}
+	// 同时订阅原文与译文；任一语言未启用时拒绝
+	if _, err := accessService.ValidateViewerSession(activity.ID, token.Value, "zh-CN", "en"); err != nil {
+		t.Fatalf("validate bilingual viewer session failed: %v", err)
+	}
+	if _, err := accessService.ValidateViewerSession(activity.ID, token.Value, "en", "ja"); err == nil {
+		t.Fatalf("expected error for language not enabled")
+	}
 
 	// 模拟过期
 	accessRepo.tokens[activity.ID][0].ExpiresAt = time.Now().Add(-time.Minute)
@@ -152,9 +162,9 @@ func TestAccessService_GenerateAndValidateViewerToken(t *testing.T) {
 func TestAccessService_GenerateSpeakerToken(t *testing.T) {
 	activityRepo := repository.NewMemoryActivityRepository()
 	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
-	service := NewActivityService(activityRepo, cfg)
+	service := NewActivityService(activityRepo, nil, cfg)
 
-	activity, err := service.CreateActivity(&domain.CreateActivityRequest{
+	activity, err := service.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
 		Title:           "Speaker session",
 		Description:     "",
 		Speaker:         "Speaker",
@@ -167,7 +177,7 @@ func TestAccessService_GenerateSpeakerToken(t *testing.T) {
 	}
 
 	accessRepo := newFakeAccessRepo()
-	accessService := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL)
+	accessService := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, nil)
 
 	token, err := accessService.GenerateSpeakerToken(activity.ID)
 	if err != nil {
@@ -180,3 +190,137 @@ func TestAccessService_GenerateSpeakerToken(t *testing.T) {
 		t.Fatalf("validate speaker session failed: %v", err)
 	}
 }
+
+func TestAccessService_AdmitViewerEnforcesMaxAudience(t *testing.T) {
+	activityRepo := repository.NewMemoryActivityRepository()
+	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
+	service := NewActivityService(activityRepo, nil, cfg)
+
+	activity, err := service.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
+		Title:           "Capped",
+		Speaker:         "Tester",
+		StartTime:       time.Now(),
+		InputLanguage:   "zh-CN",
+		TargetLanguages: []string{"en"},
+	})
+	if err != nil {
+		t.Fatalf("create activity failed: %v", err)
+	}
+	if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("publish activity failed: %v", err)
+	}
+
+	accessService := NewAccessService(activityRepo, newFakeAccessRepo(), cfg.ViewerBaseURL, nil)
+	token, err := accessService.GenerateViewerToken(activity.ID, &domain.GenerateViewerTokenRequest{MaxAudience: 2})
+	if err != nil {
+		t.Fatalf("generate viewer token failed: %v", err)
+	}
+
+	first, err := accessService.AdmitViewer(activity.ID, token.Value, "en")
+	if err != nil {
+		t.Fatalf("admit first viewer failed: %v", err)
+	}
+	if _, err := accessService.AdmitViewer(activity.ID, token.Value, "en"); err != nil {
+		t.Fatalf("admit second viewer failed: %v", err)
+	}
+	if _, err := accessService.AdmitViewer(activity.ID, token.Value, "en"); !errors.Is(err, domain.ErrAudienceLimitReached) {
+		t.Fatalf("expected audience limit error, got %v", err)
+	}
+	if _, err := accessService.ValidateViewerSession(activity.ID, token.Value, "en"); !errors.Is(err, domain.ErrAudienceLimitReached) {
+		t.Fatalf("expected audience limit error from validate, got %v", err)
+	}
+
+	tokens, err := accessService.ListTokens(activity.ID)
+	if err != nil {
+		t.Fatalf("list tokens failed: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].CurrentAudience != 2 {
+		t.Fatalf("expected current audience 2, got %+v", tokens)
+	}
+
+	first.Release()
+	first.Release()
+	if _, err := accessService.AdmitViewer(activity.ID, token.Value, "en"); err != nil {
+		t.Fatalf("admit after release failed: %v", err)
+	}
+}
+
+func TestAudienceCounter_LeaseExpiry(t *testing.T) {
+	counter := NewAudienceCounter()
+	if !counter.Acquire("token", "a", 1, 50*time.Millisecond) {
+		t.Fatal("first lease should be acquired")
+	}
+	if counter.Acquire("token", "b", 1, time.Minute) {
+		t.Fatal("second lease should be rejected")
+	}
+	// 续期已有租约不受上限限制
+	if !counter.Acquire("token", "a", 1, 50*time.Millisecond) {
+		t.Fatal("existing lease should be renewed")
+	}
+
+	// 未释放的租约到期后名额自动归还
+	time.Sleep(100 * time.Millisecond)
+	if count := counter.Count("token"); count != 0 {
+		t.Fatalf("Count() after expiry = %d, want 0", count)
+	}
+	if !counter.Acquire("token", "b", 1, time.Minute) {
+		t.Fatal("lease should be acquired after expiry")
+	}
+}
+
+// TestAccessService_SharedAudienceLimit 两个实例经 Redis 共享观众上限，需要本地 Redis，不可用时跳过
+func TestAccessService_SharedAudienceLimit(t *testing.T) {
+	redisURL := os.Getenv("TEST_REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379/15"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	store, err := broadcast.NewRedisBackend(ctx, redisURL)
+	if err != nil {
+		t.Skipf("redis not available: %v", err)
+	}
+	defer store.Close()
+
+	activityRepo := repository.NewMemoryActivityRepository()
+	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
+	service := NewActivityService(activityRepo, nil, cfg)
+	activity, err := service.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
+		Title:           "Shared",
+		Speaker:         "Tester",
+		StartTime:       time.Now(),
+		InputLanguage:   "zh-CN",
+		TargetLanguages: []string{"en"},
+	})
+	if err != nil {
+		t.Fatalf("create activity failed: %v", err)
+	}
+	if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("publish activity failed: %v", err)
+	}
+
+	accessRepo := newFakeAccessRepo()
+	first := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, nil)
+	second := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, nil)
+	first.SetAudienceStore(store)
+	second.SetAudienceStore(store)
+
+	token, err := first.GenerateViewerToken(activity.ID, &domain.GenerateViewerTokenRequest{MaxAudience: 1})
+	if err != nil {
+		t.Fatalf("generate viewer token failed: %v", err)
+	}
+	admission, err := first.AdmitViewer(activity.ID, token.Value, "en")
+	if err != nil {
+		t.Fatalf("admit on first instance failed: %v", err)
+	}
+	if _, err := second.AdmitViewer(activity.ID, token.Value, "en"); !errors.Is(err, domain.ErrAudienceLimitReached) {
+		t.Fatalf("expected audience limit on second instance, got %v", err)
+	}
+
+	admission.Release()
+	again, err := second.AdmitViewer(activity.ID, token.Value, "en")
+	if err != nil {
+		t.Fatalf("admit on second instance after release failed: %v", err)
+	}
+	again.Release()
+}
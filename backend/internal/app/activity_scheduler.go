@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// ActivityScheduleRepository 定义调度器跨组织查询待处理活动的接口
+type ActivityScheduleRepository interface {
+	// FindAutoPublishDue 查找开启自动发布、开始时间不晚于 before 的草稿
+	FindAutoPublishDue(ctx context.Context, before time.Time) ([]*domain.Activity, error)
+	// FindAutoCloseCandidates 查找设置了计划结束时间或无音频自动关闭的已发布活动
+	FindAutoCloseCandidates(ctx context.Context) ([]*domain.Activity, error)
+}
+
+// SchedulerLock 多实例部署时保证同一时刻只有一个实例执行调度
+type SchedulerLock interface {
+	TryLock(ctx context.Context) (unlock func(), acquired bool, err error)
+}
+
+// ActivityScheduler 活动调度器
+// 定时在开始时间前自动发布草稿，并在到达计划结束时间或长时间无音频时自动关闭活动
+type ActivityScheduler struct {
+	activities      *ActivityService
+	repo            ActivityScheduleRepository
+	lock            SchedulerLock
+	interval        time.Duration
+	publishLeadTime time.Duration
+	now             func() time.Time
+}
+
+// NewActivityScheduler 创建活动调度器，lock 为空时不做跨实例互斥（仅限单实例部署）
+func NewActivityScheduler(activities *ActivityService, repo ActivityScheduleRepository, lock SchedulerLock, interval, publishLeadTime time.Duration) *ActivityScheduler {
+	return &ActivityScheduler{
+		activities:      activities,
+		repo:            repo,
+		lock:            lock,
+		interval:        interval,
+		publishLeadTime: publishLeadTime,
+		now:             time.Now,
+	}
+}
+
+// Run 按间隔执行调度，直到 ctx 取消
+func (s *ActivityScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一轮调度，未获得锁时跳过
+func (s *ActivityScheduler) RunOnce(ctx context.Context) {
+	if s.lock != nil {
+		unlock, acquired, err := s.lock.TryLock(ctx)
+		if err != nil {
+			log.Printf("Warning: activity scheduler failed to acquire lock: %v", err)
+			return
+		}
+		if !acquired {
+			return
+		}
+		defer unlock()
+	}
+
+	now := s.now()
+	s.publishDue(ctx, now)
+	s.closeDue(ctx, now)
+}
+
+// publishDue 发布开始时间在提前量以内的草稿
+func (s *ActivityScheduler) publishDue(ctx context.Context, now time.Time) {
+	due, err := s.repo.FindAutoPublishDue(ctx, now.Add(s.publishLeadTime))
+	if err != nil {
+		log.Printf("Warning: activity scheduler failed to query drafts: %v", err)
+		return
+	}
+
+	for _, candidate := range due {
+		activity, err := s.reload(candidate.ID, domain.ActivityStatusDraft)
+		if err != nil || activity == nil {
+			continue
+		}
+		if err := s.activities.publish(activity, domain.TransitionReasonAutoPublish); err != nil {
+			log.Printf("Warning: activity scheduler failed to publish activity %s: %v", activity.ID, err)
+		}
+	}
+}
+
+// closeDue 关闭到达计划结束时间或无音频超时的活动
+func (s *ActivityScheduler) closeDue(ctx context.Context, now time.Time) {
+	candidates, err := s.repo.FindAutoCloseCandidates(ctx)
+	if err != nil {
+		log.Printf("Warning: activity scheduler failed to query published activities: %v", err)
+		return
+	}
+
+	for _, candidate := range candidates {
+		if _, ok := closeReason(candidate, now); !ok {
+			continue
+		}
+		activity, err := s.reload(candidate.ID, domain.ActivityStatusPublished)
+		if err != nil || activity == nil {
+			continue
+		}
+		// 重新读取后再判断一次，排除期间修改过计划的活动
+		reason, ok := closeReason(activity, now)
+		if !ok {
+			continue
+		}
+		if err := s.activities.close(activity, reason); err != nil {
+			log.Printf("Warning: activity scheduler failed to close activity %s: %v", activity.ID, err)
+		}
+	}
+}
+
+// reload 重新读取活动，状态已被手动修改时返回 nil
+func (s *ActivityScheduler) reload(id string, status domain.ActivityStatus) (*domain.Activity, error) {
+	activity, err := s.activities.repo.FindByID(id)
+	if err != nil {
+		log.Printf("Warning: activity scheduler failed to load activity %s: %v", id, err)
+		return nil, err
+	}
+	if activity.Status != status {
+		return nil, nil
+	}
+	return activity, nil
+}
+
+// closeReason 判断活动是否应自动关闭
+// 无音频时长从最近一次音频开始计算；尚未收到音频时从开始时间与发布时间中较晚者开始计算
+func closeReason(activity *domain.Activity, now time.Time) (string, bool) {
+	if activity.ScheduledEndTime != nil && !now.Before(*activity.ScheduledEndTime) {
+		return domain.TransitionReasonScheduledEnd, true
+	}
+
+	if activity.IdleCloseMinutes <= 0 || now.Before(activity.StartTime) {
+		return "", false
+	}
+	since := activity.StartTime
+	if activity.LastAudioAt != nil {
+		since = *activity.LastAudioAt
+	} else if activity.UpdatedAt.After(since) {
+		since = activity.UpdatedAt
+	}
+	if now.Sub(since) >= time.Duration(activity.IdleCloseMinutes)*time.Minute {
+		return domain.TransitionReasonIdle, true
+	}
+	return "", false
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+type fakeTransitionRepo struct {
+	mu          sync.Mutex
+	transitions []*domain.ActivityTransition
+}
+
+func (r *fakeTransitionRepo) RecordTransition(_ context.Context, transition *domain.ActivityTransition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, transition)
+	return nil
+}
+
+func (r *fakeTransitionRepo) ListTransitions(_ context.Context, activityID string) ([]*domain.ActivityTransition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*domain.ActivityTransition
+	for _, transition := range r.transitions {
+		if transition.ActivityID == activityID {
+			result = append(result, transition)
+		}
+	}
+	return result, nil
+}
+
+type fakeSchedulerLock struct {
+	held     bool
+	released int
+}
+
+func (l *fakeSchedulerLock) TryLock(context.Context) (func(), bool, error) {
+	if l.held {
+		return nil, false, nil
+	}
+	return func() { l.released++ }, true, nil
+}
+
+func newTestScheduler(t *testing.T, now time.Time, lock SchedulerLock) (*ActivityScheduler, *ActivityService, *repository.MemoryActivityRepository, *fakeTransitionRepo) {
+	t.Helper()
+	activityRepo := repository.NewMemoryActivityRepository()
+	transitions := &fakeTransitionRepo{}
+	service := NewActivityService(activityRepo, transitions, &config.Config{ViewerBaseURL: "http://localhost:3000"})
+	scheduler := NewActivityScheduler(service, activityRepo, lock, time.Minute, 10*time.Minute)
+	scheduler.now = func() time.Time { return now }
+	return scheduler, service, activityRepo, transitions
+}
+
+func createScheduledActivity(t *testing.T, service *ActivityService, req domain.CreateActivityRequest) *domain.Activity {
+	t.Helper()
+	req.Title = "Scheduled"
+	req.Speaker = "Tester"
+	req.InputLanguage = "zh-CN"
+	req.TargetLanguages = []string{"en"}
+	activity, err := service.CreateActivity(domain.DefaultOrganizationID, &req)
+	if err != nil {
+		t.Fatalf("create activity failed: %v", err)
+	}
+	return activity
+}
+
+func activityStatus(t *testing.T, repo *repository.MemoryActivityRepository, id string) domain.ActivityStatus {
+	t.Helper()
+	activity, err := repo.FindByID(id)
+	if err != nil {
+		t.Fatalf("find activity failed: %v", err)
+	}
+	return activity.Status
+}
+
+func TestActivityScheduler_AutoPublish(t *testing.T) {
+	now := time.Now()
+	lock := &fakeSchedulerLock{}
+	scheduler, service, repo, transitions := newTestScheduler(t, now, lock)
+
+	due := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(5 * time.Minute), AutoPublish: true})
+	later := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(time.Hour), AutoPublish: true})
+	manual := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(5 * time.Minute)})
+
+	scheduler.RunOnce(context.Background())
+
+	if status := activityStatus(t, repo, due.ID); status != domain.ActivityStatusPublished {
+		t.Fatalf("activity within lead time should be published, got %s", status)
+	}
+	if status := activityStatus(t, repo, later.ID); status != domain.ActivityStatusDraft {
+		t.Fatalf("activity outside lead time should stay draft, got %s", status)
+	}
+	if status := activityStatus(t, repo, manual.ID); status != domain.ActivityStatusDraft {
+		t.Fatalf("activity without autoPublish should stay draft, got %s", status)
+	}
+
+	logged, _ := transitions.ListTransitions(context.Background(), due.ID)
+	if len(logged) != 1 || logged[0].Reason != domain.TransitionReasonAutoPublish || logged[0].ToStatus != domain.ActivityStatusPublished {
+		t.Fatalf("unexpected transitions: %+v", logged)
+	}
+	if lock.released != 1 {
+		t.Fatalf("lock should be released once, got %d", lock.released)
+	}
+}
+
+func TestActivityScheduler_AutoClose(t *testing.T) {
+	now := time.Now()
+	scheduler, service, repo, transitions := newTestScheduler(t, now, nil)
+
+	ended := now.Add(-time.Minute)
+	scheduledEnd := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(-time.Hour), ScheduledEndTime: &ended})
+	idle := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(-time.Hour), IdleCloseMinutes: 10})
+	active := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(-time.Hour), IdleCloseMinutes: 10})
+	for _, activity := range []*domain.Activity{scheduledEnd, idle, active} {
+		if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+	}
+
+	// 模拟音频上报：idle 已 15 分钟无音频，active 刚收到音频
+	setLastAudio := func(id string, at time.Time) {
+		activity, _ := repo.FindByID(id)
+		activity.LastAudioAt = &at
+		if err := repo.Update(activity); err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+	}
+	setLastAudio(idle.ID, now.Add(-15*time.Minute))
+	setLastAudio(active.ID, now.Add(-time.Minute))
+
+	scheduler.RunOnce(context.Background())
+
+	if status := activityStatus(t, repo, scheduledEnd.ID); status != domain.ActivityStatusClosed {
+		t.Fatalf("activity past scheduled end should be closed, got %s", status)
+	}
+	if status := activityStatus(t, repo, idle.ID); status != domain.ActivityStatusClosed {
+		t.Fatalf("idle activity should be closed, got %s", status)
+	}
+	if status := activityStatus(t, repo, active.ID); status != domain.ActivityStatusPublished {
+		t.Fatalf("activity with recent audio should stay published, got %s", status)
+	}
+
+	for id, reason := range map[string]string{
+		scheduledEnd.ID: domain.TransitionReasonScheduledEnd,
+		idle.ID:         domain.TransitionReasonIdle,
+	} {
+		logged, _ := transitions.ListTransitions(context.Background(), id)
+		last := logged[len(logged)-1]
+		if last.Reason != reason || last.FromStatus != domain.ActivityStatusPublished || last.ToStatus != domain.ActivityStatusClosed {
+			t.Fatalf("unexpected transition for %s: %+v", id, last)
+		}
+	}
+}
+
+func TestActivityScheduler_SkipsWithoutLock(t *testing.T) {
+	now := time.Now()
+	scheduler, service, repo, transitions := newTestScheduler(t, now, &fakeSchedulerLock{held: true})
+
+	activity := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(time.Minute), AutoPublish: true})
+	scheduler.RunOnce(context.Background())
+
+	if status := activityStatus(t, repo, activity.ID); status != domain.ActivityStatusDraft {
+		t.Fatalf("scheduler without lock should not publish, got %s", status)
+	}
+	if len(transitions.transitions) != 0 {
+		t.Fatalf("no transitions expected, got %d", len(transitions.transitions))
+	}
+}
+
+func TestActivityScheduler_TransitionKeepsConcurrentChanges(t *testing.T) {
+	now := time.Now()
+	_, service, repo, transitions := newTestScheduler(t, now, nil)
+
+	activity := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(-time.Hour)})
+	if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	// 调度器读到的快照之后，活动标题被编辑
+	stale, _ := repo.FindByID(activity.ID)
+	edited, _ := repo.FindByID(activity.ID)
+	edited.Title = "Edited"
+	if err := repo.Update(edited); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if err := service.close(stale, domain.TransitionReasonIdle); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	stored, _ := repo.FindByID(activity.ID)
+	if stored.Status != domain.ActivityStatusClosed || stored.Title != "Edited" {
+		t.Fatalf("close should only write status fields, got status=%s title=%q", stored.Status, stored.Title)
+	}
+
+	// 状态已被其他操作改变时不再写入，也不记录流转
+	logged, _ := transitions.ListTransitions(context.Background(), activity.ID)
+	again, _ := repo.FindByID(activity.ID)
+	again.Status = domain.ActivityStatusPublished
+	if err := service.close(again, domain.TransitionReasonIdle); !errors.Is(err, domain.ErrActivityStatusChanged) {
+		t.Fatalf("expected ErrActivityStatusChanged, got %v", err)
+	}
+	if after, _ := transitions.ListTransitions(context.Background(), activity.ID); len(after) != len(logged) {
+		t.Fatalf("stale transition should not be recorded, got %d transitions", len(after))
+	}
+}
+
+func TestActivityScheduler_EditRacingCloseKeepsClosed(t *testing.T) {
+	now := time.Now()
+	_, service, repo, transitions := newTestScheduler(t, now, nil)
+
+	activity := createScheduledActivity(t, service, domain.CreateActivityRequest{StartTime: now.Add(-time.Hour)})
+	if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	// 编辑请求读到已发布的活动后，活动被关闭，编辑再写回
+	editing, _ := repo.FindByID(activity.ID)
+	if _, err := service.CloseActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	editing.Title = "Edited"
+	if err := repo.Update(editing); !errors.Is(err, domain.ErrActivityStatusChanged) {
+		t.Fatalf("stale edit error = %v, want ErrActivityStatusChanged", err)
+	}
+
+	stored, _ := repo.FindByID(activity.ID)
+	if stored.Status != domain.ActivityStatusClosed || stored.EndTime == nil || stored.Title == "Edited" {
+		t.Fatalf("stale edit should not reopen activity, got status=%s endTime=%v title=%q", stored.Status, stored.EndTime, stored.Title)
+	}
+	logged, _ := transitions.ListTransitions(context.Background(), activity.ID)
+	if last := logged[len(logged)-1]; last.ToStatus != stored.Status {
+		t.Fatalf("last transition %s does not match stored status %s", last.ToStatus, stored.Status)
+	}
+}
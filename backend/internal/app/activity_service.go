@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,22 +11,38 @@ import (
 	"github.com/hoshea/orion-backend/internal/infra/config"
 )
 
+// ActivityTransitionRepository 定义活动状态变更记录的持久化接口
+type ActivityTransitionRepository interface {
+	RecordTransition(ctx context.Context, transition *domain.ActivityTransition) error
+	ListTransitions(ctx context.Context, activityID string) ([]*domain.ActivityTransition, error)
+}
+
 // ActivityService 活动管理服务
 type ActivityService struct {
 	repo          domain.ActivityRepository
+	transitions   ActivityTransitionRepository
 	viewerBaseURL string
+	closeHooks    []func(activity *domain.Activity)
 }
 
 // NewActivityService 创建活动服务
-func NewActivityService(repo domain.ActivityRepository, cfg *config.Config) *ActivityService {
+// transitions 为空时状态变更只写日志，不持久化
+func NewActivityService(repo domain.ActivityRepository, transitions ActivityTransitionRepository, cfg *config.Config) *ActivityService {
 	return &ActivityService{
 		repo:          repo,
+		transitions:   transitions,
 		viewerBaseURL: cfg.ViewerBaseURL,
 	}
 }
 
-// CreateActivity 创建活动
-func (s *ActivityService) CreateActivity(req *domain.CreateActivityRequest) (*domain.Activity, error) {
+// OnClose 注册活动关闭后的回调（手动关闭与调度器自动关闭都会触发），用于撤销访问并结束直播连接
+// 需在服务开始处理请求前注册
+func (s *ActivityService) OnClose(hook func(activity *domain.Activity)) {
+	s.closeHooks = append(s.closeHooks, hook)
+}
+
+// CreateActivity 在组织内创建活动
+func (s *ActivityService) CreateActivity(orgID string, req *domain.CreateActivityRequest) (*domain.Activity, error) {
 	// 生成活动 ID
 	activityID := uuid.New().String()
 
@@ -35,6 +53,7 @@ func (s *ActivityService) CreateActivity(req *domain.CreateActivityRequest) (*do
 	now := time.Now()
 	activity := &domain.Activity{
 		ID:              activityID,
+		OrganizationID:  orgID,
 		Title:           req.Title,
 		Description:     req.Description,
 		Speaker:         req.Speaker,
@@ -46,6 +65,11 @@ func (s *ActivityService) CreateActivity(req *domain.CreateActivityRequest) (*do
 		ViewerURL:       viewerURL,
 		CreatedAt:       now,
 		UpdatedAt:       now,
+
+		InterimTranslationIntervalMs: req.InterimTranslationIntervalMs,
+		AutoPublish:                  req.AutoPublish,
+		ScheduledEndTime:             req.ScheduledEndTime,
+		IdleCloseMinutes:             req.IdleCloseMinutes,
 	}
 
 	// 验证活动数据
@@ -62,9 +86,9 @@ func (s *ActivityService) CreateActivity(req *domain.CreateActivityRequest) (*do
 }
 
 // UpdateActivity 更新活动
-func (s *ActivityService) UpdateActivity(id string, req *domain.UpdateActivityRequest) (*domain.Activity, error) {
+func (s *ActivityService) UpdateActivity(orgID, id string, req *domain.UpdateActivityRequest) (*domain.Activity, error) {
 	// 查找活动
-	activity, err := s.repo.FindByID(id)
+	activity, err := s.repo.FindByIDInOrganization(orgID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +120,20 @@ func (s *ActivityService) UpdateActivity(id string, req *domain.UpdateActivityRe
 	if req.CoverURL != nil {
 		activity.CoverURL = *req.CoverURL
 	}
+	if req.InterimTranslationIntervalMs != nil {
+		activity.InterimTranslationIntervalMs = *req.InterimTranslationIntervalMs
+	}
+	if req.AutoPublish != nil {
+		activity.AutoPublish = *req.AutoPublish
+	}
+	if req.ClearScheduledEndTime {
+		activity.ScheduledEndTime = nil
+	} else if req.ScheduledEndTime != nil {
+		activity.ScheduledEndTime = req.ScheduledEndTime
+	}
+	if req.IdleCloseMinutes != nil {
+		activity.IdleCloseMinutes = *req.IdleCloseMinutes
+	}
 
 	activity.UpdatedAt = time.Now()
 
@@ -112,65 +150,109 @@ func (s *ActivityService) UpdateActivity(id string, req *domain.UpdateActivityRe
 	return activity, nil
 }
 
-// GetActivity 获取活动详情
-func (s *ActivityService) GetActivity(id string) (*domain.Activity, error) {
-	return s.repo.FindByID(id)
+// GetActivity 获取组织内的活动详情
+func (s *ActivityService) GetActivity(orgID, id string) (*domain.Activity, error) {
+	return s.repo.FindByIDInOrganization(orgID, id)
 }
 
-// ListActivities 列出活动
-func (s *ActivityService) ListActivities(status *domain.ActivityStatus) ([]*domain.Activity, error) {
+// ListActivities 列出组织内的活动
+func (s *ActivityService) ListActivities(orgID string, status *domain.ActivityStatus) ([]*domain.Activity, error) {
 	if status != nil {
-		return s.repo.FindByStatus(*status)
+		return s.repo.FindByStatus(orgID, *status)
 	}
-	return s.repo.FindAll()
+	return s.repo.FindAll(orgID)
 }
 
 // PublishActivity 发布活动
-func (s *ActivityService) PublishActivity(id string) (*domain.Activity, error) {
-	activity, err := s.repo.FindByID(id)
+func (s *ActivityService) PublishActivity(orgID, id string) (*domain.Activity, error) {
+	activity, err := s.repo.FindByIDInOrganization(orgID, id)
 	if err != nil {
 		return nil, err
 	}
-
-	// 发布活动
-	if err := activity.Publish(); err != nil {
+	if err := s.publish(activity, domain.TransitionReasonManual); err != nil {
 		return nil, err
 	}
-
-	// 保存状态变更
-	if err := s.repo.Update(activity); err != nil {
-		return nil, fmt.Errorf("发布活动失败: %w", err)
-	}
-
 	return activity, nil
 }
 
 // CloseActivity 关闭活动
-func (s *ActivityService) CloseActivity(id string) (*domain.Activity, error) {
-	activity, err := s.repo.FindByID(id)
+func (s *ActivityService) CloseActivity(orgID, id string) (*domain.Activity, error) {
+	activity, err := s.repo.FindByIDInOrganization(orgID, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.close(activity, domain.TransitionReasonManual); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
 
-	// 关闭活动
-	if err := activity.Close(); err != nil {
+// ListTransitions 列出组织内活动的状态变更记录
+func (s *ActivityService) ListTransitions(orgID, id string) ([]*domain.ActivityTransition, error) {
+	if _, err := s.repo.FindByIDInOrganization(orgID, id); err != nil {
 		return nil, err
 	}
+	if s.transitions == nil {
+		return []*domain.ActivityTransition{}, nil
+	}
+	return s.transitions.ListTransitions(context.Background(), id)
+}
 
-	// 保存状态变更
-	if err := s.repo.Update(activity); err != nil {
-		return nil, fmt.Errorf("关闭活动失败: %w", err)
+// publish 发布活动并记录状态变更
+func (s *ActivityService) publish(activity *domain.Activity, reason string) error {
+	from := activity.Status
+	if err := activity.Publish(); err != nil {
+		return err
+	}
+	// 只写状态相关字段，并发的手动操作或编辑不会被覆盖
+	if err := s.repo.UpdateStatus(activity, from); err != nil {
+		return fmt.Errorf("发布活动失败: %w", err)
 	}
+	s.recordTransition(activity, from, reason)
+	return nil
+}
 
-	// TODO: 关闭活动时自动失效二维码
+// close 关闭活动并记录状态变更
+func (s *ActivityService) close(activity *domain.Activity, reason string) error {
+	from := activity.Status
+	if err := activity.Close(); err != nil {
+		return err
+	}
+	// 只写状态相关字段，并发的手动操作或编辑不会被覆盖
+	if err := s.repo.UpdateStatus(activity, from); err != nil {
+		return fmt.Errorf("关闭活动失败: %w", err)
+	}
+	s.recordTransition(activity, from, reason)
+	for _, hook := range s.closeHooks {
+		hook(activity)
+	}
+	return nil
+}
 
-	return activity, nil
+// recordTransition 记录状态变更，持久化失败不影响已生效的状态
+func (s *ActivityService) recordTransition(activity *domain.Activity, from domain.ActivityStatus, reason string) {
+	log.Printf("Activity %s transitioned %s -> %s (%s)", activity.ID, from, activity.Status, reason)
+	if s.transitions == nil {
+		return
+	}
+
+	transition := &domain.ActivityTransition{
+		ID:         uuid.New().String(),
+		ActivityID: activity.ID,
+		FromStatus: from,
+		ToStatus:   activity.Status,
+		Reason:     reason,
+		CreatedAt:  activity.UpdatedAt,
+	}
+	if err := s.transitions.RecordTransition(context.Background(), transition); err != nil {
+		log.Printf("Warning: failed to record transition for activity %s: %v", activity.ID, err)
+	}
 }
 
 // DeleteActivity 删除活动
-func (s *ActivityService) DeleteActivity(id string) error {
+func (s *ActivityService) DeleteActivity(orgID, id string) error {
 	// 软删除策略：只允许删除草稿状态的活动
-	activity, err := s.repo.FindByID(id)
+	activity, err := s.repo.FindByIDInOrganization(orgID, id)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+func TestActivityService_OrganizationScope(t *testing.T) {
+	activityRepo := repository.NewMemoryActivityRepository()
+	service := NewActivityService(activityRepo, nil, &config.Config{ViewerBaseURL: "http://localhost:3000"})
+
+	const orgA, orgB = "org-a", "org-b"
+	activity, err := service.CreateActivity(orgA, &domain.CreateActivityRequest{
+		Title:           "Org A",
+		Speaker:         "Tester",
+		StartTime:       time.Now().Add(time.Hour),
+		InputLanguage:   "zh-CN",
+		TargetLanguages: []string{"en"},
+	})
+	if err != nil {
+		t.Fatalf("create activity failed: %v", err)
+	}
+	if activity.OrganizationID != orgA {
+		t.Fatalf("organizationId = %q, want %q", activity.OrganizationID, orgA)
+	}
+
+	if list, _ := service.ListActivities(orgA, nil); len(list) != 1 {
+		t.Fatalf("org A should see its activity, got %d", len(list))
+	}
+	if list, _ := service.ListActivities(orgB, nil); len(list) != 0 {
+		t.Fatalf("org B should not see org A activities, got %d", len(list))
+	}
+	draft := domain.ActivityStatusDraft
+	if list, _ := service.ListActivities(orgB, &draft); len(list) != 0 {
+		t.Fatalf("org B should not see org A drafts, got %d", len(list))
+	}
+
+	// 其他组织按不存在处理
+	if _, err := service.GetActivity(orgB, activity.ID); !errors.Is(err, domain.ErrActivityNotFound) {
+		t.Fatalf("expected ErrActivityNotFound, got %v", err)
+	}
+	if _, err := service.PublishActivity(orgB, activity.ID); !errors.Is(err, domain.ErrActivityNotFound) {
+		t.Fatalf("expected ErrActivityNotFound on publish, got %v", err)
+	}
+	if err := service.DeleteActivity(orgB, activity.ID); !errors.Is(err, domain.ErrActivityNotFound) {
+		t.Fatalf("expected ErrActivityNotFound on delete, got %v", err)
+	}
+
+	// 令牌继承活动所属组织
+	access := NewAccessService(activityRepo, newFakeAccessRepo(), "http://localhost:3000", nil)
+	token, err := access.GenerateSpeakerToken(activity.ID)
+	if err != nil {
+		t.Fatalf("generate speaker token failed: %v", err)
+	}
+	if token.OrganizationID != orgA {
+		t.Fatalf("token organizationId = %q, want %q", token.OrganizationID, orgA)
+	}
+}
+
+func TestActivityService_CloseRevokesAccess(t *testing.T) {
+	activityRepo := repository.NewMemoryActivityRepository()
+	cfg := &config.Config{ViewerBaseURL: "http://localhost:3000"}
+	service := NewActivityService(activityRepo, nil, cfg)
+	accessRepo := newFakeAccessRepo()
+	access := NewAccessService(activityRepo, accessRepo, cfg.ViewerBaseURL, nil)
+
+	var closed []string
+	service.OnClose(func(activity *domain.Activity) {
+		closed = append(closed, activity.ID)
+		if err := access.RevokeActivityAccess(activity.ID); err != nil {
+			t.Errorf("RevokeActivityAccess() error = %v", err)
+		}
+	})
+
+	activity, err := service.CreateActivity(domain.DefaultOrganizationID, &domain.CreateActivityRequest{
+		Title:           "Closing",
+		Speaker:         "Tester",
+		StartTime:       time.Now(),
+		InputLanguage:   "zh-CN",
+		TargetLanguages: []string{"en"},
+	})
+	if err != nil {
+		t.Fatalf("create activity failed: %v", err)
+	}
+	if _, err := service.PublishActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	speaker, err := access.GenerateSpeakerToken(activity.ID)
+	if err != nil {
+		t.Fatalf("generate speaker token failed: %v", err)
+	}
+	viewer, err := access.GenerateViewerToken(activity.ID, &domain.GenerateViewerTokenRequest{})
+	if err != nil {
+		t.Fatalf("generate viewer token failed: %v", err)
+	}
+	if _, err := access.ActivateViewerEntry(activity.ID); err != nil {
+		t.Fatalf("activate viewer entry failed: %v", err)
+	}
+
+	if _, err := service.CloseActivity(domain.DefaultOrganizationID, activity.ID); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if len(closed) != 1 || closed[0] != activity.ID {
+		t.Fatalf("close hook calls = %v", closed)
+	}
+
+	tokens, _ := access.ListTokens(activity.ID)
+	for _, token := range tokens {
+		if token.ID == speaker.ID || token.ID == viewer.ID {
+			if token.Status != domain.TokenStatusRevoked {
+				t.Fatalf("token %s status = %s, want revoked", token.ID, token.Status)
+			}
+		}
+	}
+	entry, err := access.GetViewerEntry(activity.ID)
+	if err != nil {
+		t.Fatalf("get viewer entry failed: %v", err)
+	}
+	if entry.Status != domain.ViewerEntryStatusRevoked {
+		t.Fatalf("viewer entry status = %s, want revoked", entry.Status)
+	}
+}
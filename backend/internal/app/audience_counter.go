@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
+)
+
+// audienceStoreTimeout 读写共享名额存储的超时时间
+const audienceStoreTimeout = 2 * time.Second
+
+// AudienceCounter 按观众令牌统计在线人数，用于执行 MaxAudience 限制
+// 每个观众占用一个带有效期的租约，连接期间定期续期，未释放的租约到期后自动失效。
+// 默认保存在进程内；多实例部署时设置共享存储，所有实例共用同一上限
+type AudienceCounter struct {
+	mu     sync.Mutex
+	leases map[string]map[string]time.Time // tokenID -> 租约 ID -> 到期时间
+	store  broadcast.LeaseStore            // 共享存储，nil 时使用 leases
+}
+
+// NewAudienceCounter 创建计数器
+func NewAudienceCounter() *AudienceCounter {
+	return &AudienceCounter{leases: make(map[string]map[string]time.Time)}
+}
+
+// SetStore 使用共享存储统计名额，需在使用前调用
+func (c *AudienceCounter) SetStore(store broadcast.LeaseStore) {
+	c.store = store
+}
+
+// Acquire 占用或续期一个名额，limit <= 0 表示不限；租约不存在且超过上限时返回 false
+// 共享存储不可用时放行，避免 Redis 故障导致观众无法接入
+func (c *AudienceCounter) Acquire(tokenID, leaseID string, limit int, ttl time.Duration) bool {
+	if c.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), audienceStoreTimeout)
+		defer cancel()
+		acquired, err := c.store.AcquireLease(ctx, tokenID, leaseID, limit, ttl)
+		if err != nil {
+			log.Printf("Warning: failed to acquire audience lease for token %s: %v", tokenID, err)
+			return true
+		}
+		return acquired
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	leases := c.prune(tokenID, now)
+	if _, exists := leases[leaseID]; !exists && limit > 0 && len(leases) >= limit {
+		return false
+	}
+	if leases == nil {
+		leases = make(map[string]time.Time)
+		c.leases[tokenID] = leases
+	}
+	leases[leaseID] = now.Add(ttl)
+	return true
+}
+
+// Release 释放一个名额
+func (c *AudienceCounter) Release(tokenID, leaseID string) {
+	if c.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), audienceStoreTimeout)
+		defer cancel()
+		if err := c.store.ReleaseLease(ctx, tokenID, leaseID); err != nil {
+			log.Printf("Warning: failed to release audience lease for token %s: %v", tokenID, err)
+		}
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.leases[tokenID], leaseID)
+	if len(c.leases[tokenID]) == 0 {
+		delete(c.leases, tokenID)
+	}
+}
+
+// Count 返回令牌当前在线人数
+func (c *AudienceCounter) Count(tokenID string) int {
+	if c.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), audienceStoreTimeout)
+		defer cancel()
+		count, err := c.store.CountLeases(ctx, tokenID)
+		if err != nil {
+			log.Printf("Warning: failed to count audience for token %s: %v", tokenID, err)
+		}
+		return count
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.prune(tokenID, time.Now()))
+}
+
+// prune 移除令牌已过期的租约并返回剩余租约，调用方需持有锁
+func (c *AudienceCounter) prune(tokenID string, now time.Time) map[string]time.Time {
+	leases := c.leases[tokenID]
+	for leaseID, expiresAt := range leases {
+		if !expiresAt.After(now) {
+			delete(leases, leaseID)
+		}
+	}
+	if leases != nil && len(leases) == 0 {
+		delete(c.leases, tokenID)
+		return nil
+	}
+	return leases
+}
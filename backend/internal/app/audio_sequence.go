@@ -0,0 +1,65 @@
+package app
+
+// AudioSequenceTracker 跟踪演讲者推流的音频序列号，检测缺失与乱序；断线重连后沿用
+// 非并发安全，由调用方负责同步
+type AudioSequenceTracker struct {
+	started   bool
+	next      uint32 // 期望的下一个序列号
+	committed bool
+	last      uint32 // 最近成功送入识别的序列号
+
+	Received   int    // 接收的音频块数
+	Missing    uint32 // 累计缺失的块数
+	OutOfOrder int    // 累计迟到或重复的块数
+	Dropped    int    // 序列号正常但未能送入识别的块数
+}
+
+// AudioSequenceResult 单个音频块的检查结果
+type AudioSequenceResult struct {
+	Accept     bool   // 是否送入识别，迟到或重复的块丢弃
+	Expected   uint32 // 期望的序列号
+	Missing    uint32 // 本块之前缺失的块数
+	OutOfOrder bool   // 是否迟到或重复
+}
+
+// LastAccepted 返回最近成功送入识别（已 Commit）的序列号，尚无时返回 0, false
+func (t *AudioSequenceTracker) LastAccepted() (sequence uint32, ok bool) {
+	return t.last, t.committed
+}
+
+// Commit 记录 Observe 接受的音频块已送入识别
+func (t *AudioSequenceTracker) Commit(sequence uint32) {
+	t.committed = true
+	t.last = sequence
+}
+
+// Drop 记录 Observe 接受的音频块未能送入识别，LastAccepted 不前进
+func (t *AudioSequenceTracker) Drop() {
+	t.Dropped++
+}
+
+// Observe 检查序列号，首个音频块的序列号作为起点
+// 接受的块由调用方送入识别后再 Commit 或 Drop
+func (t *AudioSequenceTracker) Observe(sequence uint32) AudioSequenceResult {
+	t.Received++
+	if !t.started {
+		t.started = true
+		t.next = sequence + 1
+		return AudioSequenceResult{Accept: true, Expected: sequence}
+	}
+
+	expected := t.next
+	switch {
+	case sequence == expected:
+		t.next++
+		return AudioSequenceResult{Accept: true, Expected: expected}
+	case sequence > expected:
+		missing := sequence - expected
+		t.Missing += missing
+		t.next = sequence + 1
+		return AudioSequenceResult{Accept: true, Expected: expected, Missing: missing}
+	default:
+		t.OutOfOrder++
+		return AudioSequenceResult{Expected: expected, OutOfOrder: true}
+	}
+}
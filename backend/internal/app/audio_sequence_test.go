@@ -0,0 +1,57 @@
+package app
+
+import "testing"
+
+func TestAudioSequenceTracker(t *testing.T) {
+	var tracker AudioSequenceTracker
+
+	steps := []struct {
+		sequence uint32
+		want     AudioSequenceResult
+	}{
+		{sequence: 5, want: AudioSequenceResult{Accept: true, Expected: 5}},
+		{sequence: 6, want: AudioSequenceResult{Accept: true, Expected: 6}},
+		{sequence: 9, want: AudioSequenceResult{Accept: true, Expected: 7, Missing: 2}},
+		{sequence: 8, want: AudioSequenceResult{Expected: 10, OutOfOrder: true}},
+		{sequence: 9, want: AudioSequenceResult{Expected: 10, OutOfOrder: true}},
+		{sequence: 10, want: AudioSequenceResult{Accept: true, Expected: 10}},
+	}
+	for i, step := range steps {
+		if got := tracker.Observe(step.sequence); got != step.want {
+			t.Fatalf("step %d: Observe(%d) = %+v, want %+v", i, step.sequence, got, step.want)
+		}
+	}
+
+	if tracker.Received != 6 || tracker.Missing != 2 || tracker.OutOfOrder != 2 {
+		t.Fatalf("stats = received %d, missing %d, out of order %d", tracker.Received, tracker.Missing, tracker.OutOfOrder)
+	}
+}
+
+func TestAudioSequenceTracker_CommitAndDrop(t *testing.T) {
+	var tracker AudioSequenceTracker
+
+	if _, ok := tracker.LastAccepted(); ok {
+		t.Fatal("LastAccepted() before any audio should be false")
+	}
+	// 接受但尚未送入识别的块不计入 LastAccepted
+	tracker.Observe(1)
+	if _, ok := tracker.LastAccepted(); ok {
+		t.Fatal("LastAccepted() before Commit should be false")
+	}
+	tracker.Commit(1)
+
+	tracker.Observe(2)
+	tracker.Drop()
+	if last, ok := tracker.LastAccepted(); !ok || last != 1 {
+		t.Fatalf("LastAccepted() after Drop = %d, %t; want 1", last, ok)
+	}
+	if tracker.Dropped != 1 {
+		t.Fatalf("Dropped = %d, want 1", tracker.Dropped)
+	}
+
+	tracker.Observe(3)
+	tracker.Commit(3)
+	if last, _ := tracker.LastAccepted(); last != 3 {
+		t.Fatalf("LastAccepted() = %d, want 3", last)
+	}
+}
@@ -1,38 +1,44 @@
 package app
 
 import (
-	"crypto/hmac"
+	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"log"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hoshea/orion-backend/internal/domain"
 	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/jwtkeys"
 )
 
-// AuthService 负责管理员登录、令牌签发与刷新
-type AuthService struct {
-	adminUsername string
-	adminPassword string
-	signingKey    []byte
-	accessTTL     time.Duration
-	refreshTTL    time.Duration
-	mu            sync.RWMutex
-	refreshTokens map[string]*RefreshSession
+// RefreshSessionRepository 定义刷新令牌会话的持久化接口
+type RefreshSessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.RefreshSession) error
+	// FindSessionByTokenHash 查找记录（包括已轮换、已注销的记录），不存在时返回 domain.ErrSessionNotFound
+	FindSessionByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshSession, error)
+	// RotateSession 将仍有效的记录标记为已轮换，返回是否成功
+	RotateSession(ctx context.Context, id string, at time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, userID, familyID string, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID string, at time.Time) (int, error)
+	ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]*domain.RefreshSession, error)
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error)
 }
 
-// RefreshSession 刷新令牌会话信息
-type RefreshSession struct {
-	Token     string
-	UserID    string
-	Role      string
-	ExpiresAt time.Time
+// AuthService 负责管理后台用户登录、令牌签发与刷新
+type AuthService struct {
+	users      *UserService
+	sessions   RefreshSessionRepository
+	keys       *jwtkeys.KeySet
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
 // AuthTokens 登录或刷新后返回的令牌对
@@ -46,69 +52,219 @@ type AuthTokens struct {
 type Claims struct {
 	UserID    string `json:"uid"`
 	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // 登录会话 ID，对应刷新令牌 family
 	Subject   string `json:"sub"`
 	IssuedAt  int64  `json:"iat"`
 	ExpiresAt int64  `json:"exp"`
 	NotBefore int64  `json:"nbf"`
+	// OrganizationID 当前所在组织，管理接口按该组织隔离数据
+	OrganizationID string `json:"org,omitempty"`
 }
 
-const defaultRoleAdmin = "admin"
-
 // NewAuthService 创建 AuthService
-func NewAuthService(cfg *config.Config) (*AuthService, error) {
+func NewAuthService(cfg *config.Config, users *UserService, sessions RefreshSessionRepository) (*AuthService, error) {
 	if cfg == nil {
 		return nil, errors.New("config is required")
 	}
-
-	if cfg.Auth.AdminUsername == "" || cfg.Auth.AdminPassword == "" {
-		return nil, errors.New("admin username/password must be configured")
+	if users == nil {
+		return nil, errors.New("user service is required")
+	}
+	if sessions == nil {
+		return nil, errors.New("session repository is required")
 	}
 
-	signingKey, err := loadSigningKey(cfg.Auth.JWTSecretPath)
+	keys, err := jwtkeys.Load(cfg.Auth.JWTSecretPath, cfg.Auth.JWTVerificationKeyPaths)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthService{
-		adminUsername: cfg.Auth.AdminUsername,
-		adminPassword: cfg.Auth.AdminPassword,
-		signingKey:    signingKey,
-		accessTTL:     cfg.Auth.AccessTokenTTL,
-		refreshTTL:    cfg.Auth.RefreshTokenTTL,
-		refreshTokens: make(map[string]*RefreshSession),
+		users:      users,
+		sessions:   sessions,
+		keys:       keys,
+		accessTTL:  cfg.Auth.AccessTokenTTL,
+		refreshTTL: cfg.Auth.RefreshTokenTTL,
 	}, nil
 }
 
-// Authenticate 校验管理员账号并签发令牌
-func (s *AuthService) Authenticate(username, password string) (*AuthTokens, error) {
-	if username != s.adminUsername || password != s.adminPassword {
-		return nil, errors.New("用户名或密码错误")
+// Authenticate 校验用户名密码并签发令牌，令牌携带用户 ID 与角色
+func (s *AuthService) Authenticate(username, password string, meta domain.SessionMetadata) (*AuthTokens, error) {
+	user, err := s.users.VerifyCredentials(username, password)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.issueTokens(s.adminUsername, defaultRoleAdmin)
+	now := time.Now()
+	session := &domain.RefreshSession{
+		FamilyID:       uuid.New().String(),
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		StartedAt:      now,
+	}
+	return s.issueTokens(user, session, meta, now)
 }
 
 // Refresh 使用 refresh token 刷新访问令牌
-func (s *AuthService) Refresh(refreshToken string) (*AuthTokens, error) {
+// 刷新令牌只能使用一次，已轮换的令牌被再次使用时视为泄露，注销整个会话
+func (s *AuthService) Refresh(refreshToken string, meta domain.SessionMetadata) (*AuthTokens, error) {
+	return s.rotate(refreshToken, meta, "")
+}
+
+// SwitchOrganization 超级管理员切换到指定组织，轮换刷新令牌并签发携带新组织的访问令牌
+// 调用方需先确认组织存在；refreshToken 必须属于 userID
+func (s *AuthService) SwitchOrganization(userID, refreshToken, orgID string, meta domain.SessionMetadata) (*AuthTokens, error) {
 	if refreshToken == "" {
 		return nil, errors.New("刷新令牌不能为空")
 	}
+	session, err := s.sessions.FindSessionByTokenHash(context.Background(), hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
 
-	s.mu.RLock()
-	session, ok := s.refreshTokens[refreshToken]
-	s.mu.RUnlock()
-	if !ok {
-		return nil, errors.New("刷新令牌无效")
+	user, err := s.users.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != domain.UserRoleSuperAdmin {
+		return nil, domain.ErrRoleNotAllowed
 	}
+	return s.rotate(refreshToken, meta, orgID)
+}
 
-	if time.Now().After(session.ExpiresAt) {
-		s.DeleteRefreshToken(refreshToken)
-		return nil, errors.New("刷新令牌已过期")
+// rotate 轮换刷新令牌，targetOrgID 非空时（仅超级管理员）切换会话所在组织
+func (s *AuthService) rotate(refreshToken string, meta domain.SessionMetadata, targetOrgID string) (*AuthTokens, error) {
+	if refreshToken == "" {
+		return nil, errors.New("刷新令牌不能为空")
 	}
 
-	// 刷新时旋转刷新令牌
-	s.DeleteRefreshToken(refreshToken)
-	return s.issueTokens(session.UserID, session.Role)
+	ctx := context.Background()
+	now := time.Now()
+	session, err := s.sessions.FindSessionByTokenHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+	if session.RotatedAt != nil {
+		return nil, s.revokeReusedFamily(session, now)
+	}
+
+	// 条件更新保证并发刷新时只有一个请求成功，其余按重放处理
+	rotated, err := s.sessions.RotateSession(ctx, session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.revokeReusedFamily(session, now)
+	}
+
+	// 重新读取用户，使角色变更或删除在刷新时生效
+	user, err := s.users.GetUser(session.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	// 普通用户始终位于所属组织；超级管理员沿用会话中切换后的组织
+	orgID := user.OrganizationID
+	if user.Role == domain.UserRoleSuperAdmin {
+		if targetOrgID != "" {
+			orgID = targetOrgID
+		} else if session.OrganizationID != "" {
+			orgID = session.OrganizationID
+		}
+	}
+
+	next := &domain.RefreshSession{
+		FamilyID:       session.FamilyID,
+		UserID:         user.ID,
+		OrganizationID: orgID,
+		StartedAt:      session.StartedAt,
+	}
+	if meta.Device == "" {
+		meta.Device = session.Device
+	}
+	return s.issueTokens(user, next, meta, now)
+}
+
+// revokeReusedFamily 注销重放令牌所属的整个会话
+func (s *AuthService) revokeReusedFamily(session *domain.RefreshSession, now time.Time) error {
+	log.Printf("Warning: reused refresh token detected for user %s, revoking session %s", session.UserID, session.FamilyID)
+	err := s.sessions.RevokeFamily(context.Background(), session.UserID, session.FamilyID, now)
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+// Logout 注销刷新令牌所属的会话，令牌无效时视为已注销
+func (s *AuthService) Logout(refreshToken string) error {
+	ctx := context.Background()
+	session, err := s.sessions.FindSessionByTokenHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	err = s.sessions.RevokeFamily(ctx, session.UserID, session.FamilyID, time.Now())
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ListSessions 列出用户仍有效的登录会话，currentSessionID 对应的会话标记为当前会话
+func (s *AuthService) ListSessions(userID, currentSessionID string) ([]domain.SessionInfo, error) {
+	sessions, err := s.sessions.ListActiveSessions(context.Background(), userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, domain.SessionInfo{
+			ID:         session.FamilyID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			StartedAt:  session.StartedAt,
+			LastUsedAt: session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.FamilyID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+// RevokeSession 注销用户的指定会话
+func (s *AuthService) RevokeSession(userID, sessionID string) error {
+	return s.sessions.RevokeFamily(context.Background(), userID, sessionID, time.Now())
+}
+
+// RevokeAllSessions 注销用户的全部会话，返回注销的会话数
+// 已签发的访问令牌在过期前仍然有效
+func (s *AuthService) RevokeAllSessions(userID string) (int, error) {
+	return s.sessions.RevokeUserSessions(context.Background(), userID, time.Now())
+}
+
+// PurgeExpiredSessions 清理过期的刷新令牌记录
+func (s *AuthService) PurgeExpiredSessions() (int64, error) {
+	return s.sessions.DeleteExpiredSessions(context.Background(), time.Now())
 }
 
 // ValidateAccessToken 解析并验证访问令牌
@@ -122,9 +278,29 @@ func (s *AuthService) ValidateAccessToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("访问令牌格式错误")
 	}
 
-	unsigned := parts[0] + "." + parts[1]
-	expectedSig := signToken(unsigned, s.signingKey)
-	if !hmac.Equal([]byte(expectedSig), []byte(parts[2])) {
+	headerBytes, err := base64URLDecode(parts[0])
+	if err != nil {
+		return nil, errors.New("访问令牌头部无法解析")
+	}
+	var header tokenHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, errors.New("访问令牌头部格式错误")
+	}
+	signature, err := base64URLDecode(parts[2])
+	if err != nil {
+		return nil, errors.New("访问令牌签名无效")
+	}
+
+	// 按 kid 选择验证密钥，轮换期间旧密钥签发的令牌仍可验证
+	unsigned := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range s.keys.VerificationKeys(header.KeyID, header.Algorithm) {
+		if key.Verify(unsigned, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
 		return nil, errors.New("访问令牌签名无效")
 	}
 
@@ -148,30 +324,28 @@ func (s *AuthService) ValidateAccessToken(tokenString string) (*Claims, error) {
 	return &claims, nil
 }
 
-// DeleteRefreshToken 移除刷新令牌
-func (s *AuthService) DeleteRefreshToken(token string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	delete(s.refreshTokens, token)
-}
-
-func (s *AuthService) issueTokens(userID, role string) (*AuthTokens, error) {
-	accessToken, err := s.generateAccessToken(userID, role)
+// issueTokens 签发访问令牌，并在会话中登记新的刷新令牌
+func (s *AuthService) issueTokens(user *domain.User, session *domain.RefreshSession, meta domain.SessionMetadata, now time.Time) (*AuthTokens, error) {
+	accessToken, err := s.generateAccessToken(user.ID, string(user.Role), session.FamilyID, session.OrganizationID)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken := uuid.New().String()
-	expiresAt := time.Now().Add(s.refreshTTL)
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
 
-	s.mu.Lock()
-	s.refreshTokens[refreshToken] = &RefreshSession{
-		Token:     refreshToken,
-		UserID:    userID,
-		Role:      role,
-		ExpiresAt: expiresAt,
+	session.ID = uuid.New().String()
+	session.TokenHash = hashRefreshToken(refreshToken)
+	session.Device = truncateMetadata(meta.Device)
+	session.UserAgent = truncateMetadata(meta.UserAgent)
+	session.IP = meta.IP
+	session.CreatedAt = now
+	session.ExpiresAt = now.Add(s.refreshTTL)
+	if err := s.sessions.CreateSession(context.Background(), session); err != nil {
+		return nil, err
 	}
-	s.mu.Unlock()
 
 	return &AuthTokens{
 		AccessToken:  accessToken,
@@ -180,16 +354,35 @@ func (s *AuthService) issueTokens(userID, role string) (*AuthTokens, error) {
 	}, nil
 }
 
-func (s *AuthService) generateAccessToken(userID, role string) (string, error) {
+// JWKS 返回可公开的验证公钥，供其他服务验证访问令牌
+func (s *AuthService) JWKS() jwtkeys.JWKS {
+	return s.keys.JWKS()
+}
+
+// tokenHeader JWT 头部
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+func (s *AuthService) generateAccessToken(userID, role, sessionID, orgID string) (string, error) {
 	now := time.Now()
-	headerSegment := base64URLEncode([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	key := s.keys.Signing()
+	headerBytes, err := json.Marshal(tokenHeader{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", fmt.Errorf("序列化访问令牌头部失败: %w", err)
+	}
 	claims := Claims{
 		UserID:    userID,
 		Role:      role,
+		SessionID: sessionID,
 		Subject:   userID,
 		IssuedAt:  now.Unix(),
 		ExpiresAt: now.Add(s.accessTTL).Unix(),
 		NotBefore: now.Unix(),
+
+		OrganizationID: orgID,
 	}
 
 	payloadBytes, err := json.Marshal(claims)
@@ -197,17 +390,38 @@ func (s *AuthService) generateAccessToken(userID, role string) (string, error) {
 		return "", fmt.Errorf("序列化访问令牌载荷失败: %w", err)
 	}
 
-	payloadSegment := base64URLEncode(payloadBytes)
-	unsigned := headerSegment + "." + payloadSegment
-	signature := signToken(unsigned, s.signingKey)
+	unsigned := base64URLEncode(headerBytes) + "." + base64URLEncode(payloadBytes)
+	signature, err := key.Sign([]byte(unsigned))
+	if err != nil {
+		return "", fmt.Errorf("签名访问令牌失败: %w", err)
+	}
+
+	return unsigned + "." + base64URLEncode(signature), nil
+}
+
+// generateRefreshToken 生成 256 位随机刷新令牌
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	return base64URLEncode(buf), nil
+}
 
-	return unsigned + "." + signature, nil
+// hashRefreshToken 计算刷新令牌摘要，数据库只保存摘要
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-func signToken(unsigned string, key []byte) string {
-	mac := hmac.New(sha256.New, key)
-	mac.Write([]byte(unsigned))
-	return base64URLEncode(mac.Sum(nil))
+// truncateMetadata 限制客户端信息长度
+func truncateMetadata(value string) string {
+	const maxLen = 256
+	value = strings.TrimSpace(value)
+	if len(value) > maxLen {
+		return strings.ToValidUTF8(value[:maxLen], "")
+	}
+	return value
 }
 
 func base64URLEncode(data []byte) string {
@@ -217,24 +431,3 @@ func base64URLEncode(data []byte) string {
 func base64URLDecode(s string) ([]byte, error) {
 	return base64.RawURLEncoding.DecodeString(s)
 }
-
-func loadSigningKey(path string) ([]byte, error) {
-	if strings.TrimSpace(path) == "" {
-		return nil, errors.New("JWT_SECRET_PATH 未配置")
-	}
-
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("读取 JWT 私钥失败: %w", err)
-	}
-
-	key := strings.TrimSpace(string(content))
-	if key == "" {
-		return nil, errors.New("JWT 私钥文件内容为空")
-	}
-	if len(key) < 32 {
-		return nil, errors.New("JWT 私钥长度至少 32 个字符")
-	}
-
-	return []byte(key), nil
-}
@@ -0,0 +1,372 @@
+package app
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/config"
+)
+
+type fakeSessionRepo struct {
+	sessions map[string]*domain.RefreshSession // id -> session
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: make(map[string]*domain.RefreshSession)}
+}
+
+func (f *fakeSessionRepo) CreateSession(_ context.Context, session *domain.RefreshSession) error {
+	cloned := *session
+	f.sessions[session.ID] = &cloned
+	return nil
+}
+
+func (f *fakeSessionRepo) FindSessionByTokenHash(_ context.Context, tokenHash string) (*domain.RefreshSession, error) {
+	for _, session := range f.sessions {
+		if session.TokenHash == tokenHash {
+			cloned := *session
+			return &cloned, nil
+		}
+	}
+	return nil, domain.ErrSessionNotFound
+}
+
+func (f *fakeSessionRepo) RotateSession(_ context.Context, id string, at time.Time) (bool, error) {
+	session, ok := f.sessions[id]
+	if !ok || session.RotatedAt != nil || session.RevokedAt != nil {
+		return false, nil
+	}
+	session.RotatedAt = &at
+	return true, nil
+}
+
+func (f *fakeSessionRepo) RevokeFamily(_ context.Context, userID, familyID string, at time.Time) error {
+	revoked := 0
+	for _, session := range f.sessions {
+		if session.FamilyID == familyID && session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			revoked++
+		}
+	}
+	if revoked == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (f *fakeSessionRepo) RevokeUserSessions(_ context.Context, userID string, at time.Time) (int, error) {
+	families := make(map[string]struct{})
+	for _, session := range f.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			families[session.FamilyID] = struct{}{}
+		}
+	}
+	return len(families), nil
+}
+
+func (f *fakeSessionRepo) ListActiveSessions(_ context.Context, userID string, now time.Time) ([]*domain.RefreshSession, error) {
+	var result []*domain.RefreshSession
+	for _, session := range f.sessions {
+		if session.UserID == userID && session.RotatedAt == nil && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			cloned := *session
+			result = append(result, &cloned)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (f *fakeSessionRepo) DeleteExpiredSessions(_ context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for id, session := range f.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(f.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newTestAuthService(t *testing.T, users *UserService) *AuthService {
+	t.Helper()
+	auth, _ := newTestAuthServiceWithSessions(t, users)
+	return auth
+}
+
+func newTestAuthServiceWithSessions(t *testing.T, users *UserService) (*AuthService, *fakeSessionRepo) {
+	t.Helper()
+	secretPath := filepath.Join(t.TempDir(), "jwt.key")
+	if err := os.WriteFile(secretPath, []byte(strings.Repeat("k", 32)), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	return newTestAuthServiceWithKeys(t, users, secretPath)
+}
+
+func newTestAuthServiceWithKeys(t *testing.T, users *UserService, signingPath string, verificationPaths ...string) (*AuthService, *fakeSessionRepo) {
+	t.Helper()
+	cfg := &config.Config{Auth: config.AuthConfig{
+		JWTSecretPath:           signingPath,
+		JWTVerificationKeyPaths: verificationPaths,
+		AccessTokenTTL:          time.Minute,
+		RefreshTokenTTL:         time.Hour,
+	}}
+	sessions := newFakeSessionRepo()
+	auth, err := NewAuthService(cfg, users, sessions)
+	if err != nil {
+		t.Fatalf("NewAuthService() error = %v", err)
+	}
+	return auth, sessions
+}
+
+func newTestOwner(t *testing.T) *UserService {
+	t.Helper()
+	users := NewUserService(newFakeUserRepo())
+	_, err := users.CreateUser(domain.DefaultOrganizationID, domain.UserRoleSuperAdmin, &domain.CreateUserRequest{
+		Username: "admin", Password: "admin123", Role: domain.UserRoleOwner,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return users
+}
+
+func TestAuthService_RefreshTokenReuseRevokesFamily(t *testing.T) {
+	auth, sessions := newTestAuthServiceWithSessions(t, newTestOwner(t))
+
+	first, err := auth.Authenticate("admin", "admin123", domain.SessionMetadata{Device: "laptop", UserAgent: "Firefox", IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	for _, session := range sessions.sessions {
+		if session.TokenHash == first.RefreshToken || session.Device != "laptop" || session.IP != "10.0.0.1" {
+			t.Fatalf("unexpected stored session: %+v", session)
+		}
+	}
+
+	second, err := auth.Refresh(first.RefreshToken, domain.SessionMetadata{UserAgent: "Firefox", IP: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	firstClaims, _ := auth.ValidateAccessToken(first.AccessToken)
+	secondClaims, _ := auth.ValidateAccessToken(second.AccessToken)
+	if firstClaims.SessionID == "" || firstClaims.SessionID != secondClaims.SessionID {
+		t.Fatalf("session id should be kept across rotation: %q vs %q", firstClaims.SessionID, secondClaims.SessionID)
+	}
+
+	// 重放已轮换的令牌，整个会话被注销，最新的令牌也随之失效
+	if _, err := auth.Refresh(first.RefreshToken, domain.SessionMetadata{}); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("expected ErrRefreshTokenReused, got %v", err)
+	}
+	if _, err := auth.Refresh(second.RefreshToken, domain.SessionMetadata{}); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Fatalf("expected ErrRefreshTokenInvalid after family revocation, got %v", err)
+	}
+}
+
+func TestAuthService_SessionsAndLogout(t *testing.T) {
+	auth := newTestAuthService(t, newTestOwner(t))
+
+	laptop, _ := auth.Authenticate("admin", "admin123", domain.SessionMetadata{Device: "laptop"})
+	phone, _ := auth.Authenticate("admin", "admin123", domain.SessionMetadata{Device: "phone"})
+	tablet, _ := auth.Authenticate("admin", "admin123", domain.SessionMetadata{Device: "tablet"})
+	claims, _ := auth.ValidateAccessToken(laptop.AccessToken)
+
+	// 刷新后仍是同一个会话，沿用登录时的设备名
+	if _, err := auth.Refresh(laptop.RefreshToken, domain.SessionMetadata{IP: "10.0.0.9"}); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	sessions, err := auth.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %+v", sessions)
+	}
+	current := 0
+	for _, session := range sessions {
+		if session.Current {
+			current++
+			if session.ID != claims.SessionID || session.Device != "laptop" || session.IP != "10.0.0.9" {
+				t.Fatalf("unexpected current session: %+v", session)
+			}
+		}
+	}
+	if current != 1 {
+		t.Fatalf("expected exactly one current session, got %d", current)
+	}
+
+	if err := auth.Logout(phone.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := auth.Refresh(phone.RefreshToken, domain.SessionMetadata{}); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Fatalf("expected logged out token to be invalid, got %v", err)
+	}
+	if err := auth.Logout("unknown-token"); err != nil {
+		t.Fatalf("Logout() with unknown token error = %v", err)
+	}
+
+	count, err := auth.RevokeAllSessions(claims.UserID)
+	if err != nil || count != 2 {
+		t.Fatalf("RevokeAllSessions() = %d, %v; want 2", count, err)
+	}
+	if _, err := auth.Refresh(tablet.RefreshToken, domain.SessionMetadata{}); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Fatalf("expected revoked token to be invalid, got %v", err)
+	}
+	if sessions, _ := auth.ListSessions(claims.UserID, claims.SessionID); len(sessions) != 0 {
+		t.Fatalf("expected no active sessions, got %+v", sessions)
+	}
+}
+
+func writeEd25519Key(t *testing.T, dir, name string) (privatePath, publicPath string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+
+	privatePath = filepath.Join(dir, name+".pem")
+	publicPath = filepath.Join(dir, name+".pub")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatalf("write private key: %v", err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	return privatePath, publicPath
+}
+
+func TestAuthService_KeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeEd25519Key(t, dir, "old")
+	newPrivate, _ := writeEd25519Key(t, dir, "new")
+	users := newTestOwner(t)
+
+	before, _ := newTestAuthServiceWithKeys(t, users, oldPrivate)
+	oldTokens, err := before.Authenticate("admin", "admin123", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	// 轮换后旧密钥签发的令牌仍可验证，新令牌使用新密钥
+	after, _ := newTestAuthServiceWithKeys(t, users, newPrivate, oldPublic)
+	if _, err := after.ValidateAccessToken(oldTokens.AccessToken); err != nil {
+		t.Fatalf("token signed by rotated key should validate: %v", err)
+	}
+	newTokens, err := after.Authenticate("admin", "admin123", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if _, err := before.ValidateAccessToken(newTokens.AccessToken); err == nil {
+		t.Fatal("old instance should not accept tokens signed by an unknown key")
+	}
+	if keys := after.JWKS().Keys; len(keys) != 2 {
+		t.Fatalf("expected 2 keys in JWKS, got %d", len(keys))
+	}
+
+	// 旧公钥移除后，旧令牌失效
+	removed, _ := newTestAuthServiceWithKeys(t, users, newPrivate)
+	if _, err := removed.ValidateAccessToken(oldTokens.AccessToken); err == nil {
+		t.Fatal("token signed by removed key should be rejected")
+	}
+}
+
+func TestAuthService_RejectsAlgorithmConfusion(t *testing.T) {
+	privatePath, publicPath := writeEd25519Key(t, t.TempDir(), "signing")
+	auth, _ := newTestAuthServiceWithKeys(t, newTestOwner(t), privatePath)
+	tokens, err := auth.Authenticate("admin", "admin123", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	parts := strings.Split(tokens.AccessToken, ".")
+	kid := auth.JWKS().Keys[0].KeyID
+
+	publicPEM, _ := os.ReadFile(publicPath)
+	forge := func(header string, key []byte) string {
+		unsigned := base64URLEncode([]byte(header)) + "." + parts[1]
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(unsigned))
+		return unsigned + "." + base64URLEncode(mac.Sum(nil))
+	}
+
+	forged := []string{
+		// 以公开的公钥作为 HMAC 密钥伪造
+		forge(`{"alg":"HS256","typ":"JWT","kid":"`+kid+`"}`, publicPEM),
+		forge(`{"alg":"HS256","typ":"JWT"}`, publicPEM),
+		base64URLEncode([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".",
+	}
+	for _, token := range forged {
+		if _, err := auth.ValidateAccessToken(token); err == nil {
+			t.Fatalf("forged token should be rejected: %s", token)
+		}
+	}
+}
+
+func TestAuthService_SwitchOrganization(t *testing.T) {
+	users := NewUserService(newFakeUserRepo())
+	root, err := users.CreateUser(domain.DefaultOrganizationID, domain.UserRoleSuperAdmin, &domain.CreateUserRequest{
+		Username: "root", Password: "password", Role: domain.UserRoleSuperAdmin,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	owner, err := users.CreateUser("org-b", domain.UserRoleSuperAdmin, &domain.CreateUserRequest{
+		Username: "owner", Password: "password", Role: domain.UserRoleOwner,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	auth := newTestAuthService(t, users)
+
+	tokens, err := auth.Authenticate("root", "password", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	switched, err := auth.SwitchOrganization(root.ID, tokens.RefreshToken, "org-b", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("SwitchOrganization() error = %v", err)
+	}
+	claims, _ := auth.ValidateAccessToken(switched.AccessToken)
+	if claims.OrganizationID != "org-b" {
+		t.Fatalf("org = %q, want org-b", claims.OrganizationID)
+	}
+
+	// 切换后刷新仍保持在目标组织，旧刷新令牌已轮换
+	refreshed, err := auth.Refresh(switched.RefreshToken, domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if claims, _ := auth.ValidateAccessToken(refreshed.AccessToken); claims.OrganizationID != "org-b" {
+		t.Fatalf("org after refresh = %q, want org-b", claims.OrganizationID)
+	}
+
+	// 普通用户不能切换组织，也不能使用他人的刷新令牌
+	ownerTokens, err := auth.Authenticate("owner", "password", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if claims, _ := auth.ValidateAccessToken(ownerTokens.AccessToken); claims.OrganizationID != "org-b" {
+		t.Fatalf("owner org = %q, want org-b", claims.OrganizationID)
+	}
+	if _, err := auth.SwitchOrganization(owner.ID, ownerTokens.RefreshToken, domain.DefaultOrganizationID, domain.SessionMetadata{}); !errors.Is(err, domain.ErrRoleNotAllowed) {
+		t.Fatalf("expected ErrRoleNotAllowed, got %v", err)
+	}
+	if _, err := auth.SwitchOrganization(root.ID, ownerTokens.RefreshToken, domain.DefaultOrganizationID, domain.SessionMetadata{}); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Fatalf("expected ErrRefreshTokenInvalid for another user's token, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/caption"
+)
+
+const (
+	// minCueDuration 单条字幕最短显示时长
+	minCueDuration = 800 * time.Millisecond
+	// fallbackCueDuration 缺少句子开始时间时的默认显示时长
+	fallbackCueDuration = 3 * time.Second
+)
+
+// ExportCaptions 将活动归档字幕导出为字幕文件
+// language 为空时导出原文，时间轴以活动首个音频块为零点
+func (s *TranscriptService) ExportCaptions(w io.Writer, activityID, language string, format caption.Format) error {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return err
+	}
+	if language == "" {
+		language = activity.InputLanguage
+	}
+	if !supportsLanguage(activity, language) {
+		return fmt.Errorf("%w: %s", domain.ErrUnsupportedLanguage, language)
+	}
+
+	ctx := context.Background()
+	subtitles, err := s.repo.ListSubtitles(ctx, activityID, 0, 0)
+	if err != nil {
+		return err
+	}
+	audioStartedAt, err := s.repo.GetAudioStartedAt(ctx, activityID)
+	if err != nil {
+		return err
+	}
+
+	var base time.Time
+	if audioStartedAt != nil {
+		base = *audioStartedAt
+	}
+	return caption.Write(w, format, language, buildCaptionCues(subtitles, language, base))
+}
+
+// buildCaptionCues 计算字幕时间轴
+// base 为零值或晚于首句开始时，以首句开始时间为零点；相邻字幕不重叠
+func buildCaptionCues(subtitles []*domain.Subtitle, language string, base time.Time) []caption.Cue {
+	cues := make([]caption.Cue, 0, len(subtitles))
+	if len(subtitles) == 0 {
+		return cues
+	}
+
+	if first := cueStartTime(subtitles[0]); base.IsZero() || first.Before(base) {
+		base = first
+	}
+
+	var prevEnd time.Duration
+	for _, subtitle := range subtitles {
+		payload, ok := subtitle.PayloadFor(language)
+		if !ok {
+			continue
+		}
+
+		start := cueStartTime(subtitle).Sub(base)
+		end := subtitle.Timestamp.Sub(base)
+		if start < prevEnd {
+			start = prevEnd
+		}
+		if end < start+minCueDuration {
+			end = start + minCueDuration
+		}
+		prevEnd = end
+
+		cues = append(cues, caption.Cue{
+			Start: start,
+			End:   end,
+			Text:  payload.Text,
+		})
+	}
+	return cues
+}
+
+func cueStartTime(subtitle *domain.Subtitle) time.Time {
+	if subtitle.StartedAt.IsZero() || subtitle.StartedAt.After(subtitle.Timestamp) {
+		return subtitle.Timestamp.Add(-fallbackCueDuration)
+	}
+	return subtitle.StartedAt
+}
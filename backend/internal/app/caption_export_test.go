@@ -0,0 +1,53 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+func TestBuildCaptionCues_RelativeToFirstAudio(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	subtitles := []*domain.Subtitle{
+		{
+			ID:           "s1",
+			SourceLang:   "zh-CN",
+			Original:     "大家好",
+			Translations: map[string]string{"en": "Hello"},
+			StartedAt:    base.Add(2 * time.Second),
+			Timestamp:    base.Add(4 * time.Second),
+		},
+		{
+			// 开始时间早于上一句结束，应顺延
+			ID:           "s2",
+			SourceLang:   "zh-CN",
+			Original:     "欢迎",
+			Translations: map[string]string{"en": "Welcome"},
+			StartedAt:    base.Add(3 * time.Second),
+			Timestamp:    base.Add(4200 * time.Millisecond),
+		},
+		{
+			ID:         "s3",
+			SourceLang: "zh-CN",
+			Original:   "未翻译",
+			Timestamp:  base.Add(10 * time.Second),
+		},
+	}
+
+	cues := buildCaptionCues(subtitles, "en", base)
+	if len(cues) != 2 {
+		t.Fatalf("expected 2 cues, got %d", len(cues))
+	}
+	if cues[0].Start != 2*time.Second || cues[0].End != 4*time.Second || cues[0].Text != "Hello" {
+		t.Fatalf("unexpected first cue: %+v", cues[0])
+	}
+	if cues[1].Start != 4*time.Second || cues[1].End != 4*time.Second+minCueDuration {
+		t.Fatalf("unexpected second cue: %+v", cues[1])
+	}
+
+	original := buildCaptionCues(subtitles, "zh-CN", time.Time{})
+	if len(original) != 3 || original[0].Start != 0 {
+		t.Fatalf("expected original cues starting at zero, got %+v", original)
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// maxPhraseHints 传给识别引擎的短语提示上限
+const maxPhraseHints = 500
+
+// glossaryPlaceholder 匹配翻译结果中的术语占位符，容忍引擎插入的空格与大小写变化
+var glossaryPlaceholder = regexp.MustCompile(`(?i)__\s*GLS\s*(\d+)\s*__`)
+
+// Glossary 编译后的活动术语表，用于识别短语提示与译文术语替换
+type Glossary struct {
+	terms   []*domain.GlossaryTerm
+	byKey   map[string]*domain.GlossaryTerm // 小写术语 -> 条目
+	pattern *regexp.Regexp
+}
+
+// NewGlossary 编译术语表，长术语优先匹配
+func NewGlossary(terms []*domain.GlossaryTerm) *Glossary {
+	g := &Glossary{byKey: make(map[string]*domain.GlossaryTerm, len(terms))}
+	for _, term := range terms {
+		source := strings.TrimSpace(term.Source)
+		if source == "" {
+			continue
+		}
+		g.terms = append(g.terms, term)
+		g.byKey[strings.ToLower(source)] = term
+	}
+	if len(g.terms) == 0 {
+		return g
+	}
+
+	sources := make([]string, 0, len(g.byKey))
+	for key := range g.byKey {
+		sources = append(sources, key)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if len(sources[i]) != len(sources[j]) {
+			return len(sources[i]) > len(sources[j])
+		}
+		return sources[i] < sources[j]
+	})
+
+	alternatives := make([]string, 0, len(sources))
+	for _, source := range sources {
+		alternative := regexp.QuoteMeta(source)
+		// 拉丁字母术语按单词边界匹配，避免 AI 命中 said
+		if isWordRune(firstRune(source)) {
+			alternative = `\b` + alternative
+		}
+		if isWordRune(lastRune(source)) {
+			alternative += `\b`
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	g.pattern = regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+	return g
+}
+
+// PhraseHints 返回识别引擎的短语提示
+func (g *Glossary) PhraseHints() []string {
+	if g == nil {
+		return nil
+	}
+	hints := make([]string, 0, len(g.terms))
+	for _, term := range g.terms {
+		if len(hints) == maxPhraseHints {
+			break
+		}
+		hints = append(hints, strings.TrimSpace(term.Source))
+	}
+	return hints
+}
+
+// Protect 将原文中有目标语言译法的术语替换为占位符，返回替换后的文本与占位符对应的译法
+func (g *Glossary) Protect(text, language string) (string, []string) {
+	if g == nil || g.pattern == nil {
+		return text, nil
+	}
+
+	var replacements []string
+	protected := g.pattern.ReplaceAllStringFunc(text, func(match string) string {
+		term := g.byKey[strings.ToLower(match)]
+		if term == nil {
+			return match
+		}
+		rendering, ok := lookupTranslation(term.Translations, language)
+		if !ok {
+			return match
+		}
+		replacements = append(replacements, rendering)
+		return fmt.Sprintf("__GLS%d__", len(replacements)-1)
+	})
+	return protected, replacements
+}
+
+// restoreGlossary 将译文中的占位符还原为术语译法
+func restoreGlossary(text string, replacements []string) string {
+	if len(replacements) == 0 {
+		return text
+	}
+	return glossaryPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		index, err := strconv.Atoi(glossaryPlaceholder.FindStringSubmatch(match)[1])
+		if err != nil || index >= len(replacements) {
+			return match
+		}
+		return replacements[index]
+	})
+}
+
+// lookupTranslation 按语言查找译法，先精确匹配再按主语言匹配
+func lookupTranslation(translations map[string]string, language string) (string, bool) {
+	for lang, text := range translations {
+		if strings.EqualFold(lang, language) {
+			return text, true
+		}
+	}
+	base, _, _ := strings.Cut(language, "-")
+	for lang, text := range translations {
+		if strings.EqualFold(lang, base) {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+func lastRune(s string) rune {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0
+	}
+	return runes[len(runes)-1]
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// GlossaryRepository 定义术语表的持久化接口
+type GlossaryRepository interface {
+	CreateTerm(ctx context.Context, term *domain.GlossaryTerm) error
+	UpdateTerm(ctx context.Context, term *domain.GlossaryTerm) error
+	DeleteTerm(ctx context.Context, activityID, id string) error
+	FindTerm(ctx context.Context, activityID, id string) (*domain.GlossaryTerm, error)
+	ListTerms(ctx context.Context, activityID string) ([]*domain.GlossaryTerm, error)
+}
+
+// GlossaryService 负责活动术语表管理
+type GlossaryService struct {
+	activityRepo domain.ActivityRepository
+	repo         GlossaryRepository
+}
+
+// NewGlossaryService 创建术语表服务
+func NewGlossaryService(activityRepo domain.ActivityRepository, repo GlossaryRepository) *GlossaryService {
+	return &GlossaryService{
+		activityRepo: activityRepo,
+		repo:         repo,
+	}
+}
+
+// ListTerms 列出活动术语
+func (s *GlossaryService) ListTerms(activityID string) ([]*domain.GlossaryTerm, error) {
+	if _, err := s.activityRepo.FindByID(activityID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTerms(context.Background(), activityID)
+}
+
+// CreateTerm 新增术语
+func (s *GlossaryService) CreateTerm(activityID string, req *domain.CreateGlossaryTermRequest) (*domain.GlossaryTerm, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		return nil, fmt.Errorf("术语不能为空")
+	}
+	translations, err := normalizeGlossaryTranslations(activity, req.Translations)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	term := &domain.GlossaryTerm{
+		ID:           uuid.New().String(),
+		ActivityID:   activityID,
+		Source:       source,
+		Translations: translations,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.CreateTerm(context.Background(), term); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// UpdateTerm 更新术语
+func (s *GlossaryService) UpdateTerm(activityID, termID string, req *domain.UpdateGlossaryTermRequest) (*domain.GlossaryTerm, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	term, err := s.repo.FindTerm(ctx, activityID, termID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Source != nil {
+		source := strings.TrimSpace(*req.Source)
+		if source == "" {
+			return nil, fmt.Errorf("术语不能为空")
+		}
+		term.Source = source
+	}
+	if req.Translations != nil {
+		translations, err := normalizeGlossaryTranslations(activity, req.Translations)
+		if err != nil {
+			return nil, err
+		}
+		term.Translations = translations
+	}
+	term.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateTerm(ctx, term); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// DeleteTerm 删除术语
+func (s *GlossaryService) DeleteTerm(activityID, termID string) error {
+	if _, err := s.activityRepo.FindByID(activityID); err != nil {
+		return err
+	}
+	return s.repo.DeleteTerm(context.Background(), activityID, termID)
+}
+
+// normalizeGlossaryTranslations 校验译法语言并去除空白译法
+func normalizeGlossaryTranslations(activity *domain.Activity, translations map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(translations))
+	for language, text := range translations {
+		language = strings.TrimSpace(language)
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if !supportsLanguage(activity, language) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedLanguage, language)
+		}
+		result[language] = text
+	}
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+// memoryGlossaryRepository 内存术语表，同一活动内术语不区分大小写唯一
+type memoryGlossaryRepository struct {
+	mu    sync.Mutex
+	terms map[string]*domain.GlossaryTerm
+}
+
+func newMemoryGlossaryRepository() *memoryGlossaryRepository {
+	return &memoryGlossaryRepository{terms: make(map[string]*domain.GlossaryTerm)}
+}
+
+func (r *memoryGlossaryRepository) CreateTerm(_ context.Context, term *domain.GlossaryTerm) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.terms {
+		if existing.ActivityID == term.ActivityID && strings.EqualFold(existing.Source, term.Source) {
+			return domain.ErrGlossaryTermExists
+		}
+	}
+	stored := *term
+	r.terms[term.ID] = &stored
+	return nil
+}
+
+func (r *memoryGlossaryRepository) UpdateTerm(_ context.Context, term *domain.GlossaryTerm) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.terms[term.ID]; !ok {
+		return domain.ErrGlossaryTermNotFound
+	}
+	stored := *term
+	r.terms[term.ID] = &stored
+	return nil
+}
+
+func (r *memoryGlossaryRepository) DeleteTerm(_ context.Context, activityID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if term, ok := r.terms[id]; !ok || term.ActivityID != activityID {
+		return domain.ErrGlossaryTermNotFound
+	}
+	delete(r.terms, id)
+	return nil
+}
+
+func (r *memoryGlossaryRepository) FindTerm(_ context.Context, activityID, id string) (*domain.GlossaryTerm, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	term, ok := r.terms[id]
+	if !ok || term.ActivityID != activityID {
+		return nil, domain.ErrGlossaryTermNotFound
+	}
+	found := *term
+	return &found, nil
+}
+
+func (r *memoryGlossaryRepository) ListTerms(_ context.Context, activityID string) ([]*domain.GlossaryTerm, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*domain.GlossaryTerm
+	for _, term := range r.terms {
+		if term.ActivityID == activityID {
+			found := *term
+			result = append(result, &found)
+		}
+	}
+	return result, nil
+}
+
+func newTestGlossaryService(t *testing.T) (*GlossaryService, *domain.Activity) {
+	t.Helper()
+	activityRepo := repository.NewMemoryActivityRepository()
+	activity := &domain.Activity{ID: "act-glossary", InputLanguage: "zh-CN", TargetLanguages: []string{"en", "ja"}}
+	if err := activityRepo.Create(activity); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return NewGlossaryService(activityRepo, newMemoryGlossaryRepository()), activity
+}
+
+func TestGlossaryService_CreateTerm(t *testing.T) {
+	service, activity := newTestGlossaryService(t)
+
+	term, err := service.CreateTerm(activity.ID, &domain.CreateGlossaryTermRequest{
+		Source:       "  奥瑞恩 ",
+		Translations: map[string]string{"en": " Orion ", "ja": "  "},
+	})
+	if err != nil {
+		t.Fatalf("CreateTerm() error = %v", err)
+	}
+	if term.Source != "奥瑞恩" || len(term.Translations) != 1 || term.Translations["en"] != "Orion" {
+		t.Fatalf("term should be trimmed and drop empty translations, got %+v", term)
+	}
+
+	if _, err := service.CreateTerm(activity.ID, &domain.CreateGlossaryTermRequest{Source: "奥瑞恩"}); !errors.Is(err, domain.ErrGlossaryTermExists) {
+		t.Fatalf("duplicate term error = %v, want ErrGlossaryTermExists", err)
+	}
+	if _, err := service.CreateTerm(activity.ID, &domain.CreateGlossaryTermRequest{Source: " "}); err == nil {
+		t.Fatal("empty source should be rejected")
+	}
+	if _, err := service.CreateTerm(activity.ID, &domain.CreateGlossaryTermRequest{
+		Source:       "猎户座",
+		Translations: map[string]string{"fr": "Orion"},
+	}); !errors.Is(err, domain.ErrUnsupportedLanguage) {
+		t.Fatalf("unsupported language error = %v, want ErrUnsupportedLanguage", err)
+	}
+	if _, err := service.CreateTerm("missing", &domain.CreateGlossaryTermRequest{Source: "猎户座"}); !errors.Is(err, domain.ErrActivityNotFound) {
+		t.Fatalf("missing activity error = %v, want ErrActivityNotFound", err)
+	}
+
+	terms, err := service.ListTerms(activity.ID)
+	if err != nil || len(terms) != 1 {
+		t.Fatalf("ListTerms() = %v, %v; want 1 term", terms, err)
+	}
+}
+
+func TestGlossaryService_UpdateAndDeleteTerm(t *testing.T) {
+	service, activity := newTestGlossaryService(t)
+	term, err := service.CreateTerm(activity.ID, &domain.CreateGlossaryTermRequest{
+		Source:       "奥瑞恩",
+		Translations: map[string]string{"en": "Orion"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTerm() error = %v", err)
+	}
+
+	// 只传 translations 时保留术语原文，译法整体替换
+	updated, err := service.UpdateTerm(activity.ID, term.ID, &domain.UpdateGlossaryTermRequest{
+		Translations: map[string]string{"ja": "オリオン"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTerm() error = %v", err)
+	}
+	if updated.Source != "奥瑞恩" || len(updated.Translations) != 1 || updated.Translations["ja"] != "オリオン" {
+		t.Fatalf("updated term = %+v", updated)
+	}
+
+	empty := " "
+	if _, err := service.UpdateTerm(activity.ID, term.ID, &domain.UpdateGlossaryTermRequest{Source: &empty}); err == nil {
+		t.Fatal("empty source should be rejected")
+	}
+	if _, err := service.UpdateTerm(activity.ID, "missing", &domain.UpdateGlossaryTermRequest{}); !errors.Is(err, domain.ErrGlossaryTermNotFound) {
+		t.Fatalf("missing term error = %v, want ErrGlossaryTermNotFound", err)
+	}
+
+	if err := service.DeleteTerm(activity.ID, term.ID); err != nil {
+		t.Fatalf("DeleteTerm() error = %v", err)
+	}
+	if err := service.DeleteTerm(activity.ID, term.ID); !errors.Is(err, domain.ErrGlossaryTermNotFound) {
+		t.Fatalf("second DeleteTerm() error = %v, want ErrGlossaryTermNotFound", err)
+	}
+}
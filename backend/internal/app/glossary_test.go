@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/google"
+)
+
+type stubGlossaryRepository struct {
+	terms []*domain.GlossaryTerm
+}
+
+func (r *stubGlossaryRepository) CreateTerm(ctx context.Context, term *domain.GlossaryTerm) error {
+	return nil
+}
+
+func (r *stubGlossaryRepository) UpdateTerm(ctx context.Context, term *domain.GlossaryTerm) error {
+	return nil
+}
+
+func (r *stubGlossaryRepository) DeleteTerm(ctx context.Context, activityID, id string) error {
+	return nil
+}
+
+func (r *stubGlossaryRepository) FindTerm(ctx context.Context, activityID, id string) (*domain.GlossaryTerm, error) {
+	return nil, domain.ErrGlossaryTermNotFound
+}
+
+func (r *stubGlossaryRepository) ListTerms(ctx context.Context, activityID string) ([]*domain.GlossaryTerm, error) {
+	return r.terms, nil
+}
+
+func TestGlossaryProtect(t *testing.T) {
+	glossary := NewGlossary([]*domain.GlossaryTerm{
+		{Source: "AI", Translations: map[string]string{"zh-CN": "人工智能"}},
+		{Source: "Orion Cloud", Translations: map[string]string{"zh": "猎户云"}},
+		{Source: "Orion", Translations: map[string]string{"zh-CN": "猎户座"}},
+	})
+
+	protected, replacements := glossary.Protect("She said Orion Cloud uses ai and Orion.", "zh-CN")
+	if protected != "She said __GLS0__ uses __GLS1__ and __GLS2__." {
+		t.Fatalf("protected = %q", protected)
+	}
+	want := []string{"猎户云", "人工智能", "猎户座"}
+	for i := range want {
+		if replacements[i] != want[i] {
+			t.Fatalf("replacements = %v, want %v", replacements, want)
+		}
+	}
+
+	// 引擎可能改变占位符的大小写或插入空格
+	if got := restoreGlossary("她说 __gls0__ 使用 __ GLS1 __ 和 __GLS2__。", replacements); got != "她说 猎户云 使用 人工智能 和 猎户座。" {
+		t.Fatalf("restoreGlossary() = %q", got)
+	}
+
+	// 没有该语言译法的术语保持原文交给翻译引擎
+	if protected, replacements := glossary.Protect("Orion", "ja"); protected != "Orion" || len(replacements) != 0 {
+		t.Fatalf("unexpected protection for ja: %q %v", protected, replacements)
+	}
+
+	if hints := glossary.PhraseHints(); len(hints) != 3 {
+		t.Fatalf("PhraseHints() = %v", hints)
+	}
+}
+
+func TestPipelineTranslateAppliesGlossary(t *testing.T) {
+	pipeline := &TranslationPipeline{
+		translationClient: google.NewMockTranslationClient(),
+		glossary: &stubGlossaryRepository{terms: []*domain.GlossaryTerm{
+			{Source: "奥瑞恩", Translations: map[string]string{"en-US": "Orion"}},
+		}},
+	}
+	session := &PipelineSession{
+		ActivityID:      "activity-1",
+		SourceLanguage:  "zh-CN",
+		TargetLanguages: []string{"en-US", "ja-JP"},
+		ctx:             context.Background(),
+	}
+
+	translations, err := pipeline.translate(session, "欢迎使用奥瑞恩")
+	if err != nil {
+		t.Fatalf("translate() error = %v", err)
+	}
+	if got := translations["en-US"]; got != "[EN-US] 欢迎使用Orion" {
+		t.Fatalf("en-US = %q", got)
+	}
+	if got := translations["ja-JP"]; got != "[JA-JP] 欢迎使用奥瑞恩" {
+		t.Fatalf("ja-JP = %q", got)
+	}
+}
+
+// blockingGlossaryRepository 在 release 关闭前阻塞 ListTerms
+type blockingGlossaryRepository struct {
+	stubGlossaryRepository
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingGlossaryRepository) ListTerms(ctx context.Context, activityID string) ([]*domain.GlossaryTerm, error) {
+	r.entered <- struct{}{}
+	<-r.release
+	return r.terms, nil
+}
+
+func TestPipelineSessionGlossary_LoadsWithoutLock(t *testing.T) {
+	repo := &blockingGlossaryRepository{
+		stubGlossaryRepository: stubGlossaryRepository{terms: []*domain.GlossaryTerm{{Source: "奥瑞恩"}}},
+		entered:                make(chan struct{}, 1),
+		release:                make(chan struct{}),
+	}
+	pipeline := &TranslationPipeline{glossary: repo}
+	session := &PipelineSession{ActivityID: "activity-1", ctx: context.Background()}
+
+	loaded := make(chan *Glossary, 1)
+	go func() { loaded <- pipeline.sessionGlossary(session) }()
+	<-repo.entered
+
+	// 加载期间其他调用方不等待数据库，直接使用旧术语表（首次加载前为 nil）
+	done := make(chan *Glossary, 1)
+	go func() { done <- pipeline.sessionGlossary(session) }()
+	select {
+	case glossary := <-done:
+		if glossary != nil {
+			t.Fatalf("concurrent caller should get the previous glossary, got %v", glossary.PhraseHints())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("concurrent caller blocked on glossary load")
+	}
+
+	close(repo.release)
+	if hints := (<-loaded).PhraseHints(); len(hints) != 1 {
+		t.Fatalf("loaded glossary hints = %v", hints)
+	}
+	// 刷新间隔内不再查询
+	if hints := pipeline.sessionGlossary(session).PhraseHints(); len(hints) != 1 {
+		t.Fatalf("cached glossary hints = %v", hints)
+	}
+	select {
+	case <-repo.entered:
+		t.Fatal("glossary should not reload within refresh interval")
+	default:
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// OrganizationRepository 定义组织的持久化接口
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *domain.Organization) error
+	Update(ctx context.Context, org *domain.Organization) error
+	FindByID(ctx context.Context, id string) (*domain.Organization, error)
+	List(ctx context.Context) ([]*domain.Organization, error)
+}
+
+// OrganizationService 负责组织（租户）管理，仅超级管理员可用
+type OrganizationService struct {
+	repo OrganizationRepository
+}
+
+// NewOrganizationService 创建组织服务
+func NewOrganizationService(repo OrganizationRepository) *OrganizationService {
+	return &OrganizationService{repo: repo}
+}
+
+// ListOrganizations 列出全部组织
+func (s *OrganizationService) ListOrganizations() ([]*domain.Organization, error) {
+	return s.repo.List(context.Background())
+}
+
+// GetOrganization 获取组织
+func (s *OrganizationService) GetOrganization(id string) (*domain.Organization, error) {
+	return s.repo.FindByID(context.Background(), id)
+}
+
+// CreateOrganization 创建组织
+func (s *OrganizationService) CreateOrganization(req *domain.CreateOrganizationRequest) (*domain.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("组织名称不能为空")
+	}
+
+	now := time.Now()
+	org := &domain.Organization{
+		ID:        uuid.New().String(),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(context.Background(), org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// UpdateOrganization 更新组织名称
+func (s *OrganizationService) UpdateOrganization(id string, req *domain.UpdateOrganizationRequest) (*domain.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("组织名称不能为空")
+	}
+
+	ctx := context.Background()
+	org, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	org.Name = name
+	org.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
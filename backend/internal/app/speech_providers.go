@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/google"
+	"github.com/hoshea/orion-backend/internal/infra/stt"
+)
+
+// DefaultSpeechProviders 返回内置语音识别引擎注册表
+// vosk 与 whispercpp 通过本地进程离线识别，音频不出内网
+func DefaultSpeechProviders() *stt.Registry {
+	registry := stt.NewRegistry()
+	registry.Register("google", func(ctx context.Context, cfg *config.Config) (stt.Client, error) {
+		if cfg.Google.STTAPIKey == "" {
+			return nil, errors.New("GOOGLE_STT_API_KEY 未配置")
+		}
+		return google.NewSTTClient(ctx, cfg.Google.STTAPIKey)
+	})
+	registry.Register("mock", func(ctx context.Context, cfg *config.Config) (stt.Client, error) {
+		return google.NewMockSTTClient(), nil
+	})
+	registry.Register("vosk", func(ctx context.Context, cfg *config.Config) (stt.Client, error) {
+		return stt.NewVoskClient(cfg.Speech.VoskServerURL)
+	})
+	registry.Register("whispercpp", func(ctx context.Context, cfg *config.Config) (stt.Client, error) {
+		return stt.NewWhisperCppClient(cfg.Speech.WhisperCppServerURL, cfg.Speech.WhisperCppSegment)
+	})
+	return registry
+}
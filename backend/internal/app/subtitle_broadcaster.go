@@ -1,193 +1,591 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
+)
+
+const (
+	// broadcastTimeout 发布、订阅广播后端的超时时间
+	broadcastTimeout = 5 * time.Second
+	// broadcastEventClosed 演讲者断开事件，各实例收到后断开本地观众
+	broadcastEventClosed domain.MessageType = "CLOSED"
+	// broadcastEventEnded 活动已关闭事件，各实例通知本地观众 ENDED 后断开，并结束本地演讲者连接
+	broadcastEventEnded domain.MessageType = "ENDED"
+	// defaultViewerLagWindow 观众发送队列持续积压过半超过该时长即被断开
+	defaultViewerLagWindow = 15 * time.Second
+	// defaultIdleRelease 没有演讲者注册的活动在最后一位观众离开后保留订阅的时长
+	defaultIdleRelease = 30 * time.Second
 )
 
 // SubtitleBroadcaster 字幕广播服务
-// 负责将字幕分发给订阅了特定语言的观众
+// 负责将字幕分发给订阅了特定语言的观众。字幕经广播后端（内存或 Redis）发布，
+// 每个实例只维护本实例的观众，并订阅有本地观众或演讲者的活动
 type SubtitleBroadcaster struct {
 	mu          sync.RWMutex
 	activities  map[string]*ActivityBroadcast // activityID -> broadcast
+	backend     broadcast.Backend
+	history     *SubtitleHistory
+	lagWindow   time.Duration // 慢速观众的积压容忍时长，<= 0 时不断开
+	idleRelease time.Duration // 空闲活动保留订阅的时长，<= 0 时立即释放
+
+	dropped atomic.Uint64 // 未送达观众的消息总数
+	evicted atomic.Uint64 // 因积压被断开的观众总数
 }
 
-// ActivityBroadcast 单个活动的广播器
+// ActivityBroadcast 单个活动在本实例的广播器
 type ActivityBroadcast struct {
-	ActivityID string
-	mu         sync.RWMutex
-	viewers    map[string]*ViewerConnection // viewerID -> connection
+	ActivityID  string
+	mu          sync.RWMutex
+	viewers     map[string]*ViewerConnection // viewerID -> connection
+	unsubscribe func()
+	ended       chan struct{}        // 活动关闭时关闭
+	state       *domain.StatePayload // 最近一次演讲状态，新加入的观众会先收到
+	registered  bool                 // 本实例有演讲者注册，观众全部离开时也保留订阅
+	detached    bool                 // 已从本实例移除，加入的观众需重新获取广播器
+	delivered   uint64               // 已投递的最终字幕数，回放历史时据此判断读取期间是否有新字幕
+	idleTimer   *time.Timer          // 最后一位观众离开后的延迟释放
+	idleGen     uint64               // 延迟释放的代数，观众重新加入或再次空闲时递增，使旧的释放失效
 }
 
-// ViewerConnection 观众连接
-type ViewerConnection struct {
-	ID           string
-	Language     string                      // 订阅的语言
-	SendChannel  chan *domain.SubtitlePayload // 发送字幕的 channel
+// broadcastEvent 经广播后端在实例间传递的事件
+type broadcastEvent struct {
+	Type     domain.MessageType   `json:"type"`
+	Subtitle *domain.Subtitle     `json:"subtitle,omitempty"`
+	State    *domain.StatePayload `json:"state,omitempty"`
 }
 
 // NewSubtitleBroadcaster 创建字幕广播服务
-func NewSubtitleBroadcaster() *SubtitleBroadcaster {
+// backend 为 nil 时使用进程内广播；history 非 nil 时发布的最终字幕由发布实例写入历史缓存。
+// 多实例部署时 history 需使用共享存储（SetStore），否则只有发布实例能回放
+func NewSubtitleBroadcaster(backend broadcast.Backend, history *SubtitleHistory) *SubtitleBroadcaster {
+	if backend == nil {
+		backend = broadcast.NewMemoryBackend()
+	}
 	return &SubtitleBroadcaster{
-		activities: make(map[string]*ActivityBroadcast),
+		activities:  make(map[string]*ActivityBroadcast),
+		backend:     backend,
+		history:     history,
+		lagWindow:   defaultViewerLagWindow,
+		idleRelease: defaultIdleRelease,
 	}
 }
 
-// RegisterActivity 注册活动
+// SetLagWindow 设置慢速观众的积压容忍时长，需在添加观众前调用
+func (b *SubtitleBroadcaster) SetLagWindow(window time.Duration) {
+	b.lagWindow = window
+}
+
+// SetIdleRelease 设置空闲活动保留订阅的时长，长轮询观众在此期间再次请求时复用订阅
+func (b *SubtitleBroadcaster) SetIdleRelease(delay time.Duration) {
+	b.idleRelease = delay
+}
+
+// DroppedMessages 返回未送达观众的消息总数（发送队列已满被丢弃）
+func (b *SubtitleBroadcaster) DroppedMessages() uint64 {
+	return b.dropped.Load()
+}
+
+// EvictedViewers 返回因长时间积压被断开的观众总数
+func (b *SubtitleBroadcaster) EvictedViewers() uint64 {
+	return b.evicted.Load()
+}
+
+// RegisterActivity 注册活动并订阅广播
 func (b *SubtitleBroadcaster) RegisterActivity(activityID string) {
+	activity, err := b.ensureActivity(activityID)
+	if err != nil {
+		log.Printf("Warning: failed to register activity %s for broadcast: %v", activityID, err)
+		return
+	}
+	activity.mu.Lock()
+	activity.registered = true
+	activity.cancelIdleRelease()
+	activity.mu.Unlock()
+}
+
+// ensureActivity 获取本实例的活动广播器，不存在时创建并订阅广播后端
+func (b *SubtitleBroadcaster) ensureActivity(activityID string) (*ActivityBroadcast, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if _, exists := b.activities[activityID]; !exists {
-		b.activities[activityID] = &ActivityBroadcast{
-			ActivityID: activityID,
-			viewers:    make(map[string]*ViewerConnection),
-		}
-		log.Printf("Registered activity for broadcast: %s", activityID)
+	if activity, exists := b.activities[activityID]; exists {
+		return activity, nil
 	}
+
+	activity := &ActivityBroadcast{
+		ActivityID: activityID,
+		viewers:    make(map[string]*ViewerConnection),
+		ended:      make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), broadcastTimeout)
+	defer cancel()
+	unsubscribe, err := b.backend.Subscribe(ctx, activityID, func(payload []byte) {
+		b.handleEvent(activity, payload)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe broadcast: %w", err)
+	}
+	activity.unsubscribe = unsubscribe
+
+	b.activities[activityID] = activity
+	log.Printf("Registered activity for broadcast: %s", activityID)
+	return activity, nil
 }
 
-// UnregisterActivity 注销活动
+// UnregisterActivity 注销活动，通知所有实例断开该活动的观众
 func (b *SubtitleBroadcaster) UnregisterActivity(activityID string) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	if broadcast, exists := b.activities[activityID]; exists {
-		// 关闭所有观众连接
-		broadcast.mu.Lock()
-		for _, viewer := range broadcast.viewers {
-			close(viewer.SendChannel)
-		}
-		broadcast.mu.Unlock()
+	if err := b.publish(activityID, broadcastEvent{Type: broadcastEventClosed}); err != nil {
+		log.Printf("Warning: failed to publish close event for activity %s: %v", activityID, err)
+	}
 
-		delete(b.activities, activityID)
-		log.Printf("Unregistered activity from broadcast: %s", activityID)
+	// 广播后端不可用或本实例未收到事件时，保证本地观众被断开
+	b.mu.RLock()
+	activity := b.activities[activityID]
+	b.mu.RUnlock()
+	if activity != nil {
+		b.closeActivity(activity)
 	}
 }
 
-// AddViewer 添加观众
-func (b *SubtitleBroadcaster) AddViewer(activityID, viewerID, language string) (*ViewerConnection, error) {
+// EndActivity 活动关闭时通知所有实例：观众收到 STATE ENDED 后断开，演讲者连接随之结束
+func (b *SubtitleBroadcaster) EndActivity(activityID string) {
+	if err := b.publish(activityID, broadcastEvent{Type: broadcastEventEnded}); err != nil {
+		log.Printf("Warning: failed to publish end event for activity %s: %v", activityID, err)
+	}
+
+	// 广播后端不可用或本实例未收到事件时，保证本地连接被结束
 	b.mu.RLock()
-	broadcast, exists := b.activities[activityID]
+	activity := b.activities[activityID]
 	b.mu.RUnlock()
+	if activity != nil {
+		b.endActivity(activity)
+	}
+}
 
-	if !exists {
-		// 自动注册活动
-		b.RegisterActivity(activityID)
-		b.mu.RLock()
-		broadcast = b.activities[activityID]
-		b.mu.RUnlock()
+// Ended 返回活动在本实例结束（活动被关闭）时关闭的 channel，注册失败时返回 nil
+func (b *SubtitleBroadcaster) Ended(activityID string) <-chan struct{} {
+	activity, err := b.ensureActivity(activityID)
+	if err != nil {
+		log.Printf("Warning: failed to watch activity %s: %v", activityID, err)
+		return nil
 	}
+	return activity.ended
+}
 
-	viewer := &ViewerConnection{
-		ID:          viewerID,
-		Language:    language,
-		SendChannel: make(chan *domain.SubtitlePayload, 100), // 缓冲 100 条字幕
+// endActivity 向本地观众发送 ENDED 状态后关闭活动广播器
+func (b *SubtitleBroadcaster) endActivity(activity *ActivityBroadcast) {
+	if !b.detach(activity) {
+		return
+	}
+	b.teardown(activity, &domain.WebSocketMessage{
+		Type:    domain.MessageTypeState,
+		Payload: domain.StatePayload{Status: "ENDED", Message: "活动已结束"},
+	})
+	close(activity.ended)
+}
+
+// closeActivity 关闭本实例的活动广播器及其观众连接
+func (b *SubtitleBroadcaster) closeActivity(activity *ActivityBroadcast) {
+	if b.detach(activity) {
+		b.teardown(activity, nil)
+	}
+}
+
+// detach 从本实例移除活动广播器，返回是否由本次调用移除
+func (b *SubtitleBroadcaster) detach(activity *ActivityBroadcast) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// 活动可能已被重新注册，只关闭事件对应的那一个
+	if b.activities[activity.ActivityID] != activity {
+		return false
 	}
+	delete(b.activities, activity.ActivityID)
+	activity.mu.Lock()
+	activity.detached = true
+	activity.cancelIdleRelease()
+	activity.mu.Unlock()
+	return true
+}
 
-	broadcast.mu.Lock()
-	broadcast.viewers[viewerID] = viewer
-	broadcast.mu.Unlock()
+// teardown 取消订阅并断开观众，final 非空时作为最后一条消息发送给每位观众
+func (b *SubtitleBroadcaster) teardown(activity *ActivityBroadcast, final *domain.WebSocketMessage) {
+	activity.unsubscribe()
 
-	log.Printf("Added viewer %s to activity %s (language: %s)", viewerID, activityID, language)
+	activity.mu.Lock()
+	for id, viewer := range activity.viewers {
+		if final != nil {
+			viewer.sendPriority(final)
+		}
+		close(viewer.SendChannel)
+		delete(activity.viewers, id)
+	}
+	activity.mu.Unlock()
+
+	log.Printf("Unregistered activity from broadcast: %s", activity.ActivityID)
+}
+
+// AddViewer 添加观众，可同时订阅多个语言
+func (b *SubtitleBroadcaster) AddViewer(activityID, viewerID string, languages ...string) (*ViewerConnection, error) {
+	// 观众可能先于演讲者加入，或落在与演讲者不同的实例上，自动注册活动
+	var activity *ActivityBroadcast
+	for {
+		var err error
+		if activity, err = b.ensureActivity(activityID); err != nil {
+			return nil, err
+		}
+		activity.mu.Lock()
+		if !activity.detached {
+			break
+		}
+		// 最后一位观众刚离开，广播器已被释放
+		activity.mu.Unlock()
+	}
+
+	activity.cancelIdleRelease()
+	viewer := newViewerConnection(viewerID, languages, &b.dropped)
+	activity.viewers[viewerID] = viewer
+	if activity.state != nil {
+		viewer.sendPriority(&domain.WebSocketMessage{Type: domain.MessageTypeState, Payload: *activity.state})
+	}
+	activity.mu.Unlock()
+
+	log.Printf("Added viewer %s to activity %s (languages: %s)", viewerID, activityID, strings.Join(languages, ","))
 	return viewer, nil
 }
 
 // RemoveViewer 移除观众
+// 本实例没有演讲者注册该活动时，最后一位观众离开 idleRelease 后取消订阅，
+// 避免长轮询等短连接遗留订阅，又不必每次请求都重新订阅
 func (b *SubtitleBroadcaster) RemoveViewer(activityID, viewerID string) {
 	b.mu.RLock()
-	broadcast, exists := b.activities[activityID]
+	activity, exists := b.activities[activityID]
 	b.mu.RUnlock()
-
 	if !exists {
 		return
 	}
 
-	broadcast.mu.Lock()
-	if viewer, found := broadcast.viewers[viewerID]; found {
+	activity.mu.Lock()
+	if viewer, found := activity.viewers[viewerID]; found {
 		close(viewer.SendChannel)
-		delete(broadcast.viewers, viewerID)
+		delete(activity.viewers, viewerID)
 		log.Printf("Removed viewer %s from activity %s", viewerID, activityID)
 	}
-	broadcast.mu.Unlock()
+	idle := len(activity.viewers) == 0 && !activity.registered && !activity.detached
+	var gen uint64
+	if idle {
+		activity.cancelIdleRelease()
+		gen = activity.idleGen
+		if b.idleRelease > 0 {
+			activity.idleTimer = time.AfterFunc(b.idleRelease, func() { b.releaseIdle(activity, gen) })
+		}
+	}
+	activity.mu.Unlock()
+
+	if idle && b.idleRelease <= 0 {
+		b.releaseIdle(activity, gen)
+	}
+}
+
+// releaseIdle 释放仍然空闲的活动广播器并取消订阅，gen 已失效时不做处理
+func (b *SubtitleBroadcaster) releaseIdle(activity *ActivityBroadcast, gen uint64) {
+	b.mu.Lock()
+	activity.mu.Lock()
+	idle := b.activities[activity.ActivityID] == activity && activity.idleGen == gen &&
+		len(activity.viewers) == 0 && !activity.registered
+	if idle {
+		delete(b.activities, activity.ActivityID)
+		activity.detached = true
+		activity.idleTimer = nil
+	}
+	activity.mu.Unlock()
+	b.mu.Unlock()
+
+	if idle {
+		activity.unsubscribe()
+		log.Printf("Released idle activity from broadcast: %s", activity.ActivityID)
+	}
+}
+
+// cancelIdleRelease 取消待执行的延迟释放，调用方需持有活动锁
+func (a *ActivityBroadcast) cancelIdleRelease() {
+	a.idleGen++
+	if a.idleTimer != nil {
+		a.idleTimer.Stop()
+		a.idleTimer = nil
+	}
+}
+
+// SwitchViewerLanguages 切换观众订阅的语言，并经发送队列回放新语言最近 historyLimit 条历史字幕
+// 在活动锁内完成切换与回放，之后的字幕都按新语言投递
+func (b *SubtitleBroadcaster) SwitchViewerLanguages(activityID, viewerID string, languages []string, historyLimit int) error {
+	if len(languages) == 0 {
+		return fmt.Errorf("观众订阅语言不能为空")
+	}
+	err := b.replayHistory(activityID, viewerID, languages, "", historyLimit)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Viewer %s of activity %s switched to languages %s", viewerID, activityID, strings.Join(languages, ","))
+	return nil
+}
+
+// ResendHistory 经发送队列补发句子 afterID 之后的历史字幕，用于观众连接时回放与发现序列号缺失后补发
+// afterID 为空或已不在缓存中时补发最近 historyLimit 条
+func (b *SubtitleBroadcaster) ResendHistory(activityID, viewerID, afterID string, historyLimit int) error {
+	return b.replayHistory(activityID, viewerID, nil, afterID, historyLimit)
+}
+
+// replayHistoryAttempts 锁外读取历史期间有新字幕投递时的最多重读次数，之后在锁内读取
+const replayHistoryAttempts = 3
+
+// replayHistory 经观众发送队列回放一条 HISTORY 消息，languages 非空时同时切换观众的订阅语言
+// 历史可能存放在 Redis，读取在活动锁外进行，避免网络往返阻塞其他观众的投递；
+// 读取期间有新字幕投递时重新读取，保证回放与之后的实时字幕之间不缺句
+func (b *SubtitleBroadcaster) replayHistory(activityID, viewerID string, languages []string, afterID string, historyLimit int) error {
+	b.mu.RLock()
+	activity, exists := b.activities[activityID]
+	b.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("活动未在广播中: %s", activityID)
+	}
+
+	for attempt := 1; ; attempt++ {
+		activity.mu.RLock()
+		delivered := activity.delivered
+		viewer, found := activity.viewers[viewerID]
+		replayLanguages := languages
+		if found && replayLanguages == nil {
+			replayLanguages = viewer.Languages
+		}
+		activity.mu.RUnlock()
+		if !found {
+			return fmt.Errorf("观众不存在: %s", viewerID)
+		}
+
+		history := b.historyPayload(activityID, replayLanguages, afterID, historyLimit)
+
+		activity.mu.Lock()
+		viewer, found = activity.viewers[viewerID]
+		if !found {
+			activity.mu.Unlock()
+			return fmt.Errorf("观众不存在: %s", viewerID)
+		}
+		if activity.delivered != delivered {
+			if attempt < replayHistoryAttempts {
+				activity.mu.Unlock()
+				continue
+			}
+			// 字幕持续到达，退回到锁内读取
+			history = b.historyPayload(activityID, replayLanguages, afterID, historyLimit)
+		}
+		viewer.Languages = replayLanguages
+		viewer.sendPriority(&domain.WebSocketMessage{Type: domain.MessageTypeHistory, Payload: history})
+		activity.mu.Unlock()
+		return nil
+	}
+}
+
+// historyPayload 读取观众订阅语言的历史字幕
+func (b *SubtitleBroadcaster) historyPayload(activityID string, languages []string, afterID string, historyLimit int) domain.HistoryPayload {
+	history := domain.HistoryPayload{Language: languages[0], Languages: languages, Subtitles: []domain.SubtitlePayload{}}
+	if b.history != nil {
+		history.Subtitles, _ = b.history.After(activityID, languages, afterID, historyLimit)
+	}
+	return history
 }
 
 // BroadcastSubtitle 广播字幕
 // 根据观众订阅的语言分发字幕
 func (b *SubtitleBroadcaster) BroadcastSubtitle(activityID string, subtitle *domain.Subtitle) {
-	b.mu.RLock()
-	broadcast, exists := b.activities[activityID]
-	b.mu.RUnlock()
+	// 发布前写入历史，只写一次，未订阅该活动的实例也能从共享存储读到
+	if b.history != nil {
+		b.history.Append(subtitle)
+	}
+	if err := b.publish(activityID, broadcastEvent{Type: domain.MessageTypeSubtitle, Subtitle: subtitle}); err != nil {
+		log.Printf("Warning: failed to broadcast subtitle for activity %s: %v", activityID, err)
+	}
+}
 
-	if !exists {
-		log.Printf("Warning: No broadcast found for activity %s", activityID)
+// BroadcastPartial 广播中间识别结果
+// 只发送给有对应语言文本的观众，缓冲区满时直接丢弃（后续结果会覆盖）
+func (b *SubtitleBroadcaster) BroadcastPartial(activityID string, partial *domain.Subtitle) {
+	if err := b.publish(activityID, broadcastEvent{Type: domain.MessageTypePartial, Subtitle: partial}); err != nil {
+		log.Printf("Warning: failed to broadcast partial for activity %s: %v", activityID, err)
+	}
+}
+
+// BroadcastState 通知所有观众演讲状态变化（暂停、恢复、停止）
+func (b *SubtitleBroadcaster) BroadcastState(activityID string, state domain.StatePayload) {
+	if err := b.publish(activityID, broadcastEvent{Type: domain.MessageTypeState, State: &state}); err != nil {
+		log.Printf("Warning: failed to broadcast state for activity %s: %v", activityID, err)
+	}
+}
+
+// publish 编码事件并发布到广播后端
+func (b *SubtitleBroadcaster) publish(activityID string, event broadcastEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode broadcast event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), broadcastTimeout)
+	defer cancel()
+	return b.backend.Publish(ctx, activityID, payload)
+}
+
+// handleEvent 处理广播后端投递的事件
+func (b *SubtitleBroadcaster) handleEvent(activity *ActivityBroadcast, payload []byte) {
+	var event broadcastEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		log.Printf("Warning: invalid broadcast event for activity %s: %v", activity.ActivityID, err)
 		return
 	}
 
-	broadcast.mu.RLock()
-	defer broadcast.mu.RUnlock()
+	switch event.Type {
+	case broadcastEventClosed:
+		b.closeActivity(activity)
+	case broadcastEventEnded:
+		b.endActivity(activity)
+	case domain.MessageTypeSubtitle:
+		if event.Subtitle == nil {
+			return
+		}
+		sent := b.deliver(activity, event.Type, event.Subtitle)
+		log.Printf("Broadcasted subtitle for activity %s to %d viewers", activity.ActivityID, sent)
+	case domain.MessageTypePartial:
+		if event.Subtitle != nil {
+			b.deliver(activity, event.Type, event.Subtitle)
+		}
+	case domain.MessageTypeState:
+		if event.State != nil {
+			b.deliverState(activity, event.State)
+		}
+	}
+}
+
+// deliver 按观众语言分发消息给本实例的观众，返回成功投递的观众数
+// 编号需在锁内按顺序分配，因此持有写锁
+func (b *SubtitleBroadcaster) deliver(activity *ActivityBroadcast, messageType domain.MessageType, subtitle *domain.Subtitle) int {
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
 
+	if messageType == domain.MessageTypeSubtitle {
+		activity.delivered++
+	}
+	sent := 0
 	// 遍历所有观众，发送对应语言的字幕
-	for _, viewer := range broadcast.viewers {
-		// 获取观众订阅语言的翻译
-		translatedText, ok := subtitle.Translations[viewer.Language]
+	for _, viewer := range activity.viewers {
+		// 每句只发一条，合并观众订阅的全部语言；一个语言都没有翻译时跳过
+		payload, ok := subtitle.PayloadForLanguages(viewer.Languages)
 		if !ok {
-			// 如果没有该语言的翻译，跳过
 			continue
 		}
 
-		subtitlePayload := &domain.SubtitlePayload{
-			ID:         subtitle.ID,
-			Original:   subtitle.Original,
-			SourceLang: subtitle.SourceLang,
-			TargetLang: viewer.Language,
-			Text:       translatedText,
-			Timestamp:  subtitle.Timestamp,
-			Confidence: subtitle.Confidence,
+		msg := &domain.WebSocketMessage{Type: messageType, Payload: payload}
+		if messageType != domain.MessageTypeSubtitle {
+			viewer.sendPartial(msg)
+			continue
 		}
-
-		// 非阻塞发送
-		select {
-		case viewer.SendChannel <- subtitlePayload:
-			// 发送成功
-		default:
-			// Channel 已满，跳过该观众（避免阻塞其他观众）
+		// 非阻塞发送，队列已满时跳过该观众（避免阻塞其他观众），客户端可据序列号请求补发
+		if viewer.send(msg) {
+			sent++
+		} else {
 			log.Printf("Warning: viewer %s channel is full, skipping subtitle", viewer.ID)
 		}
 	}
 
-	log.Printf("Broadcasted subtitle for activity %s to %d viewers", activityID, len(broadcast.viewers))
+	b.evictLagging(activity)
+	return sent
+}
+
+// deliverState 记录演讲状态并发送给本实例的全部观众，缓冲区满时丢弃最旧的消息以保证送达
+func (b *SubtitleBroadcaster) deliverState(activity *ActivityBroadcast, state *domain.StatePayload) {
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+
+	activity.state = state
+	msg := &domain.WebSocketMessage{Type: domain.MessageTypeState, Payload: *state}
+	for _, viewer := range activity.viewers {
+		viewer.sendPriority(msg)
+	}
+	b.evictLagging(activity)
+}
+
+// evictLagging 断开发送队列持续积压超过容忍时长的观众，通知其重新同步，调用方需持有活动写锁
+func (b *SubtitleBroadcaster) evictLagging(activity *ActivityBroadcast) {
+	if b.lagWindow <= 0 {
+		return
+	}
+	now := time.Now()
+	for id, viewer := range activity.viewers {
+		if viewer.laggingFor(now) < b.lagWindow {
+			continue
+		}
+		viewer.sendPriority(&domain.WebSocketMessage{
+			Type:    domain.MessageTypeState,
+			Payload: domain.StatePayload{Status: "RESYNC_REQUIRED", Message: "字幕接收过慢，请重新连接"},
+		})
+		close(viewer.SendChannel)
+		delete(activity.viewers, id)
+		b.evicted.Add(1)
+		log.Printf("Evicted lagging viewer %s from activity %s (dropped %d messages)", id, activity.ActivityID, viewer.Dropped())
+	}
+}
+
+// ActivityIDs 返回本实例正在广播的活动
+func (b *SubtitleBroadcaster) ActivityIDs() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ids := make([]string, 0, len(b.activities))
+	for id := range b.activities {
+		ids = append(ids, id)
+	}
+	return ids
 }
 
-// GetViewerCount 获取活动的观众数量
+// GetViewerCount 获取本实例上活动的观众数量
 func (b *SubtitleBroadcaster) GetViewerCount(activityID string) int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	if broadcast, exists := b.activities[activityID]; exists {
-		broadcast.mu.RLock()
-		defer broadcast.mu.RUnlock()
-		return len(broadcast.viewers)
+	if activity, exists := b.activities[activityID]; exists {
+		activity.mu.RLock()
+		defer activity.mu.RUnlock()
+		return len(activity.viewers)
 	}
 
 	return 0
 }
 
-// GetViewersByLanguage 按语言统计观众数量
+// GetViewersByLanguage 按语言统计本实例上的观众数量，订阅多个语言的观众计入每个语言
 func (b *SubtitleBroadcaster) GetViewersByLanguage(activityID string) map[string]int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	result := make(map[string]int)
 
-	if broadcast, exists := b.activities[activityID]; exists {
-		broadcast.mu.RLock()
-		defer broadcast.mu.RUnlock()
+	if activity, exists := b.activities[activityID]; exists {
+		activity.mu.RLock()
+		defer activity.mu.RUnlock()
 
-		for _, viewer := range broadcast.viewers {
-			result[viewer.Language]++
+		for _, viewer := range activity.viewers {
+			for _, language := range viewer.Languages {
+				result[language]++
+			}
 		}
 	}
 
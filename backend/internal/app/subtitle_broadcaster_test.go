@@ -0,0 +1,503 @@
+package app
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
+)
+
+func TestSubtitleBroadcaster_MemoryBackend(t *testing.T) {
+	backend := broadcast.NewMemoryBackend()
+	testBroadcasterAcrossInstances(t, backend, backend)
+	testBroadcasterEndActivity(t, backend, backend)
+	testBroadcasterState(t, backend, backend)
+}
+
+// TestSubtitleBroadcaster_RedisBackend 需要本地 Redis（可通过 TEST_REDIS_URL 指定），不可用时跳过
+func TestSubtitleBroadcaster_RedisBackend(t *testing.T) {
+	redisURL := os.Getenv("TEST_REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379/15"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	speakerBackend, err := broadcast.NewRedisBackend(ctx, redisURL)
+	if err != nil {
+		t.Skipf("redis not available: %v", err)
+	}
+	defer speakerBackend.Close()
+	viewerBackend, err := broadcast.NewRedisBackend(ctx, redisURL)
+	if err != nil {
+		t.Fatalf("NewRedisBackend() error = %v", err)
+	}
+	defer viewerBackend.Close()
+
+	testBroadcasterAcrossInstances(t, speakerBackend, viewerBackend)
+	testBroadcasterEndActivity(t, speakerBackend, viewerBackend)
+	testBroadcasterState(t, speakerBackend, viewerBackend)
+}
+
+// testBroadcasterAcrossInstances 演讲者与观众连接在不同实例上，字幕经广播后端送达观众
+func testBroadcasterAcrossInstances(t *testing.T, speakerBackend, viewerBackend broadcast.Backend) {
+	activityID := "act-" + time.Now().Format("150405.000000000")
+	speakerHistory := NewSubtitleHistory(time.Minute, 10)
+	viewerHistory := NewSubtitleHistory(time.Minute, 10)
+	// 未订阅该活动的实例，只能从共享存储读到历史
+	idleHistory := NewSubtitleHistory(time.Minute, 10)
+	if store, ok := speakerBackend.(broadcast.HistoryStore); ok {
+		speakerHistory.SetStore(store)
+		viewerHistory.SetStore(viewerBackend.(broadcast.HistoryStore))
+		idleHistory.SetStore(store)
+		defer store.ClearHistory(context.Background(), activityID)
+	} else {
+		// 进程内广播只有一个实例，共用一份缓存
+		viewerHistory, idleHistory = speakerHistory, speakerHistory
+	}
+	speakerInstance := NewSubtitleBroadcaster(speakerBackend, speakerHistory)
+	viewerInstance := NewSubtitleBroadcaster(viewerBackend, viewerHistory)
+
+	enViewer, err := viewerInstance.AddViewer(activityID, "viewer-en", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	jaViewer, err := viewerInstance.AddViewer(activityID, "viewer-ja", "ja")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	speakerInstance.RegisterActivity(activityID)
+
+	partial := &domain.Subtitle{ID: "s1", ActivityID: activityID, Original: "你好", SourceLang: "zh-CN", Partial: true,
+		Translations: map[string]string{"en": "Hi"}}
+	subtitle := newHistorySubtitle(activityID, 1, time.Now())
+	speakerInstance.BroadcastPartial(activityID, partial)
+	speakerInstance.BroadcastSubtitle(activityID, subtitle)
+
+	for _, want := range []domain.MessageType{domain.MessageTypePartial, domain.MessageTypeSubtitle} {
+		msg := receiveViewerMessage(t, enViewer)
+		if msg.Type != want {
+			t.Fatalf("message type = %s, want %s", msg.Type, want)
+		}
+	}
+	if len(jaViewer.SendChannel) != 0 {
+		t.Fatalf("ja viewer should not receive subtitles without ja translation")
+	}
+
+	// 历史由发布实例写入，观众在任一实例加入都能回放
+	for name, history := range map[string]*SubtitleHistory{"speaker": speakerHistory, "viewer": viewerHistory, "idle": idleHistory} {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			items := history.Recent(activityID, "en", 0)
+			if len(items) == 1 && items[0].Text == "text 1" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s history = %+v", name, items)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// 演讲者实例注销活动后，观众实例上的观众连接被关闭
+	speakerInstance.UnregisterActivity(activityID)
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-jaViewer.SendChannel:
+			if !ok {
+				if viewerInstance.GetViewerCount(activityID) != 0 {
+					t.Fatalf("viewer instance still tracks activity")
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for viewer channel to close")
+		}
+	}
+}
+
+// testBroadcasterEndActivity 任一实例关闭活动后，观众收到 ENDED 并被断开，演讲者实例收到结束通知
+func testBroadcasterEndActivity(t *testing.T, speakerBackend, viewerBackend broadcast.Backend) {
+	activityID := "act-end-" + time.Now().Format("150405.000000000")
+	speakerInstance := NewSubtitleBroadcaster(speakerBackend, nil)
+	viewerInstance := NewSubtitleBroadcaster(viewerBackend, nil)
+
+	viewer, err := viewerInstance.AddViewer(activityID, "viewer-en", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	speakerInstance.RegisterActivity(activityID)
+	ended := speakerInstance.Ended(activityID)
+
+	// 管理端请求落在第三个实例上
+	NewSubtitleBroadcaster(speakerBackend, nil).EndActivity(activityID)
+
+	msg := receiveViewerMessage(t, viewer)
+	if state, ok := msg.Payload.(domain.StatePayload); msg.Type != domain.MessageTypeState || !ok || state.Status != "ENDED" {
+		t.Fatalf("unexpected final message: %+v", msg)
+	}
+	select {
+	case _, ok := <-viewer.SendChannel:
+		if ok {
+			t.Fatal("viewer channel should be closed after ENDED")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for viewer channel to close")
+	}
+	select {
+	case <-ended:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for speaker instance to observe end")
+	}
+}
+
+// testBroadcasterState 演讲状态变化送达全部观众，之后加入的观众先收到最近的状态
+func testBroadcasterState(t *testing.T, speakerBackend, viewerBackend broadcast.Backend) {
+	activityID := "act-state-" + time.Now().Format("150405.000000000")
+	speakerInstance := NewSubtitleBroadcaster(speakerBackend, nil)
+	viewerInstance := NewSubtitleBroadcaster(viewerBackend, nil)
+
+	viewer, err := viewerInstance.AddViewer(activityID, "viewer-en", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	speakerInstance.RegisterActivity(activityID)
+	defer speakerInstance.UnregisterActivity(activityID)
+
+	speakerInstance.BroadcastState(activityID, domain.StatePayload{Status: "PAUSED"})
+	msg := receiveViewerMessage(t, viewer)
+	if state, ok := msg.Payload.(domain.StatePayload); msg.Type != domain.MessageTypeState || !ok || state.Status != "PAUSED" {
+		t.Fatalf("unexpected state message: %+v", msg)
+	}
+
+	// 之后加入的观众先收到最近的状态
+	late, err := viewerInstance.AddViewer(activityID, "viewer-ja", "ja")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	msg = receiveViewerMessage(t, late)
+	if state, ok := msg.Payload.(domain.StatePayload); !ok || state.Status != "PAUSED" {
+		t.Fatalf("late viewer state = %+v", msg)
+	}
+}
+
+func TestSubtitleBroadcaster_SwitchViewerLanguages(t *testing.T) {
+	activityID := "act-switch"
+	history := NewSubtitleHistory(time.Minute, 10)
+	broadcaster := NewSubtitleBroadcaster(nil, history)
+
+	subtitle := newHistorySubtitle(activityID, 1, time.Now())
+	subtitle.Translations["ja"] = "テキスト 1"
+	history.Append(subtitle)
+
+	viewer, err := broadcaster.AddViewer(activityID, "viewer-1", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	defer broadcaster.UnregisterActivity(activityID)
+
+	if err := broadcaster.SwitchViewerLanguages(activityID, "missing", []string{"ja"}, 10); err == nil {
+		t.Fatal("SwitchViewerLanguages() should fail for unknown viewer")
+	}
+	if err := broadcaster.SwitchViewerLanguages(activityID, viewer.ID, []string{"ja"}, 10); err != nil {
+		t.Fatalf("SwitchViewerLanguages() error = %v", err)
+	}
+
+	// 先收到新语言的历史，之后的字幕按新语言投递
+	msg := receiveViewerMessage(t, viewer)
+	replay, ok := msg.Payload.(domain.HistoryPayload)
+	if msg.Type != domain.MessageTypeHistory || !ok || replay.Language != "ja" ||
+		len(replay.Subtitles) != 1 || replay.Subtitles[0].Text != "テキスト 1" {
+		t.Fatalf("unexpected history message: %+v", msg)
+	}
+
+	broadcaster.BroadcastSubtitle(activityID, &domain.Subtitle{ID: "s2", ActivityID: activityID, Original: "原文 2",
+		SourceLang: "zh-CN", Translations: map[string]string{"en": "text 2", "ja": "テキスト 2"}})
+	msg = receiveViewerMessage(t, viewer)
+	if payload, ok := msg.Payload.(domain.SubtitlePayload); !ok || payload.Text != "テキスト 2" {
+		t.Fatalf("unexpected subtitle after switch: %+v", msg)
+	}
+	if counts := broadcaster.GetViewersByLanguage(activityID); counts["ja"] != 1 || counts["en"] != 0 {
+		t.Fatalf("viewers by language = %v", counts)
+	}
+}
+
+func TestSubtitleBroadcaster_MultiLanguageViewer(t *testing.T) {
+	activityID := "act-bilingual"
+	history := NewSubtitleHistory(time.Minute, 10)
+	broadcaster := NewSubtitleBroadcaster(nil, history)
+
+	viewer, err := broadcaster.AddViewer(activityID, "viewer-1", "zh-CN", "en", "ja")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	defer broadcaster.UnregisterActivity(activityID)
+
+	// 每句只发一条，合并全部有文本的订阅语言
+	broadcaster.BroadcastSubtitle(activityID, newHistorySubtitle(activityID, 1, time.Now()))
+	msg := receiveViewerMessage(t, viewer)
+	payload, ok := msg.Payload.(domain.SubtitlePayload)
+	if msg.Type != domain.MessageTypeSubtitle || !ok || payload.TargetLang != "zh-CN" || payload.Text != "原文 1" {
+		t.Fatalf("unexpected subtitle message: %+v", msg)
+	}
+	if len(payload.Translations) != 2 || payload.Translations["en"] != "text 1" || payload.Translations["zh-CN"] != "原文 1" {
+		t.Fatalf("translations = %v", payload.Translations)
+	}
+	if len(viewer.SendChannel) != 0 {
+		t.Fatalf("viewer should receive one message per sentence, %d pending", len(viewer.SendChannel))
+	}
+
+	if items := history.RecentFor(activityID, []string{"zh-CN", "en"}, 0); len(items) != 1 || items[0].Translations["en"] != "text 1" {
+		t.Fatalf("history = %+v", items)
+	}
+	if counts := broadcaster.GetViewersByLanguage(activityID); counts["zh-CN"] != 1 || counts["en"] != 1 || counts["ja"] != 1 {
+		t.Fatalf("viewers by language = %v", counts)
+	}
+}
+
+func TestSubtitleBroadcaster_SequenceAndResend(t *testing.T) {
+	activityID := "act-resend"
+	broadcaster := NewSubtitleBroadcaster(nil, NewSubtitleHistory(time.Minute, 10))
+	viewer, err := broadcaster.AddViewer(activityID, "viewer-1", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	defer broadcaster.UnregisterActivity(activityID)
+
+	// 编号消息逐条递增，PARTIAL 不编号
+	for i := 1; i <= 3; i++ {
+		broadcaster.BroadcastSubtitle(activityID, newHistorySubtitle(activityID, i, time.Now()))
+	}
+	broadcaster.BroadcastPartial(activityID, newHistorySubtitle(activityID, 4, time.Now()))
+	for want := uint64(1); want <= 3; want++ {
+		if msg := receiveViewerMessage(t, viewer); msg.Seq != want {
+			t.Fatalf("seq = %d, want %d", msg.Seq, want)
+		}
+	}
+	if msg := receiveViewerMessage(t, viewer); msg.Type != domain.MessageTypePartial || msg.Seq != 0 {
+		t.Fatalf("unexpected partial message: %+v", msg)
+	}
+
+	// 补发指定句子之后的历史字幕
+	if err := broadcaster.ResendHistory(activityID, viewer.ID, "sub-1", 10); err != nil {
+		t.Fatalf("ResendHistory() error = %v", err)
+	}
+	msg := receiveViewerMessage(t, viewer)
+	history, ok := msg.Payload.(domain.HistoryPayload)
+	if msg.Type != domain.MessageTypeHistory || !ok || msg.Seq != 4 || len(history.Subtitles) != 2 || history.Subtitles[0].ID != "sub-2" {
+		t.Fatalf("unexpected resend message: %+v", msg)
+	}
+	if err := broadcaster.ResendHistory(activityID, "missing", "", 10); err == nil {
+		t.Fatal("ResendHistory() for unknown viewer should fail")
+	}
+}
+
+func TestSubtitleBroadcaster_ResendReadsHistoryOutsideLock(t *testing.T) {
+	activityID := "act-resend-slow"
+	store := &blockingHistoryStore{reading: make(chan struct{}), release: make(chan struct{})}
+	history := NewSubtitleHistory(time.Minute, 10)
+	history.SetStore(store)
+	broadcaster := NewSubtitleBroadcaster(nil, history)
+	replaying, err := broadcaster.AddViewer(activityID, "viewer-replay", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	other, err := broadcaster.AddViewer(activityID, "viewer-other", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	defer broadcaster.UnregisterActivity(activityID)
+	broadcaster.BroadcastSubtitle(activityID, newHistorySubtitle(activityID, 1, time.Now()))
+	receiveViewerMessage(t, replaying)
+	receiveViewerMessage(t, other)
+
+	done := make(chan error, 1)
+	go func() { done <- broadcaster.ResendHistory(activityID, replaying.ID, "", 10) }()
+	<-store.reading
+
+	// 读取历史期间其他观众照常收到字幕
+	broadcaster.BroadcastSubtitle(activityID, newHistorySubtitle(activityID, 2, time.Now()))
+	if msg := receiveViewerMessage(t, other); msg.Type != domain.MessageTypeSubtitle || msg.Seq != 2 {
+		t.Fatalf("unexpected message while history is read: %+v", msg)
+	}
+	close(store.release)
+	if err := <-done; err != nil {
+		t.Fatalf("ResendHistory() error = %v", err)
+	}
+
+	// 读取期间投递的字幕使回放重读，HISTORY 包含该句
+	if msg := receiveViewerMessage(t, replaying); msg.Type != domain.MessageTypeSubtitle || msg.Seq != 2 {
+		t.Fatalf("unexpected live message: %+v", msg)
+	}
+	msg := receiveViewerMessage(t, replaying)
+	replay, ok := msg.Payload.(domain.HistoryPayload)
+	if msg.Type != domain.MessageTypeHistory || !ok || msg.Seq != 3 || len(replay.Subtitles) != 2 {
+		t.Fatalf("unexpected history message: %+v", msg)
+	}
+}
+
+func TestSubtitleBroadcaster_IdleRelease(t *testing.T) {
+	activityID := "act-idle"
+	backend := &countingBackend{Backend: broadcast.NewMemoryBackend()}
+	broadcaster := NewSubtitleBroadcaster(backend, nil)
+	broadcaster.SetIdleRelease(100 * time.Millisecond)
+
+	// 观众在保留期内再次加入时复用订阅
+	for i := 0; i < 3; i++ {
+		if _, err := broadcaster.AddViewer(activityID, "viewer-poll", "en"); err != nil {
+			t.Fatalf("AddViewer() error = %v", err)
+		}
+		broadcaster.RemoveViewer(activityID, "viewer-poll")
+	}
+	if subscribes, active := backend.counts(); subscribes != 1 || active != 1 {
+		t.Fatalf("subscribes = %d, active = %d, want 1 subscription kept", subscribes, active)
+	}
+
+	// 保留期内没有观众加入则取消订阅
+	deadline := time.Now().Add(2 * time.Second)
+	for len(broadcaster.ActivityIDs()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("idle activity was not released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, active := backend.counts(); active != 0 {
+		t.Fatalf("active subscriptions = %d after release", active)
+	}
+
+	// 演讲者注册的活动不会被释放
+	broadcaster.SetIdleRelease(0)
+	broadcaster.RegisterActivity(activityID)
+	defer broadcaster.UnregisterActivity(activityID)
+	if _, err := broadcaster.AddViewer(activityID, "viewer-poll", "en"); err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	broadcaster.RemoveViewer(activityID, "viewer-poll")
+	if ids := broadcaster.ActivityIDs(); len(ids) != 1 {
+		t.Fatalf("registered activity released: %v", ids)
+	}
+}
+
+func TestSubtitleBroadcaster_EvictLaggingViewer(t *testing.T) {
+	activityID := "act-lagging"
+	broadcaster := NewSubtitleBroadcaster(nil, nil)
+	broadcaster.SetLagWindow(200 * time.Millisecond)
+	viewer, err := broadcaster.AddViewer(activityID, "viewer-slow", "en")
+	if err != nil {
+		t.Fatalf("AddViewer() error = %v", err)
+	}
+	defer broadcaster.UnregisterActivity(activityID)
+
+	// 观众不读取，队列写满后的字幕被丢弃并计数
+	for i := 1; i <= viewerSendBuffer+10; i++ {
+		broadcaster.BroadcastSubtitle(activityID, newHistorySubtitle(activityID, i, time.Now()))
+	}
+	if viewer.Dropped() != 10 || broadcaster.DroppedMessages() != 10 {
+		t.Fatalf("dropped = %d (total %d), want 10", viewer.Dropped(), broadcaster.DroppedMessages())
+	}
+	if broadcaster.GetViewerCount(activityID) != 1 {
+		t.Fatal("viewer should not be evicted within the lag window")
+	}
+
+	// 积压超过容忍时长后被断开，最后一条为 RESYNC_REQUIRED
+	time.Sleep(250 * time.Millisecond)
+	broadcaster.BroadcastSubtitle(activityID, newHistorySubtitle(activityID, 0, time.Now()))
+	if broadcaster.EvictedViewers() != 1 || broadcaster.GetViewerCount(activityID) != 0 {
+		t.Fatalf("evicted = %d, viewers = %d", broadcaster.EvictedViewers(), broadcaster.GetViewerCount(activityID))
+	}
+	var last *domain.WebSocketMessage
+	for msg := range viewer.SendChannel {
+		last = msg
+	}
+	state, ok := last.Payload.(domain.StatePayload)
+	if last.Type != domain.MessageTypeState || !ok || state.Status != "RESYNC_REQUIRED" {
+		t.Fatalf("last message = %+v, want RESYNC_REQUIRED", last)
+	}
+	if last.Seq != viewerSendBuffer+12 {
+		t.Fatalf("last seq = %d, want %d", last.Seq, viewerSendBuffer+12)
+	}
+}
+
+func receiveViewerMessage(t *testing.T, viewer *ViewerConnection) *domain.WebSocketMessage {
+	t.Helper()
+	select {
+	case msg := <-viewer.SendChannel:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for message to %s", viewer.ID)
+		return nil
+	}
+}
+
+// blockingHistoryStore 首次读取历史时阻塞，直到 release 关闭
+type blockingHistoryStore struct {
+	mu      sync.Mutex
+	entries [][]byte
+	blocked bool
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingHistoryStore) AppendHistory(_ context.Context, _ string, entry []byte, _ int, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *blockingHistoryStore) History(_ context.Context, _ string) ([][]byte, error) {
+	s.mu.Lock()
+	first := !s.blocked
+	s.blocked = true
+	s.mu.Unlock()
+	if first {
+		close(s.reading)
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.entries...), nil
+}
+
+func (s *blockingHistoryStore) ClearHistory(_ context.Context, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+	return nil
+}
+
+// countingBackend 统计订阅次数与未取消的订阅数
+type countingBackend struct {
+	broadcast.Backend
+	mu         sync.Mutex
+	subscribes int
+	active     int
+}
+
+func (b *countingBackend) Subscribe(ctx context.Context, topic string, handler broadcast.Handler) (func(), error) {
+	unsubscribe, err := b.Backend.Subscribe(ctx, topic, handler)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	b.subscribes++
+	b.active++
+	b.mu.Unlock()
+	return func() {
+		unsubscribe()
+		b.mu.Lock()
+		b.active--
+		b.mu.Unlock()
+	}, nil
+}
+
+func (b *countingBackend) counts() (subscribes, active int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.subscribes, b.active
+}
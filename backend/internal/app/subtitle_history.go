@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/broadcast"
+)
+
+const (
+	defaultHistoryTTL  = 5 * time.Minute
+	defaultHistorySize = 50
+	// historySweepInterval 清理过期活动缓存的最短间隔
+	historySweepInterval = time.Minute
+	// historyStoreTimeout 读写共享历史存储的超时时间
+	historyStoreTimeout = 2 * time.Second
+)
+
+// SubtitleHistory 字幕历史缓存
+// 按活动保存最近的字幕，供迟到或断线重连的观众回放。默认保存在进程内；
+// 多实例部署时设置共享存储，任一实例都能读到完整历史
+type SubtitleHistory struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	maxSize   int
+	entries   map[string][]*domain.Subtitle // activityID -> 按时间排序的字幕
+	lastSweep time.Time
+	store     broadcast.HistoryStore // 共享存储，nil 时使用 entries
+}
+
+// NewSubtitleHistory 创建字幕历史缓存
+// ttl 为单条字幕的保留时长，maxSize 为每个活动保留的最大句数
+func NewSubtitleHistory(ttl time.Duration, maxSize int) *SubtitleHistory {
+	if ttl <= 0 {
+		ttl = defaultHistoryTTL
+	}
+	if maxSize <= 0 {
+		maxSize = defaultHistorySize
+	}
+	return &SubtitleHistory{
+		ttl:       ttl,
+		maxSize:   maxSize,
+		entries:   make(map[string][]*domain.Subtitle),
+		lastSweep: time.Now(),
+	}
+}
+
+// SetStore 使用共享存储保存历史，需在使用前调用
+func (h *SubtitleHistory) SetStore(store broadcast.HistoryStore) {
+	h.store = store
+}
+
+// Append 追加一条字幕
+func (h *SubtitleHistory) Append(subtitle *domain.Subtitle) {
+	if subtitle == nil || subtitle.ActivityID == "" {
+		return
+	}
+
+	if h.store != nil {
+		data, err := json.Marshal(subtitle)
+		if err != nil {
+			log.Printf("Warning: failed to encode history subtitle %s: %v", subtitle.ID, err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), historyStoreTimeout)
+		defer cancel()
+		if err := h.store.AppendHistory(ctx, subtitle.ActivityID, data, h.maxSize, h.ttl); err != nil {
+			log.Printf("Warning: failed to append history for activity %s: %v", subtitle.ActivityID, err)
+		}
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	list := append(h.prune(h.entries[subtitle.ActivityID], now), subtitle)
+	if len(list) > h.maxSize {
+		list = append([]*domain.Subtitle(nil), list[len(list)-h.maxSize:]...)
+	}
+	h.entries[subtitle.ActivityID] = list
+
+	if now.Sub(h.lastSweep) >= historySweepInterval {
+		h.sweep(now)
+	}
+}
+
+// Recent 获取活动最近 limit 条指定语言的字幕（按时间正序）
+// limit <= 0 时返回缓存中的全部字幕
+func (h *SubtitleHistory) Recent(activityID, language string, limit int) []domain.SubtitlePayload {
+	return h.RecentFor(activityID, []string{language}, limit)
+}
+
+// RecentFor 获取活动最近 limit 条字幕，每条合并指定的多个语言，负载格式与实时字幕一致
+func (h *SubtitleHistory) RecentFor(activityID string, languages []string, limit int) []domain.SubtitlePayload {
+	return payloadsFor(h.list(activityID), languages, limit)
+}
+
+// After 获取活动中句子 afterID 之后的全部字幕，供 SSE 断线续传与长轮询游标使用
+// afterID 为空或已不在缓存中（过期或被挤出）时返回最近 limit 条，found 为 false
+func (h *SubtitleHistory) After(activityID string, languages []string, afterID string, limit int) (items []domain.SubtitlePayload, found bool) {
+	list := h.list(activityID)
+
+	if afterID != "" {
+		for i := len(list) - 1; i >= 0; i-- {
+			if list[i].ID == afterID {
+				return payloadsFor(list[i+1:], languages, 0), true
+			}
+		}
+	}
+	return payloadsFor(list, languages, limit), false
+}
+
+// payloadsFor 生成字幕列表中有对应语言文本的负载，limit > 0 时只保留最后 limit 条
+func payloadsFor(list []*domain.Subtitle, languages []string, limit int) []domain.SubtitlePayload {
+	result := make([]domain.SubtitlePayload, 0, len(list))
+	for _, subtitle := range list {
+		payload, ok := subtitle.PayloadForLanguages(languages)
+		if !ok {
+			continue
+		}
+		result = append(result, payload)
+	}
+
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result
+}
+
+// list 返回活动未过期的字幕，共享存储读取失败时返回空
+func (h *SubtitleHistory) list(activityID string) []*domain.Subtitle {
+	now := time.Now()
+	if h.store == nil {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return h.prune(h.entries[activityID], now)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyStoreTimeout)
+	defer cancel()
+	entries, err := h.store.History(ctx, activityID)
+	if err != nil {
+		log.Printf("Warning: failed to read history for activity %s: %v", activityID, err)
+		return nil
+	}
+	list := make([]*domain.Subtitle, 0, len(entries))
+	for _, entry := range entries {
+		var subtitle domain.Subtitle
+		if err := json.Unmarshal(entry, &subtitle); err != nil {
+			continue
+		}
+		list = append(list, &subtitle)
+	}
+	return h.prune(list, now)
+}
+
+// Clear 清空活动的历史字幕
+func (h *SubtitleHistory) Clear(activityID string) {
+	if h.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), historyStoreTimeout)
+		defer cancel()
+		if err := h.store.ClearHistory(ctx, activityID); err != nil {
+			log.Printf("Warning: failed to clear history for activity %s: %v", activityID, err)
+		}
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.entries, activityID)
+}
+
+// prune 返回去掉过期字幕后的切片（不修改原切片）
+func (h *SubtitleHistory) prune(list []*domain.Subtitle, now time.Time) []*domain.Subtitle {
+	cutoff := now.Add(-h.ttl)
+	idx := 0
+	for idx < len(list) && list[idx].Timestamp.Before(cutoff) {
+		idx++
+	}
+	return list[idx:]
+}
+
+// sweep 移除已全部过期的活动，调用方需持有写锁
+func (h *SubtitleHistory) sweep(now time.Time) {
+	for activityID, list := range h.entries {
+		if len(h.prune(list, now)) == 0 {
+			delete(h.entries, activityID)
+		}
+	}
+	h.lastSweep = now
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+func newHistorySubtitle(activityID string, index int, ts time.Time) *domain.Subtitle {
+	return &domain.Subtitle{
+		ID:           fmt.Sprintf("sub-%d", index),
+		ActivityID:   activityID,
+		Original:     fmt.Sprintf("原文 %d", index),
+		SourceLang:   "zh-CN",
+		Translations: map[string]string{"en": fmt.Sprintf("text %d", index)},
+		Timestamp:    ts,
+	}
+}
+
+func TestSubtitleHistory_RecentKeepsLastN(t *testing.T) {
+	history := NewSubtitleHistory(time.Minute, 3)
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		history.Append(newHistorySubtitle("act-1", i, now))
+	}
+
+	items := history.Recent("act-1", "en", 0)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	if items[0].ID != "sub-3" || items[2].ID != "sub-5" {
+		t.Fatalf("unexpected order: %s .. %s", items[0].ID, items[2].ID)
+	}
+	if items[0].Text != "text 3" || items[0].TargetLang != "en" {
+		t.Fatalf("unexpected payload: %+v", items[0])
+	}
+
+	if got := history.Recent("act-1", "en", 2); len(got) != 2 || got[0].ID != "sub-4" {
+		t.Fatalf("expected last 2 items, got %+v", got)
+	}
+}
+
+func TestSubtitleHistory_LanguageAndExpiry(t *testing.T) {
+	history := NewSubtitleHistory(time.Minute, 10)
+	now := time.Now()
+	history.Append(newHistorySubtitle("act-1", 1, now.Add(-2*time.Minute)))
+	history.Append(newHistorySubtitle("act-1", 2, now))
+
+	items := history.Recent("act-1", "en", 0)
+	if len(items) != 1 || items[0].ID != "sub-2" {
+		t.Fatalf("expected expired subtitle to be pruned, got %+v", items)
+	}
+
+	// 源语言观众直接回放原文
+	source := history.Recent("act-1", "zh-CN", 0)
+	if len(source) != 1 || source[0].Text != "原文 2" {
+		t.Fatalf("expected original text for source language, got %+v", source)
+	}
+
+	if got := history.Recent("act-1", "ja", 0); len(got) != 0 {
+		t.Fatalf("expected no subtitles for untranslated language, got %+v", got)
+	}
+
+	history.Clear("act-1")
+	if got := history.Recent("act-1", "en", 0); len(got) != 0 {
+		t.Fatalf("expected empty history after clear, got %+v", got)
+	}
+}
+
+func TestSubtitleHistory_After(t *testing.T) {
+	history := NewSubtitleHistory(time.Minute, 3)
+	now := time.Now()
+	for i := 1; i <= 4; i++ {
+		history.Append(newHistorySubtitle("act-1", i, now))
+	}
+	languages := []string{"en"}
+
+	items, found := history.After("act-1", languages, "sub-3", 2)
+	if !found || len(items) != 1 || items[0].ID != "sub-4" {
+		t.Fatalf("After(sub-3) = %+v, found %t", items, found)
+	}
+	if items, found := history.After("act-1", languages, "sub-4", 2); !found || len(items) != 0 {
+		t.Fatalf("After(latest) = %+v, found %t", items, found)
+	}
+
+	// 游标已被挤出缓存时退回最近 limit 条
+	items, found = history.After("act-1", languages, "sub-1", 2)
+	if found || len(items) != 2 || items[0].ID != "sub-3" {
+		t.Fatalf("After(evicted) = %+v, found %t", items, found)
+	}
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+const (
+	defaultTranscriptPageSize = 100
+	maxTranscriptPageSize     = 500
+)
+
+// SubtitleRepository 定义字幕归档的持久化接口
+type SubtitleRepository interface {
+	SaveSubtitle(ctx context.Context, subtitle *domain.Subtitle) error
+	ListSubtitles(ctx context.Context, activityID string, offset, limit int) ([]*domain.Subtitle, error)
+	CountSubtitles(ctx context.Context, activityID string) (int, error)
+	MarkAudioStarted(ctx context.Context, activityID string, at time.Time) error
+	MarkAudioActivity(ctx context.Context, activityID string, at time.Time) error
+	GetAudioStartedAt(ctx context.Context, activityID string) (*time.Time, error)
+}
+
+// TranscriptService 负责活动文字稿查询
+type TranscriptService struct {
+	activityRepo domain.ActivityRepository
+	repo         SubtitleRepository
+}
+
+// NewTranscriptService 创建文字稿服务
+func NewTranscriptService(activityRepo domain.ActivityRepository, repo SubtitleRepository) *TranscriptService {
+	return &TranscriptService{
+		activityRepo: activityRepo,
+		repo:         repo,
+	}
+}
+
+// GetTranscript 分页获取活动文字稿
+// language 为空时返回全部译文，否则只返回该语言译文
+func (s *TranscriptService) GetTranscript(activityID, language string, page, pageSize int) (*domain.TranscriptPage, error) {
+	activity, err := s.activityRepo.FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	if language != "" && !supportsLanguage(activity, language) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedLanguage, language)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultTranscriptPageSize
+	}
+	if pageSize > maxTranscriptPageSize {
+		pageSize = maxTranscriptPageSize
+	}
+
+	ctx := context.Background()
+	total, err := s.repo.CountSubtitles(ctx, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtitles, err := s.repo.ListSubtitles(ctx, activityID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.TranscriptEntry, 0, len(subtitles))
+	for _, subtitle := range subtitles {
+		entry := domain.TranscriptEntry{
+			ID:         subtitle.ID,
+			Original:   subtitle.Original,
+			SourceLang: subtitle.SourceLang,
+			Confidence: subtitle.Confidence,
+			Timestamp:  subtitle.Timestamp,
+		}
+		if language == "" {
+			entry.Translations = subtitle.Translations
+		} else if payload, ok := subtitle.PayloadFor(language); ok {
+			entry.TargetLang = payload.TargetLang
+			entry.Text = payload.Text
+		}
+		items = append(items, entry)
+	}
+
+	return &domain.TranscriptPage{
+		ActivityID: activityID,
+		Language:   language,
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/repository"
+)
+
+// fakeSubtitleRepo 内存字幕归档，按写入顺序保存
+type fakeSubtitleRepo struct {
+	mu        sync.Mutex
+	subtitles []*domain.Subtitle
+}
+
+func (r *fakeSubtitleRepo) SaveSubtitle(_ context.Context, subtitle *domain.Subtitle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subtitles = append(r.subtitles, subtitle)
+	return nil
+}
+
+func (r *fakeSubtitleRepo) ListSubtitles(_ context.Context, activityID string, offset, limit int) ([]*domain.Subtitle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*domain.Subtitle
+	for _, subtitle := range r.subtitles {
+		if subtitle.ActivityID == activityID {
+			result = append(result, subtitle)
+		}
+	}
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *fakeSubtitleRepo) CountSubtitles(ctx context.Context, activityID string) (int, error) {
+	items, err := r.ListSubtitles(ctx, activityID, 0, 0)
+	return len(items), err
+}
+
+func (r *fakeSubtitleRepo) MarkAudioStarted(context.Context, string, time.Time) error  { return nil }
+func (r *fakeSubtitleRepo) MarkAudioActivity(context.Context, string, time.Time) error { return nil }
+func (r *fakeSubtitleRepo) GetAudioStartedAt(context.Context, string) (*time.Time, error) {
+	return nil, nil
+}
+
+func TestTranscriptService_GetTranscript(t *testing.T) {
+	activityRepo := repository.NewMemoryActivityRepository()
+	activity := &domain.Activity{ID: "act-transcript", InputLanguage: "zh-CN", TargetLanguages: []string{"en", "ja"}}
+	if err := activityRepo.Create(activity); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	subtitles := &fakeSubtitleRepo{}
+	for i := 1; i <= 3; i++ {
+		subtitles.SaveSubtitle(context.Background(), newHistorySubtitle(activity.ID, i, time.Now()))
+	}
+	service := NewTranscriptService(activityRepo, subtitles)
+
+	// 不指定语言时返回全部译文，按页截取
+	page, err := service.GetTranscript(activity.ID, "", 2, 2)
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 1 || page.Items[0].ID != "sub-3" || page.Items[0].Translations["en"] != "text 3" {
+		t.Fatalf("page 2 = %+v", page)
+	}
+
+	// 指定语言时只返回该语言，源语言返回原文
+	page, err = service.GetTranscript(activity.ID, "en", 0, 0)
+	if err != nil {
+		t.Fatalf("GetTranscript(en) error = %v", err)
+	}
+	if page.Page != 1 || page.PageSize != defaultTranscriptPageSize || len(page.Items) != 3 || page.Items[0].Text != "text 1" || page.Items[0].Translations != nil {
+		t.Fatalf("english transcript = %+v", page)
+	}
+	if page, err := service.GetTranscript(activity.ID, "zh-CN", 1, 1); err != nil || page.Items[0].Text != "原文 1" {
+		t.Fatalf("source transcript = %+v, %v", page, err)
+	}
+
+	if _, err := service.GetTranscript(activity.ID, "fr", 1, 10); !errors.Is(err, domain.ErrUnsupportedLanguage) {
+		t.Fatalf("GetTranscript(fr) error = %v, want ErrUnsupportedLanguage", err)
+	}
+	if _, err := service.GetTranscript("missing", "", 1, 10); !errors.Is(err, domain.ErrActivityNotFound) {
+		t.Fatalf("GetTranscript(missing) error = %v, want ErrActivityNotFound", err)
+	}
+}
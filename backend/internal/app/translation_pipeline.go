@@ -6,62 +6,107 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/config"
 	"github.com/hoshea/orion-backend/internal/infra/google"
+	"github.com/hoshea/orion-backend/internal/infra/metrics"
+	"github.com/hoshea/orion-backend/internal/infra/mt"
+	"github.com/hoshea/orion-backend/internal/infra/stt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// speechClient 抽象语音识别客户端
-type speechClient interface {
-	StreamingRecognize(ctx context.Context, audioStream <-chan []byte, config google.StreamingRecognizeConfig, results chan<- google.RecognitionResult) error
-	Close() error
-}
-
-// translateClient 抽象翻译客户端
-type translateClient interface {
-	Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]google.TranslationResult, error)
-	Close() error
-}
-
 // TranslationPipeline 翻译管线服务，负责协调 STT 识别和 Translation 翻译
 type TranslationPipeline struct {
-	sttClient         speechClient
-	translationClient translateClient
+	sttClient         stt.Client
+	speechProvider    string // 识别引擎名称，用于监控指标
+	translationClient mt.Translator
+	archive           SubtitleRepository // 可选，字幕归档
+	glossary          GlossaryRepository // 可选，活动术语表
 	mu                sync.RWMutex
 	sessions          map[string]*PipelineSession // activityID -> session
 }
 
+// SessionOptions 翻译会话的可选配置
+type SessionOptions struct {
+	// InterimTranslationInterval 中间结果最短翻译间隔，0 表示中间结果只推送原文
+	InterimTranslationInterval time.Duration
+	// AudioFormat 演讲者声明的音频格式，零值为 16kHz 单声道 LINEAR16
+	AudioFormat domain.AudioFormat
+}
+
 // PipelineSession 翻译会话
 type PipelineSession struct {
 	ActivityID      string
 	SourceLanguage  string
 	TargetLanguages []string
+	Options         SessionOptions
 	AudioInput      chan []byte           // 音频输入
-	SubtitleOutput  chan *domain.Subtitle // 字幕输出（包含所有语言翻译）
+	SubtitleOutput  chan *domain.Subtitle // 字幕输出（包含所有语言翻译，Partial 为中间结果）
 	cancel          context.CancelFunc
 	ctx             context.Context
+	stopMu          sync.RWMutex // 保护 AudioInput 的关闭，避免向已关闭的 channel 发送
+	stopped         bool
+	firstAudio      sync.Once
+	audioStartedAt  atomic.Int64 // 首个音频块到达时间（UnixNano）
+	onFirstAudio    func(at time.Time)
+	audioMarkedAt   atomic.Int64 // 最近一次上报音频活动的时间（UnixNano）
+	onAudioActivity func(at time.Time)
+
+	formatMu      sync.Mutex
+	format        domain.AudioFormat // 演讲者声明的格式
+	streamFormat  domain.AudioFormat // 送入识别引擎的格式
+	converter     *stt.PCMConverter  // 引擎只接受 PCM 时在 Go 中转换，nil 表示直通
+	formatChanged chan struct{}      // 演讲者切换格式，需按新格式重建识别流
+	header        []byte             // Opus 容器头，重建识别流时补发
+	headerDone    bool
+
+	stateMu sync.Mutex
+	state   sessionState
+	stateCh chan struct{} // 状态变化时关闭并替换，唤醒识别循环
+	done    chan struct{} // processSession 退出（剩余字幕已输出）时关闭
+
+	glossaryMu       sync.Mutex
+	glossary         *Glossary
+	glossaryLoadedAt time.Time
+	glossaryLoading  bool // 正在重新加载，其他调用方沿用旧术语表
 }
 
+// sessionState 会话的演讲者控制状态
+type sessionState int
+
+const (
+	sessionStreaming sessionState = iota // 正常识别
+	sessionPaused                        // 暂停：丢弃音频，不保持识别流
+	sessionFinishing                     // 停止：不再接收音频，等待剩余结果输出
+)
+
 const (
-	// streamRestartInterval 单个流的最长持续时间，必须小于 Google 官方 5 分钟限制
-	streamRestartInterval = 4*time.Minute + 30*time.Second
 	// streamErrorBackoff 遇到异常时的简单退避
 	streamErrorBackoff = time.Second
+	// archiveTimeout 单条字幕归档的超时时间
+	archiveTimeout = 5 * time.Second
+	// glossaryRefreshInterval 会话术语表的刷新间隔，术语修改在该间隔内生效
+	glossaryRefreshInterval = 30 * time.Second
+	// audioActivityInterval 上报最近音频时间的最短间隔，供调度器判断无音频自动关闭
+	audioActivityInterval = 30 * time.Second
 )
 
-// NewTranslationPipeline 创建真实的翻译管线
-func NewTranslationPipeline(ctx context.Context, sttAPIKey, translateAPIKey string) (*TranslationPipeline, error) {
-	sttClient, err := google.NewSTTClient(ctx, sttAPIKey)
+// NewTranslationPipeline 根据配置创建翻译管线
+// 识别引擎按 cfg.Speech.Provider 选择，翻译引擎按 cfg.Translation 的默认引擎与目标语言路由选择
+// archive 为空时不归档字幕，glossary 为空时不应用术语表
+func NewTranslationPipeline(ctx context.Context, cfg *config.Config, speechProviders *stt.Registry, translationProviders *mt.Registry, archive SubtitleRepository, glossary GlossaryRepository) (*TranslationPipeline, error) {
+	sttClient, err := speechProviders.New(ctx, cfg.Speech.Provider, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create STT client: %w", err)
 	}
 
-	translationClient, err := google.NewTranslationClient(ctx, translateAPIKey)
+	translationClient, err := translationProviders.NewRouter(ctx, cfg)
 	if err != nil {
 		_ = sttClient.Close()
 		return nil, fmt.Errorf("failed to create translation client: %w", err)
@@ -69,16 +114,22 @@ func NewTranslationPipeline(ctx context.Context, sttAPIKey, translateAPIKey stri
 
 	return &TranslationPipeline{
 		sttClient:         sttClient,
+		speechProvider:    cfg.Speech.Provider,
 		translationClient: translationClient,
+		archive:           archive,
+		glossary:          glossary,
 		sessions:          make(map[string]*PipelineSession),
 	}, nil
 }
 
 // NewMockTranslationPipeline 创建 Mock 管线（无需真实 API Key）
-func NewMockTranslationPipeline() *TranslationPipeline {
+func NewMockTranslationPipeline(archive SubtitleRepository, glossary GlossaryRepository) *TranslationPipeline {
 	return &TranslationPipeline{
 		sttClient:         google.NewMockSTTClient(),
+		speechProvider:    "mock",
 		translationClient: google.NewMockTranslationClient(),
+		archive:           archive,
+		glossary:          glossary,
 		sessions:          make(map[string]*PipelineSession),
 	}
 }
@@ -89,9 +140,7 @@ func (p *TranslationPipeline) Close() error {
 	defer p.mu.Unlock()
 
 	for _, session := range p.sessions {
-		session.cancel()
-		close(session.AudioInput)
-		close(session.SubtitleOutput)
+		session.stop()
 	}
 	p.sessions = make(map[string]*PipelineSession)
 
@@ -105,7 +154,12 @@ func (p *TranslationPipeline) Close() error {
 }
 
 // StartSession 开始翻译会话
-func (p *TranslationPipeline) StartSession(activityID, sourceLanguage string, targetLanguages []string) (*PipelineSession, error) {
+func (p *TranslationPipeline) StartSession(activityID, sourceLanguage string, targetLanguages []string, opts SessionOptions) (*PipelineSession, error) {
+	streamFormat, converter, err := p.resolveAudioFormat(opts.AudioFormat)
+	if err != nil {
+		return nil, err
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -118,10 +172,23 @@ func (p *TranslationPipeline) StartSession(activityID, sourceLanguage string, ta
 		ActivityID:      activityID,
 		SourceLanguage:  sourceLanguage,
 		TargetLanguages: targetLanguages,
+		Options:         opts,
 		AudioInput:      make(chan []byte, 100),
 		SubtitleOutput:  make(chan *domain.Subtitle, 50),
 		ctx:             ctx,
 		cancel:          cancel,
+		format:          opts.AudioFormat.Normalize(),
+		streamFormat:    streamFormat,
+		converter:       converter,
+		formatChanged:   make(chan struct{}, 1),
+		stateCh:         make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	session.onFirstAudio = func(at time.Time) {
+		p.markAudioStarted(activityID, at)
+	}
+	session.onAudioActivity = func(at time.Time) {
+		p.markAudioActivity(activityID, at)
 	}
 
 	p.sessions[activityID] = session
@@ -141,15 +208,42 @@ func (p *TranslationPipeline) StopSession(activityID string) error {
 		return fmt.Errorf("session not found for activity %s", activityID)
 	}
 
-	session.cancel()
-	close(session.AudioInput)
-	close(session.SubtitleOutput)
+	session.stop()
 	delete(p.sessions, activityID)
 
 	log.Printf("Stopped translation session for activity %s", activityID)
 	return nil
 }
 
+// FinishSession 结束会话：不再接收音频，等待识别引擎输出剩余的最终结果并完成翻译后关闭会话
+// 超过 timeout 仍未完成时强制停止
+func (p *TranslationPipeline) FinishSession(activityID string, timeout time.Duration) error {
+	session, err := p.GetSession(activityID)
+	if err != nil {
+		return err
+	}
+
+	session.setState(sessionFinishing)
+	select {
+	case <-session.done:
+	case <-time.After(timeout):
+		log.Printf("Timed out flushing translation session for activity %s", activityID)
+	}
+	return p.StopSession(activityID)
+}
+
+// ActiveSessions 返回进行中的会话数
+func (p *TranslationPipeline) ActiveSessions() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.sessions)
+}
+
+// SpeechProvider 返回识别引擎名称
+func (p *TranslationPipeline) SpeechProvider() string {
+	return p.speechProvider
+}
+
 // GetSession 获取会话
 func (p *TranslationPipeline) GetSession(activityID string) (*PipelineSession, error) {
 	p.mu.RLock()
@@ -162,8 +256,156 @@ func (p *TranslationPipeline) GetSession(activityID string) (*PipelineSession, e
 	return session, nil
 }
 
-// SendAudio 发送音频数据到会话
+// resolveAudioFormat 确定送入识别引擎的格式
+// 引擎支持的编码直接透传；只接受 PCM 的引擎由 Go 完成 μ-law 解码、下混与重采样。
+// 服务端不解码 Opus（需要引入 libopus 的 cgo 依赖），Vosk、whisper.cpp 下由客户端改发 PCM
+func (p *TranslationPipeline) resolveAudioFormat(format domain.AudioFormat) (domain.AudioFormat, *stt.PCMConverter, error) {
+	format = format.Normalize()
+	if err := format.Validate(); err != nil {
+		return domain.AudioFormat{}, nil, err
+	}
+
+	if supporter, ok := p.sttClient.(stt.EncodingSupporter); ok && supporter.SupportsEncoding(string(format.Encoding)) {
+		return format, nil, nil
+	}
+	if format.Encoding.IsOpus() {
+		return domain.AudioFormat{}, nil, fmt.Errorf("%w: 当前识别引擎仅支持 PCM 输入，服务端不解码 Opus，请改用 LINEAR16 或 MULAW", domain.ErrUnsupportedAudioFormat)
+	}
+
+	target := domain.DefaultAudioFormat()
+	if format == target {
+		return target, nil, nil
+	}
+	converter := stt.NewPCMConverter(
+		format.Encoding == domain.AudioEncodingMulaw,
+		int(format.Channels),
+		int(format.SampleRateHertz),
+		int(target.SampleRateHertz),
+	)
+	return target, converter, nil
+}
+
+// SetAudioFormat 切换会话音频格式，识别流按新格式重建
+// Opus 格式切换后客户端需从新的容器头开始发送
+func (p *TranslationPipeline) SetAudioFormat(session *PipelineSession, format domain.AudioFormat) error {
+	streamFormat, converter, err := p.resolveAudioFormat(format)
+	if err != nil {
+		return err
+	}
+
+	session.formatMu.Lock()
+	session.format = format.Normalize()
+	session.streamFormat = streamFormat
+	session.converter = converter
+	session.header = nil
+	session.headerDone = false
+	session.formatMu.Unlock()
+
+	select {
+	case session.formatChanged <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// AudioFormat 返回演讲者当前声明的音频格式
+func (s *PipelineSession) AudioFormat() domain.AudioFormat {
+	s.formatMu.Lock()
+	defer s.formatMu.Unlock()
+	return s.format
+}
+
+// StreamFormat 返回当前送入识别引擎的音频格式
+func (s *PipelineSession) StreamFormat() domain.AudioFormat {
+	s.formatMu.Lock()
+	defer s.formatMu.Unlock()
+	return s.streamFormat
+}
+
+// Pause 暂停识别：结束当前识别流，之后的音频直接丢弃；返回状态是否改变
+func (s *PipelineSession) Pause() bool {
+	return s.transition(sessionStreaming, sessionPaused)
+}
+
+// Resume 恢复识别，按当前格式重建识别流；返回状态是否改变
+func (s *PipelineSession) Resume() bool {
+	return s.transition(sessionPaused, sessionStreaming)
+}
+
+// Paused 是否处于暂停状态
+func (s *PipelineSession) Paused() bool {
+	state, _ := s.controlState()
+	return state == sessionPaused
+}
+
+// controlState 返回当前状态及下一次状态变化时关闭的 channel
+func (s *PipelineSession) controlState() (sessionState, <-chan struct{}) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return s.state, s.stateCh
+}
+
+// transition 当前状态为 from 时切换到 to
+func (s *PipelineSession) transition(from, to sessionState) bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	if s.state != from {
+		return false
+	}
+	s.changeState(to)
+	return true
+}
+
+// setState 无条件切换状态
+func (s *PipelineSession) setState(to sessionState) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	if s.state != to {
+		s.changeState(to)
+	}
+}
+
+// changeState 调用方需持有 stateMu
+func (s *PipelineSession) changeState(to sessionState) {
+	s.state = to
+	close(s.stateCh)
+	s.stateCh = make(chan struct{})
+}
+
+// SendAudio 发送音频数据到会话，暂停期间的音频直接丢弃
 func (s *PipelineSession) SendAudio(audioData []byte) error {
+	switch state, _ := s.controlState(); state {
+	case sessionPaused:
+		return nil
+	case sessionFinishing:
+		return fmt.Errorf("session finishing")
+	}
+
+	s.firstAudio.Do(func() {
+		now := time.Now()
+		s.audioStartedAt.Store(now.UnixNano())
+		if s.onFirstAudio != nil {
+			go s.onFirstAudio(now)
+		}
+	})
+	s.reportAudioActivity()
+
+	s.stopMu.RLock()
+	defer s.stopMu.RUnlock()
+	if s.stopped {
+		return fmt.Errorf("session closed")
+	}
+	metrics.AddAudioBytes(len(audioData))
+
+	s.formatMu.Lock()
+	if s.converter != nil {
+		audioData = s.converter.Convert(audioData)
+	}
+	s.formatMu.Unlock()
+	if len(audioData) == 0 {
+		return nil
+	}
+
 	select {
 	case s.AudioInput <- audioData:
 		return nil
@@ -174,45 +416,157 @@ func (s *PipelineSession) SendAudio(audioData []byte) error {
 	}
 }
 
+// stop 取消会话并关闭音频输入；字幕输出由 processSession 退出时关闭
+func (s *PipelineSession) stop() {
+	s.stopMu.Lock()
+	defer s.stopMu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	s.cancel()
+	close(s.AudioInput)
+}
+
+// reportAudioActivity 按 audioActivityInterval 节流上报最近音频时间
+func (s *PipelineSession) reportAudioActivity() {
+	if s.onAudioActivity == nil {
+		return
+	}
+	now := time.Now()
+	last := s.audioMarkedAt.Load()
+	if now.UnixNano()-last < int64(audioActivityInterval) {
+		return
+	}
+	if s.audioMarkedAt.CompareAndSwap(last, now.UnixNano()) {
+		go s.onAudioActivity(now)
+	}
+}
+
 func (p *TranslationPipeline) processSession(session *PipelineSession) {
-	sttResults := make(chan google.RecognitionResult, 50)
+	// processSession 是字幕输出唯一的写入方，由它负责关闭
+	defer close(session.done)
+	defer close(session.SubtitleOutput)
+
+	sttResults := make(chan stt.RecognitionResult, 50)
 	go p.streamRecognitionWithRestart(session, sttResults)
 
-	var lastFinalTranscript string
-	for result := range sttResults {
+	// 中间结果的翻译在独立协程中进行，避免翻译耗时拖慢识别结果的处理
+	var (
+		interimRequests chan interimRequest
+		interimResults  chan interimResult
+	)
+	if session.Options.InterimTranslationInterval > 0 {
+		interimRequests = make(chan interimRequest, 1)
+		interimResults = make(chan interimResult)
+		loopDone := make(chan struct{})
+		defer close(loopDone)
+		go p.translateInterim(session, interimRequests, interimResults, loopDone)
+		defer close(interimRequests)
+	}
+
+	var (
+		lastFinalTranscript   string
+		lastFinalAt           time.Time
+		sentenceID            string    // 当前句子 ID，中间结果与最终结果共用
+		sentenceStartedAt     time.Time // 当前句子首个中间结果的到达时间
+		lastPartial           *domain.Subtitle
+		lastPartialTranslated time.Time
+	)
+	for {
+		var result stt.RecognitionResult
+		select {
+		case r, ok := <-sttResults:
+			if !ok {
+				return
+			}
+			result = r
+		case translated := <-interimResults:
+			// 翻译返回前句子已结束或已切换时丢弃，避免旧译文覆盖最终字幕
+			if lastPartial == nil || translated.sentenceID != sentenceID {
+				continue
+			}
+			// 附在最新的中间结果上推送，原文不回退
+			partial := *lastPartial
+			partial.Translations = translated.translations
+			session.emitPartial(&partial)
+			continue
+		}
+
 		if !result.IsFinal {
+			now := time.Now()
+			if sentenceID == "" {
+				sentenceID = uuid.New().String()
+				sentenceStartedAt = now
+			}
+			if result.Transcript == "" || (lastPartial != nil && result.Transcript == lastPartial.Original) {
+				continue
+			}
+
+			partial := &domain.Subtitle{
+				ID:         sentenceID,
+				ActivityID: session.ActivityID,
+				Original:   result.Transcript,
+				SourceLang: session.SourceLanguage,
+				Confidence: result.Confidence,
+				StartedAt:  sentenceStartedAt,
+				Timestamp:  now,
+				Partial:    true,
+			}
+			lastPartial = partial
+			session.emitPartial(partial)
+
+			// 中间结果按活动配置的间隔节流翻译，控制翻译成本
+			if interimRequests != nil && now.Sub(lastPartialTranslated) >= session.Options.InterimTranslationInterval {
+				lastPartialTranslated = now
+				submitInterim(interimRequests, interimRequest{sentenceID: sentenceID, text: result.Transcript})
+			}
 			continue
 		}
+
+		finalAt := time.Now()
+		subtitleID := sentenceID
+		if subtitleID == "" {
+			subtitleID = uuid.New().String()
+		}
+		startedAt := sentenceStartedAt
+		if startedAt.IsZero() {
+			// 没有中间结果时，以上一句结束或首个音频块作为句子开始
+			startedAt = lastFinalAt
+			if audioStart := session.AudioStartedAt(); audioStart.After(startedAt) {
+				startedAt = audioStart
+			}
+		}
+		sentenceID = ""
+		sentenceStartedAt = time.Time{}
+		lastPartial = nil
+		lastPartialTranslated = time.Time{}
+		lastFinalAt = finalAt
+
 		if result.Transcript == "" || result.Transcript == lastFinalTranscript {
 			continue
 		}
 		lastFinalTranscript = result.Transcript
 
-		translations, err := p.translationClient.Translate(
-			session.ctx,
-			result.Transcript,
-			session.SourceLanguage,
-			session.TargetLanguages,
-		)
+		translationMap, err := p.translate(session, result.Transcript)
 		if err != nil {
 			log.Printf("Translation error for activity %s: %v", session.ActivityID, err)
 			continue
 		}
 
-		translationMap := make(map[string]string)
-		for _, t := range translations {
-			translationMap[t.Language] = t.Text
-		}
-
 		subtitle := &domain.Subtitle{
-			ID:           uuid.New().String(),
+			ID:           subtitleID,
 			ActivityID:   session.ActivityID,
 			Original:     result.Transcript,
 			SourceLang:   session.SourceLanguage,
 			Translations: translationMap,
 			Confidence:   result.Confidence,
-			Timestamp:    time.Now(),
+			StartedAt:    startedAt,
+			Timestamp:    finalAt,
 		}
+		// 异步归档，数据库变慢时不拖延实时字幕
+		go p.archiveSubtitle(subtitle)
 
 		select {
 		case session.SubtitleOutput <- subtitle:
@@ -220,69 +574,289 @@ func (p *TranslationPipeline) processSession(session *PipelineSession) {
 		case <-session.ctx.Done():
 			return
 		default:
+			metrics.IncSubtitleDropped()
 			log.Printf("Warning: subtitle output buffer full for activity %s", session.ActivityID)
 		}
 	}
 }
 
-func (p *TranslationPipeline) streamRecognitionWithRestart(session *PipelineSession, results chan<- google.RecognitionResult) {
+// interimRequest 待翻译的中间结果
+type interimRequest struct {
+	sentenceID string
+	text       string
+}
+
+// interimResult 中间结果的译文
+type interimResult struct {
+	sentenceID   string
+	translations map[string]string
+}
+
+// emitPartial 推送中间结果，中间结果会被后续结果覆盖，缓冲区满时直接丢弃
+func (s *PipelineSession) emitPartial(partial *domain.Subtitle) {
+	select {
+	case s.SubtitleOutput <- partial:
+	default:
+	}
+}
+
+// submitInterim 提交中间结果翻译，上一个请求尚未开始处理时用最新的替换
+// 只有识别循环一个发送方，清空后写入不会阻塞
+func submitInterim(requests chan interimRequest, req interimRequest) {
+	select {
+	case requests <- req:
+		return
+	default:
+	}
+	select {
+	case <-requests:
+	default:
+	}
+	requests <- req
+}
+
+// translateInterim 逐个翻译中间结果并交回识别循环，由识别循环决定是否仍需推送
+func (p *TranslationPipeline) translateInterim(session *PipelineSession, requests <-chan interimRequest, results chan<- interimResult, loopDone <-chan struct{}) {
+	for req := range requests {
+		translations, err := p.translate(session, req.text)
+		if err != nil {
+			log.Printf("Interim translation error for activity %s: %v", session.ActivityID, err)
+			continue
+		}
+		select {
+		case results <- interimResult{sentenceID: req.sentenceID, translations: translations}:
+		case <-loopDone:
+			return
+		}
+	}
+}
+
+// translate 将文本翻译为会话的全部目标语言
+// 命中术语表时按语言替换占位符，占位符相同的语言合并为一次请求
+func (p *TranslationPipeline) translate(session *PipelineSession, text string) (map[string]string, error) {
+	glossary := p.sessionGlossary(session)
+
+	var (
+		order        []string
+		groups       = make(map[string][]string)
+		replacements = make(map[string][]string)
+	)
+	for _, lang := range session.TargetLanguages {
+		protected, replaced := glossary.Protect(text, lang)
+		if _, ok := groups[protected]; !ok {
+			order = append(order, protected)
+		}
+		groups[protected] = append(groups[protected], lang)
+		replacements[lang] = replaced
+	}
+
+	translationMap := make(map[string]string, len(session.TargetLanguages))
+	for _, protected := range order {
+		translations, err := p.translationClient.Translate(
+			session.ctx,
+			protected,
+			session.SourceLanguage,
+			groups[protected],
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range translations {
+			translationMap[t.Language] = restoreGlossary(t.Text, replacements[t.Language])
+		}
+	}
+	return translationMap, nil
+}
+
+// sessionGlossary 返回会话术语表，超过刷新间隔时重新加载
+// 查询数据库时不持有锁，同一时间只有一个调用方加载，其余调用方直接使用旧术语表
+func (p *TranslationPipeline) sessionGlossary(session *PipelineSession) *Glossary {
+	if p.glossary == nil {
+		return nil
+	}
+
+	session.glossaryMu.Lock()
+	current := session.glossary
+	fresh := current != nil && time.Since(session.glossaryLoadedAt) < glossaryRefreshInterval
+	if fresh || session.glossaryLoading {
+		session.glossaryMu.Unlock()
+		return current
+	}
+	session.glossaryLoading = true
+	session.glossaryMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(session.ctx, archiveTimeout)
+	terms, err := p.glossary.ListTerms(ctx, session.ActivityID)
+	cancel()
+
+	session.glossaryMu.Lock()
+	defer session.glossaryMu.Unlock()
+	session.glossaryLoading = false
+	// 加载失败时也记录时间，沿用旧术语表，稍后重试
+	session.glossaryLoadedAt = time.Now()
+	if err != nil {
+		log.Printf("Failed to load glossary for activity %s: %v", session.ActivityID, err)
+		return session.glossary
+	}
+	session.glossary = NewGlossary(terms)
+	return session.glossary
+}
+
+// AudioStartedAt 返回会话首个音频块的到达时间，尚未收到音频时返回零值
+func (s *PipelineSession) AudioStartedAt() time.Time {
+	if nanos := s.audioStartedAt.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// markAudioStarted 记录活动首个音频块时间，用于导出字幕时间轴
+func (p *TranslationPipeline) markAudioStarted(activityID string, at time.Time) {
+	if p.archive == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+	defer cancel()
+
+	if err := p.archive.MarkAudioStarted(ctx, activityID, at); err != nil {
+		log.Printf("Failed to mark audio start for activity %s: %v", activityID, err)
+	}
+}
+
+// markAudioActivity 记录活动最近一次收到音频的时间
+func (p *TranslationPipeline) markAudioActivity(activityID string, at time.Time) {
+	if p.archive == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+	defer cancel()
+
+	if err := p.archive.MarkAudioActivity(ctx, activityID, at); err != nil {
+		log.Printf("Failed to mark audio activity for activity %s: %v", activityID, err)
+	}
+}
+
+// archiveSubtitle 持久化字幕，失败时仅记录日志，不影响实时广播
+// 在独立协程中执行，导出与文字稿按字幕时间排序，不依赖写入顺序
+func (p *TranslationPipeline) archiveSubtitle(subtitle *domain.Subtitle) {
+	if p.archive == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+	defer cancel()
+
+	if err := p.archive.SaveSubtitle(ctx, subtitle); err != nil {
+		log.Printf("Failed to archive subtitle %s for activity %s: %v", subtitle.ID, subtitle.ActivityID, err)
+	}
+}
+
+func (p *TranslationPipeline) streamRecognitionWithRestart(session *PipelineSession, results chan<- stt.RecognitionResult) {
 	defer close(results)
 
-	config := google.StreamingRecognizeConfig{
-		LanguageCode:               session.SourceLanguage,
-		SampleRateHertz:            16000,
-		EnableAutomaticPunctuation: true,
+	// 仅对存在流时长上限的引擎定期重建识别流
+	var restartInterval time.Duration
+	if limiter, ok := p.sttClient.(stt.StreamLimiter); ok {
+		restartInterval = limiter.MaxStreamDuration()
 	}
 
+	// resumed 表示识别流因时长上限或异常重建，音频仍是同一条 Opus 容器流
+	resumed := false
 	for {
 		if session.ctx.Err() != nil {
 			return
 		}
 
+		state, changed := session.controlState()
+		switch state {
+		case sessionFinishing:
+			// 停止前已缓冲的音频仍需识别：用一个立即结束的识别流送出后退出
+			if len(session.AudioInput) == 0 {
+				return
+			}
+			end := make(chan struct{})
+			close(end)
+			changed = end
+		case sessionPaused:
+			// 暂停期间不保持识别流，避免引擎因长时间无音频报错
+			select {
+			case <-changed:
+			case <-session.ctx.Done():
+			}
+			continue
+		}
+
+		// 每次（重建）识别流时带上最新术语作为短语提示
+		format := session.StreamFormat()
+		config := stt.StreamingRecognizeConfig{
+			LanguageCode:               session.SourceLanguage,
+			Encoding:                   string(format.Encoding),
+			SampleRateHertz:            format.SampleRateHertz,
+			AudioChannelCount:          format.Channels,
+			EnableAutomaticPunctuation: true,
+			PhraseHints:                p.sessionGlossary(session).PhraseHints(),
+		}
+
 		streamCtx, cancel := context.WithCancel(session.ctx)
 		errCh := make(chan error, 1)
 
+		// 状态变化时结束音频流，引擎据此输出剩余的最终结果
+		audio := session.streamAudio(streamCtx, format.Encoding, resumed, changed)
 		go func() {
 			errCh <- p.sttClient.StreamingRecognize(
 				streamCtx,
-				session.AudioInput,
+				audio,
 				config,
 				results,
 			)
 		}()
 
-		timer := time.NewTimer(streamRestartInterval)
+		var restart <-chan time.Time
+		var timer *time.Timer
+		if restartInterval > 0 {
+			timer = time.NewTimer(restartInterval)
+			restart = timer.C
+		}
 		var err error
+		resumed = true
 
 		select {
 		case <-session.ctx.Done():
 			cancel()
-			err = <-errCh
-			timer.Stop()
+			<-errCh
+			if timer != nil {
+				timer.Stop()
+			}
 			return
 		case err = <-errCh:
-			timer.Stop()
-		case <-timer.C:
+		case <-restart:
+			metrics.IncSTTRestart(p.speechProvider, metrics.RestartDurationLimit)
+			cancel()
+			err = <-errCh
+		case <-session.formatChanged:
+			metrics.IncSTTRestart(p.speechProvider, metrics.RestartFormatChange)
 			cancel()
 			err = <-errCh
+			resumed = false
 		}
 
-		if !timer.Stop() {
-			select {
-			case <-timer.C:
-			default:
-			}
+		if timer != nil {
+			timer.Stop()
 		}
 		cancel()
 
 		switch {
 		case err == nil:
-			return
+			// 音频流结束（暂停、停止或会话关闭），由循环开头按状态处理
+			continue
 		case errors.Is(err, context.Canceled):
 			continue
 		case status.Code(err) == codes.Canceled:
 			continue
 		default:
+			metrics.IncSTTRestart(p.speechProvider, metrics.RestartError)
 			log.Printf("STT stream error for activity %s: %v", session.ActivityID, err)
 			select {
 			case <-time.After(streamErrorBackoff):
@@ -292,3 +866,102 @@ func (p *TranslationPipeline) streamRecognitionWithRestart(session *PipelineSess
 		}
 	}
 }
+
+// streamAudio 为单个识别流转发音频，end 关闭或会话音频输入关闭时结束
+// Opus 容器格式：首个识别流记录容器头；重建的识别流先补发容器头，并丢弃数据直到下一个 Ogg 页或 WebM Cluster 起点
+func (s *PipelineSession) streamAudio(ctx context.Context, encoding domain.AudioEncoding, resumed bool, end <-chan struct{}) <-chan []byte {
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+
+		send := func(data []byte) bool {
+			select {
+			case out <- data:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		container := encoding.IsOpus()
+		synced := true
+		if container && resumed {
+			// 容器头尚未完整时无法补发，只能原样转发
+			if header := s.containerHeader(); header != nil {
+				if !send(header) {
+					return
+				}
+				synced = false
+			}
+		}
+
+		var carry []byte
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-end:
+				// 结束前送出已缓冲的音频，暂停或停止前说的话也能识别
+				for synced {
+					select {
+					case chunk, ok := <-s.AudioInput:
+						if !ok || !send(chunk) {
+							return
+						}
+					default:
+						return
+					}
+				}
+				return
+			case chunk, ok := <-s.AudioInput:
+				if !ok {
+					return
+				}
+				if !synced {
+					data := append(carry, chunk...)
+					idx := stt.ContainerSync(string(encoding), data)
+					if idx < 0 {
+						// 保留尾部字节，同步标记可能跨越两个音频块
+						if keep := stt.ContainerSyncMarkerSize - 1; len(data) > keep {
+							data = data[len(data)-keep:]
+						}
+						carry = append(carry[:0], data...)
+						continue
+					}
+					chunk, carry, synced = data[idx:], nil, true
+				} else if container {
+					s.recordContainerHeader(string(encoding), chunk)
+				}
+				if !send(chunk) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// containerHeader 返回已记录的完整容器头
+func (s *PipelineSession) containerHeader() []byte {
+	s.formatMu.Lock()
+	defer s.formatMu.Unlock()
+	if !s.headerDone {
+		return nil
+	}
+	return s.header
+}
+
+// recordContainerHeader 从流开头累积容器头，直到遇到首个音频数据
+func (s *PipelineSession) recordContainerHeader(encoding string, chunk []byte) {
+	s.formatMu.Lock()
+	defer s.formatMu.Unlock()
+	if s.headerDone {
+		return
+	}
+	s.header = append(s.header, chunk...)
+	header, complete := stt.ContainerHeader(encoding, s.header)
+	if complete {
+		s.header = header
+		s.headerDone = true
+	}
+}
@@ -0,0 +1,355 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/mt"
+	"github.com/hoshea/orion-backend/internal/infra/stt"
+)
+
+func TestPipelineSession_SendAudioAfterStop(t *testing.T) {
+	pipeline := NewMockTranslationPipeline(nil, nil)
+	session, err := pipeline.StartSession("activity-1", "zh-CN", []string{"en"}, SessionOptions{})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if err := session.SendAudio(make([]byte, 320)); err != nil {
+		t.Fatalf("SendAudio() error = %v", err)
+	}
+
+	if err := pipeline.StopSession("activity-1"); err != nil {
+		t.Fatalf("StopSession() error = %v", err)
+	}
+	// 会话被外部停止后演讲者可能仍在发送音频，不能向已关闭的 channel 写入
+	if err := session.SendAudio(make([]byte, 320)); err == nil {
+		t.Fatal("SendAudio() after stop should fail")
+	}
+
+	// 字幕输出在处理协程退出后关闭
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-session.SubtitleOutput:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for subtitle output to close")
+		}
+	}
+}
+
+// pcmOnlySTTClient 只接受 PCM 的识别引擎
+type pcmOnlySTTClient struct{}
+
+func (pcmOnlySTTClient) StreamingRecognize(ctx context.Context, audioStream <-chan []byte, config stt.StreamingRecognizeConfig, results chan<- stt.RecognitionResult) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (pcmOnlySTTClient) Close() error { return nil }
+
+func TestTranslationPipeline_ResolveAudioFormat(t *testing.T) {
+	pcmOnly := &TranslationPipeline{sttClient: pcmOnlySTTClient{}}
+
+	format, converter, err := pcmOnly.resolveAudioFormat(domain.AudioFormat{})
+	if err != nil || converter != nil || format != domain.DefaultAudioFormat() {
+		t.Fatalf("default format = %+v, converter %v, err %v", format, converter, err)
+	}
+
+	format, converter, err = pcmOnly.resolveAudioFormat(domain.AudioFormat{Encoding: "linear16", SampleRateHertz: 48000, Channels: 2})
+	if err != nil || converter == nil || format != domain.DefaultAudioFormat() {
+		t.Fatalf("48kHz stereo = %+v, converter %v, err %v", format, converter, err)
+	}
+
+	_, _, err = pcmOnly.resolveAudioFormat(domain.AudioFormat{Encoding: domain.AudioEncodingWebMOpus})
+	if !errors.Is(err, domain.ErrUnsupportedAudioFormat) {
+		t.Fatalf("opus on PCM-only engine error = %v", err)
+	}
+
+	// 支持 Opus 的引擎直接透传
+	passthrough := NewMockTranslationPipeline(nil, nil)
+	format, converter, err = passthrough.resolveAudioFormat(domain.AudioFormat{Encoding: domain.AudioEncodingWebMOpus})
+	if err != nil || converter != nil || format.SampleRateHertz != 48000 {
+		t.Fatalf("opus passthrough = %+v, converter %v, err %v", format, converter, err)
+	}
+
+	_, _, err = passthrough.resolveAudioFormat(domain.AudioFormat{Encoding: domain.AudioEncodingOggOpus, SampleRateHertz: 44100})
+	if !errors.Is(err, domain.ErrUnsupportedAudioFormat) {
+		t.Fatalf("opus at 44.1kHz error = %v", err)
+	}
+}
+
+func TestPipelineSession_ContainerStreamResume(t *testing.T) {
+	session := &PipelineSession{AudioInput: make(chan []byte, 10)}
+	cluster := []byte{0x1F, 0x43, 0xB6, 0x75}
+	header := []byte{0x1A, 0x45, 0xDF, 0xA3}
+
+	// 首个识别流记录容器头
+	ctx, cancel := context.WithCancel(context.Background())
+	first := session.streamAudio(ctx, domain.AudioEncodingWebMOpus, false, nil)
+	session.AudioInput <- append(append([]byte{}, header...), cluster...)
+	<-first
+	cancel()
+	if got := session.containerHeader(); !bytes.Equal(got, header) {
+		t.Fatalf("recorded header = %x, want %x", got, header)
+	}
+
+	// 重建的识别流先补发容器头，再从下一个 Cluster 开始转发；同步标记跨越两个音频块
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resumed := session.streamAudio(ctx, domain.AudioEncodingWebMOpus, true, nil)
+	session.AudioInput <- []byte{0x01, 0x02, 0x1F, 0x43}
+	session.AudioInput <- []byte{0xB6, 0x75, 0x09}
+
+	if got := <-resumed; !bytes.Equal(got, header) {
+		t.Fatalf("first chunk = %x, want header", got)
+	}
+	if got := <-resumed; !bytes.Equal(got, append(append([]byte{}, cluster...), 0x09)) {
+		t.Fatalf("second chunk = %x, want cluster", got)
+	}
+}
+
+func TestPipelineSession_PauseResumeFinish(t *testing.T) {
+	pipeline := NewMockTranslationPipeline(nil, nil)
+	session, err := pipeline.StartSession("activity-1", "zh-CN", []string{"en"}, SessionOptions{})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	if !session.Pause() || session.Pause() {
+		t.Fatal("Pause() should only change state once")
+	}
+	// 暂停期间的音频被丢弃，不产生字幕
+	if err := session.SendAudio(make([]byte, 320)); err != nil {
+		t.Fatalf("SendAudio() while paused error = %v", err)
+	}
+	select {
+	case subtitle := <-session.SubtitleOutput:
+		t.Fatalf("unexpected subtitle while paused: %+v", subtitle)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if !session.Resume() {
+		t.Fatal("Resume() should change state")
+	}
+	if err := session.SendAudio(make([]byte, 320)); err != nil {
+		t.Fatalf("SendAudio() error = %v", err)
+	}
+
+	// 停止时输出已送入的音频对应的最终字幕，然后关闭输出
+	if err := pipeline.FinishSession("activity-1", 2*time.Second); err != nil {
+		t.Fatalf("FinishSession() error = %v", err)
+	}
+	finals := 0
+	for subtitle := range session.SubtitleOutput {
+		if !subtitle.Partial {
+			finals++
+		}
+	}
+	if finals != 1 {
+		t.Fatalf("final subtitles = %d, want 1", finals)
+	}
+	if _, err := pipeline.GetSession("activity-1"); err == nil {
+		t.Fatal("session should be removed after FinishSession")
+	}
+}
+
+// scriptedSTTClient 首个识别流依次输出预设结果，之后只消费音频
+// 输出第 holdAt 个结果前等待 hold 关闭
+type scriptedSTTClient struct {
+	once   sync.Once
+	script []stt.RecognitionResult
+	hold   chan struct{}
+	holdAt int
+}
+
+func (c *scriptedSTTClient) StreamingRecognize(ctx context.Context, audioStream <-chan []byte, config stt.StreamingRecognizeConfig, results chan<- stt.RecognitionResult) error {
+	c.once.Do(func() {
+		for i, result := range c.script {
+			if c.hold != nil && i == c.holdAt {
+				<-c.hold
+			}
+			results <- result
+		}
+	})
+	for {
+		select {
+		case _, ok := <-audioStream:
+			if !ok {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *scriptedSTTClient) Close() error { return nil }
+
+// recordingTranslator 记录翻译的文本，blocked 中的文本在 release 关闭前阻塞
+type recordingTranslator struct {
+	mu      sync.Mutex
+	texts   []string
+	blocked map[string]bool
+	entered chan string
+	release chan struct{}
+}
+
+func (t *recordingTranslator) Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]mt.TranslationResult, error) {
+	t.mu.Lock()
+	t.texts = append(t.texts, text)
+	t.mu.Unlock()
+	if t.entered != nil {
+		t.entered <- text
+	}
+	if t.blocked[text] {
+		<-t.release
+	}
+	results := make([]mt.TranslationResult, 0, len(targetLangs))
+	for _, lang := range targetLangs {
+		results = append(results, mt.TranslationResult{Language: lang, Text: "[" + lang + "] " + text})
+	}
+	return results, nil
+}
+
+func (t *recordingTranslator) Close() error { return nil }
+
+func (t *recordingTranslator) translated() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.texts...)
+}
+
+// stopAndCollect 停止会话并读取全部输出
+func stopAndCollect(t *testing.T, pipeline *TranslationPipeline, session *PipelineSession) []*domain.Subtitle {
+	t.Helper()
+	if err := pipeline.StopSession(session.ActivityID); err != nil {
+		t.Fatalf("StopSession() error = %v", err)
+	}
+	var subtitles []*domain.Subtitle
+	for subtitle := range session.SubtitleOutput {
+		subtitles = append(subtitles, subtitle)
+	}
+	return subtitles
+}
+
+func TestProcessSession_InterimTranslationOffLoop(t *testing.T) {
+	translator := &recordingTranslator{
+		blocked: map[string]bool{"你好": true},
+		entered: make(chan string, 10),
+		release: make(chan struct{}),
+	}
+	client := &scriptedSTTClient{script: []stt.RecognitionResult{
+		{Transcript: "你好"},
+		{Transcript: "你好世界"},
+		{Transcript: "你好世界。", IsFinal: true},
+	}, hold: make(chan struct{}), holdAt: 1}
+	pipeline := &TranslationPipeline{
+		sttClient:         client,
+		translationClient: translator,
+		sessions:          make(map[string]*PipelineSession),
+	}
+	session, err := pipeline.StartSession("activity-1", "zh-CN", []string{"en"}, SessionOptions{InterimTranslationInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if text := <-translator.entered; text != "你好" {
+		t.Fatalf("first translation = %q, want interim 你好", text)
+	}
+
+	// 中间结果翻译阻塞期间，后续中间结果与最终字幕照常输出
+	close(client.hold)
+	var final *domain.Subtitle
+	deadline := time.After(2 * time.Second)
+	for final == nil {
+		select {
+		case subtitle := <-session.SubtitleOutput:
+			if subtitle.Partial && subtitle.Translations != nil {
+				t.Fatalf("unexpected translated partial while translation blocked: %+v", subtitle)
+			}
+			if !subtitle.Partial {
+				final = subtitle
+			}
+		case <-deadline:
+			t.Fatal("final subtitle blocked by interim translation")
+		}
+	}
+	if final.Translations["en"] != "[en] 你好世界。" {
+		t.Fatalf("final translations = %v", final.Translations)
+	}
+
+	// 句子结束后返回的中间结果译文已过期，不再推送
+	close(translator.release)
+	for text := range translator.entered {
+		if text == "你好世界" {
+			break
+		}
+	}
+	for _, subtitle := range stopAndCollect(t, pipeline, session) {
+		if subtitle.Partial {
+			t.Fatalf("stale interim translation pushed after final: %+v", subtitle)
+		}
+	}
+}
+
+func TestProcessSession_InterimTranslationInterval(t *testing.T) {
+	translator := &recordingTranslator{}
+	client := &scriptedSTTClient{script: []stt.RecognitionResult{
+		{Transcript: "一"},
+		{Transcript: "一二"},
+		{Transcript: "一二"},
+		{Transcript: "一二三"},
+		{Transcript: "一二三。", IsFinal: true},
+		{Transcript: "四"},
+	}, hold: make(chan struct{}), holdAt: 4}
+	pipeline := &TranslationPipeline{
+		sttClient:         client,
+		translationClient: translator,
+		sessions:          make(map[string]*PipelineSession),
+	}
+	session, err := pipeline.StartSession("activity-1", "zh-CN", []string{"en"}, SessionOptions{InterimTranslationInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	// 每句第一个中间结果立即翻译，间隔内的后续中间结果只推送原文
+	var partials, translated []string
+	deadline := time.After(2 * time.Second)
+	for len(translated) < 2 {
+		select {
+		case subtitle := <-session.SubtitleOutput:
+			if !subtitle.Partial {
+				continue
+			}
+			if subtitle.Translations == nil {
+				partials = append(partials, subtitle.Original)
+				continue
+			}
+			translated = append(translated, subtitle.Translations["en"])
+			if len(translated) == 1 {
+				// 第一句的中间结果译文送达后再输出最终结果
+				close(client.hold)
+			}
+		case <-deadline:
+			t.Fatalf("timed out, partials = %v, translated = %v", partials, translated)
+		}
+	}
+	stopAndCollect(t, pipeline, session)
+
+	if want := []string{"一", "一二", "一二三", "四"}; len(partials) != len(want) {
+		t.Fatalf("partials = %v, want %v (duplicates skipped)", partials, want)
+	}
+	if translated[0] != "[en] 一" || translated[1] != "[en] 四" {
+		t.Fatalf("translated partials = %v", translated)
+	}
+	if got := translator.translated(); len(got) != 3 {
+		t.Fatalf("translation calls = %v, want interim 一, final 一二三。 and interim 四", got)
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/google"
+	"github.com/hoshea/orion-backend/internal/infra/mt"
+)
+
+// DefaultTranslationProviders 返回内置机器翻译引擎注册表
+// libretranslate 可部署在本地，配合离线识别引擎实现全内网运行
+func DefaultTranslationProviders() *mt.Registry {
+	registry := mt.NewRegistry()
+	registry.Register("google", func(ctx context.Context, cfg *config.Config) (mt.Translator, error) {
+		if cfg.Google.TranslateAPIKey == "" {
+			return nil, errors.New("GOOGLE_TRANSLATE_API_KEY 未配置")
+		}
+		return google.NewTranslationClient(ctx, cfg.Google.TranslateAPIKey)
+	})
+	registry.Register("mock", func(ctx context.Context, cfg *config.Config) (mt.Translator, error) {
+		return google.NewMockTranslationClient(), nil
+	})
+	registry.Register("deepl", func(ctx context.Context, cfg *config.Config) (mt.Translator, error) {
+		return mt.NewDeepLClient(cfg.Translation.DeepLAPIURL, cfg.Translation.DeepLAPIKey)
+	})
+	registry.Register("libretranslate", func(ctx context.Context, cfg *config.Config) (mt.Translator, error) {
+		return mt.NewLibreTranslateClient(cfg.Translation.LibreTranslateURL, cfg.Translation.LibreTranslateAPIKey)
+	})
+	registry.Register("llm", func(ctx context.Context, cfg *config.Config) (mt.Translator, error) {
+		return mt.NewLLMClient(cfg.Translation.LLMAPIURL, cfg.Translation.LLMAPIKey, cfg.Translation.LLMModel)
+	})
+	return registry
+}
@@ -0,0 +1,276 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// UserRepository 定义管理后台用户的持久化接口
+type UserRepository interface {
+	Create(ctx context.Context, user *domain.User) error
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*domain.User, error)
+	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	List(ctx context.Context, orgID string) ([]*domain.User, error)
+	// CountByRole 统计组织内指定角色的用户数，orgID 为空时不限组织，role 为空时统计全部角色
+	CountByRole(ctx context.Context, orgID string, role domain.UserRole) (int, error)
+}
+
+// UserService 负责管理后台用户管理与密码校验
+type UserService struct {
+	repo     UserRepository
+	sessions RefreshSessionRepository // 修改密码或降级时注销用户的刷新会话，nil 时不注销
+	// dummyHash 用户不存在时参与比较，避免通过响应耗时探测用户名
+	dummyHash []byte
+}
+
+// NewUserService 创建用户服务
+func NewUserService(repo UserRepository) *UserService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte(uuid.New().String()), bcrypt.DefaultCost)
+	return &UserService{
+		repo:      repo,
+		dummyHash: dummyHash,
+	}
+}
+
+// SetSessions 设置刷新会话存储，重置密码或降低角色后注销该用户的全部会话
+func (s *UserService) SetSessions(sessions RefreshSessionRepository) {
+	s.sessions = sessions
+}
+
+// EnsureSuperAdmin 保证至少存在一名超级管理员
+// 数据库中没有任何用户时，使用给定账号在默认组织创建超级管理员；
+// 从单组织版本升级时，将同名的已有账号提升为超级管理员。返回是否创建或提升了用户
+func (s *UserService) EnsureSuperAdmin(username, password string) (bool, error) {
+	ctx := context.Background()
+	superAdmins, err := s.repo.CountByRole(ctx, "", domain.UserRoleSuperAdmin)
+	if err != nil {
+		return false, err
+	}
+	if superAdmins > 0 {
+		return false, nil
+	}
+
+	username = strings.TrimSpace(username)
+	if username != "" {
+		user, err := s.repo.FindByUsername(ctx, username)
+		if err == nil {
+			user.Role = domain.UserRoleSuperAdmin
+			user.UpdatedAt = time.Now()
+			return true, s.repo.Update(ctx, user)
+		}
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return false, err
+		}
+	}
+
+	count, err := s.repo.CountByRole(ctx, "", "")
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if username == "" || password == "" {
+		return false, errors.New("admin username/password must be configured to create the initial super admin")
+	}
+	_, err = s.createUser(ctx, domain.DefaultOrganizationID, &domain.CreateUserRequest{
+		Username: username,
+		Password: password,
+		Role:     domain.UserRoleSuperAdmin,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyCredentials 校验用户名和密码
+func (s *UserService) VerifyCredentials(username, password string) (*domain.User, error) {
+	user, err := s.repo.FindByUsername(context.Background(), strings.TrimSpace(username))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+			return nil, domain.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// ListUsers 列出组织内的全部用户
+func (s *UserService) ListUsers(orgID string) ([]*domain.User, error) {
+	return s.repo.List(context.Background(), orgID)
+}
+
+// GetUser 获取用户（不限组织）
+func (s *UserService) GetUser(id string) (*domain.User, error) {
+	return s.repo.FindByID(context.Background(), id)
+}
+
+// GetOrganizationUser 获取组织内的用户，不属于该组织时返回 ErrUserNotFound
+func (s *UserService) GetOrganizationUser(orgID, id string) (*domain.User, error) {
+	return s.findInOrganization(context.Background(), orgID, id)
+}
+
+// CreateUser 在组织内创建用户，只有超级管理员可以创建超级管理员
+func (s *UserService) CreateUser(orgID string, actorRole domain.UserRole, req *domain.CreateUserRequest) (*domain.User, error) {
+	if req.Role == domain.UserRoleSuperAdmin && actorRole != domain.UserRoleSuperAdmin {
+		return nil, domain.ErrRoleNotAllowed
+	}
+	return s.createUser(context.Background(), orgID, req)
+}
+
+func (s *UserService) createUser(ctx context.Context, orgID string, req *domain.CreateUserRequest) (*domain.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, fmt.Errorf("用户名不能为空")
+	}
+	if !req.Role.Valid() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidUserRole, req.Role)
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &domain.User{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Username:       username,
+		DisplayName:    strings.TrimSpace(req.DisplayName),
+		Role:           req.Role,
+		PasswordHash:   hash,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUser 更新组织内用户的资料、角色或密码
+func (s *UserService) UpdateUser(orgID string, actorRole domain.UserRole, id string, req *domain.UpdateUserRequest) (*domain.User, error) {
+	ctx := context.Background()
+	user, err := s.findInOrganization(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if actorRole != domain.UserRoleSuperAdmin &&
+		(user.Role == domain.UserRoleSuperAdmin || (req.Role != nil && *req.Role == domain.UserRoleSuperAdmin)) {
+		return nil, domain.ErrRoleNotAllowed
+	}
+
+	// 重置密码或降低角色后，已登录的设备需重新登录
+	revokeSessions := false
+	if req.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Role != nil && *req.Role != user.Role {
+		if !req.Role.Valid() {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidUserRole, *req.Role)
+		}
+		if err := s.ensureNotLastAdmin(ctx, user); err != nil {
+			return nil, err
+		}
+		revokeSessions = !req.Role.AtLeast(user.Role)
+		user.Role = *req.Role
+	}
+	if req.Password != nil {
+		hash, err := hashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = hash
+		revokeSessions = true
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if revokeSessions && s.sessions != nil {
+		if _, err := s.sessions.RevokeUserSessions(ctx, user.ID, user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+	return user, nil
+}
+
+// DeleteUser 删除组织内的用户
+func (s *UserService) DeleteUser(orgID string, actorRole domain.UserRole, id string) error {
+	ctx := context.Background()
+	user, err := s.findInOrganization(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if user.Role == domain.UserRoleSuperAdmin && actorRole != domain.UserRoleSuperAdmin {
+		return domain.ErrRoleNotAllowed
+	}
+	if err := s.ensureNotLastAdmin(ctx, user); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *UserService) findInOrganization(ctx context.Context, orgID, id string) (*domain.User, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationID != orgID {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// ensureNotLastAdmin 防止移除组织的最后一名所有者或系统的最后一名超级管理员
+func (s *UserService) ensureNotLastAdmin(ctx context.Context, user *domain.User) error {
+	switch user.Role {
+	case domain.UserRoleOwner:
+		owners, err := s.repo.CountByRole(ctx, user.OrganizationID, domain.UserRoleOwner)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return domain.ErrLastOwner
+		}
+	case domain.UserRoleSuperAdmin:
+		superAdmins, err := s.repo.CountByRole(ctx, "", domain.UserRoleSuperAdmin)
+		if err != nil {
+			return err
+		}
+		if superAdmins <= 1 {
+			return domain.ErrLastSuperAdmin
+		}
+	}
+	return nil
+}
+
+// hashPassword 使用 bcrypt 计算密码哈希
+func hashPassword(password string) (string, error) {
+	if len(password) < 8 {
+		return "", fmt.Errorf("密码长度至少 8 个字符")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,343 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+type fakeUserRepo struct {
+	users map[string]*domain.User
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: make(map[string]*domain.User)}
+}
+
+func (f *fakeUserRepo) Create(_ context.Context, user *domain.User) error {
+	for _, existing := range f.users {
+		if strings.EqualFold(existing.Username, user.Username) {
+			return domain.ErrUserExists
+		}
+	}
+	cloned := *user
+	f.users[user.ID] = &cloned
+	return nil
+}
+
+func (f *fakeUserRepo) Update(_ context.Context, user *domain.User) error {
+	if _, ok := f.users[user.ID]; !ok {
+		return domain.ErrUserNotFound
+	}
+	cloned := *user
+	f.users[user.ID] = &cloned
+	return nil
+}
+
+func (f *fakeUserRepo) Delete(_ context.Context, id string) error {
+	if _, ok := f.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(f.users, id)
+	return nil
+}
+
+func (f *fakeUserRepo) FindByID(_ context.Context, id string) (*domain.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	cloned := *user
+	return &cloned, nil
+}
+
+func (f *fakeUserRepo) FindByUsername(_ context.Context, username string) (*domain.User, error) {
+	for _, user := range f.users {
+		if strings.EqualFold(user.Username, username) {
+			cloned := *user
+			return &cloned, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (f *fakeUserRepo) List(_ context.Context, orgID string) ([]*domain.User, error) {
+	result := make([]*domain.User, 0, len(f.users))
+	for _, user := range f.users {
+		if user.OrganizationID != orgID {
+			continue
+		}
+		cloned := *user
+		result = append(result, &cloned)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Username < result[j].Username })
+	return result, nil
+}
+
+func (f *fakeUserRepo) CountByRole(_ context.Context, orgID string, role domain.UserRole) (int, error) {
+	count := 0
+	for _, user := range f.users {
+		if (orgID == "" || user.OrganizationID == orgID) && (role == "" || user.Role == role) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestUserService_EnsureSuperAdminAndAuthenticate(t *testing.T) {
+	repo := newFakeUserRepo()
+	users := NewUserService(repo)
+
+	created, err := users.EnsureSuperAdmin("admin", "admin123")
+	if err != nil || !created {
+		t.Fatalf("EnsureSuperAdmin() = %v, %v", created, err)
+	}
+	// 已有超级管理员时不再重复创建
+	if created, err := users.EnsureSuperAdmin("other", "password"); err != nil || created {
+		t.Fatalf("second EnsureSuperAdmin() = %v, %v", created, err)
+	}
+
+	admin, err := repo.FindByUsername(context.Background(), "admin")
+	if err != nil {
+		t.Fatalf("super admin not created: %v", err)
+	}
+	if admin.Role != domain.UserRoleSuperAdmin || admin.OrganizationID != domain.DefaultOrganizationID ||
+		admin.PasswordHash == "admin123" || !strings.HasPrefix(admin.PasswordHash, "$2") {
+		t.Fatalf("unexpected super admin: %+v", admin)
+	}
+
+	auth := newTestAuthService(t, users)
+	if _, err := auth.Authenticate("admin", "wrong-password", domain.SessionMetadata{}); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if _, err := auth.Authenticate("nobody", "admin123", domain.SessionMetadata{}); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials for unknown user, got %v", err)
+	}
+
+	tokens, err := auth.Authenticate("Admin", "admin123", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	claims, err := auth.ValidateAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+	if claims.UserID != admin.ID || claims.Role != string(domain.UserRoleSuperAdmin) || claims.OrganizationID != domain.DefaultOrganizationID {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestUserService_EnsureSuperAdminPromotesExistingOwner(t *testing.T) {
+	repo := newFakeUserRepo()
+	users := NewUserService(repo)
+	owner, err := users.CreateUser(domain.DefaultOrganizationID, domain.UserRoleSuperAdmin, &domain.CreateUserRequest{
+		Username: "admin", Password: "admin123", Role: domain.UserRoleOwner,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	// 从单组织版本升级：ADMIN_USERNAME 对应的所有者被提升为超级管理员
+	if promoted, err := users.EnsureSuperAdmin("admin", ""); err != nil || !promoted {
+		t.Fatalf("EnsureSuperAdmin() = %v, %v", promoted, err)
+	}
+	if user, _ := repo.FindByID(context.Background(), owner.ID); user.Role != domain.UserRoleSuperAdmin {
+		t.Fatalf("role = %s, want super-admin", user.Role)
+	}
+}
+
+func TestUserService_RefreshUsesCurrentRole(t *testing.T) {
+	users := newTestOwner(t)
+	operator, err := users.CreateUser(domain.DefaultOrganizationID, domain.UserRoleOwner, &domain.CreateUserRequest{
+		Username: "op", Password: "operator-pass", Role: domain.UserRoleOperator,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	auth := newTestAuthService(t, users)
+	tokens, err := auth.Authenticate("op", "operator-pass", domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	readOnly := domain.UserRoleReadOnly
+	if _, err := users.UpdateUser(domain.DefaultOrganizationID, domain.UserRoleOwner, operator.ID, &domain.UpdateUserRequest{Role: &readOnly}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	refreshed, err := auth.Refresh(tokens.RefreshToken, domain.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	claims, _ := auth.ValidateAccessToken(refreshed.AccessToken)
+	if claims.Role != string(domain.UserRoleReadOnly) {
+		t.Fatalf("role = %s, want read-only", claims.Role)
+	}
+
+	// 用户删除后刷新令牌失效
+	if err := users.DeleteUser(domain.DefaultOrganizationID, domain.UserRoleOwner, operator.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err := auth.Refresh(refreshed.RefreshToken, domain.SessionMetadata{}); err == nil {
+		t.Fatal("expected refresh to fail for deleted user")
+	}
+}
+
+func TestUserService_UpdateRevokesSessions(t *testing.T) {
+	users := newTestOwner(t)
+	operator, err := users.CreateUser(domain.DefaultOrganizationID, domain.UserRoleOwner, &domain.CreateUserRequest{
+		Username: "op", Password: "operator-pass", Role: domain.UserRoleOperator,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	auth, sessions := newTestAuthServiceWithSessions(t, users)
+	users.SetSessions(sessions)
+
+	login := func(password string) string {
+		t.Helper()
+		tokens, err := auth.Authenticate("op", password, domain.SessionMetadata{})
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		return tokens.RefreshToken
+	}
+	update := func(req *domain.UpdateUserRequest) {
+		t.Helper()
+		if _, err := users.UpdateUser(domain.DefaultOrganizationID, domain.UserRoleOwner, operator.ID, req); err != nil {
+			t.Fatalf("UpdateUser() error = %v", err)
+		}
+	}
+	refresh := func(token string) (string, error) {
+		tokens, err := auth.Refresh(token, domain.SessionMetadata{})
+		if err != nil {
+			return "", err
+		}
+		return tokens.RefreshToken, nil
+	}
+
+	// 修改显示名与提升角色不影响已登录的会话
+	token := login("operator-pass")
+	name, organizer := "Operator", domain.UserRoleOrganizer
+	update(&domain.UpdateUserRequest{DisplayName: &name, Role: &organizer})
+	token, err = refresh(token)
+	if err != nil {
+		t.Fatalf("Refresh() after promotion error = %v", err)
+	}
+
+	// 重置密码后旧会话全部失效
+	password := "new-operator-pass"
+	update(&domain.UpdateUserRequest{Password: &password})
+	if _, err := refresh(token); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Fatalf("Refresh() after password change error = %v, want ErrRefreshTokenInvalid", err)
+	}
+
+	// 降低角色后同样需要重新登录
+	token = login(password)
+	operatorRole := domain.UserRoleOperator
+	update(&domain.UpdateUserRequest{Role: &operatorRole})
+	if _, err := refresh(token); !errors.Is(err, domain.ErrRefreshTokenInvalid) {
+		t.Fatalf("Refresh() after demotion error = %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestUserService_KeepsLastOwner(t *testing.T) {
+	repo := newFakeUserRepo()
+	users := NewUserService(repo)
+	const org = domain.DefaultOrganizationID
+	owner, err := users.CreateUser(org, domain.UserRoleOwner, &domain.CreateUserRequest{
+		Username: "admin", Password: "admin123", Role: domain.UserRoleOwner,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	organizer := domain.UserRoleOrganizer
+	if _, err := users.UpdateUser(org, domain.UserRoleOwner, owner.ID, &domain.UpdateUserRequest{Role: &organizer}); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner on demote, got %v", err)
+	}
+	if err := users.DeleteUser(org, domain.UserRoleOwner, owner.ID); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner on delete, got %v", err)
+	}
+
+	if _, err := users.CreateUser(org, domain.UserRoleOwner, &domain.CreateUserRequest{Username: "ADMIN", Password: "password", Role: domain.UserRoleReadOnly}); !errors.Is(err, domain.ErrUserExists) {
+		t.Fatalf("expected ErrUserExists, got %v", err)
+	}
+	if _, err := users.CreateUser(org, domain.UserRoleOwner, &domain.CreateUserRequest{Username: "x", Password: "password", Role: "admin"}); !errors.Is(err, domain.ErrInvalidUserRole) {
+		t.Fatalf("expected ErrInvalidUserRole, got %v", err)
+	}
+
+	// 其他组织的所有者不计入
+	if _, err := users.CreateUser("org-b", domain.UserRoleSuperAdmin, &domain.CreateUserRequest{Username: "b-owner", Password: "password", Role: domain.UserRoleOwner}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := users.DeleteUser(org, domain.UserRoleOwner, owner.ID); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner with owner only in another org, got %v", err)
+	}
+
+	if _, err := users.CreateUser(org, domain.UserRoleOwner, &domain.CreateUserRequest{Username: "second", Password: "password", Role: domain.UserRoleOwner}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := users.DeleteUser(org, domain.UserRoleOwner, owner.ID); err != nil {
+		t.Fatalf("DeleteUser() with another owner error = %v", err)
+	}
+}
+
+func TestUserService_OrganizationIsolation(t *testing.T) {
+	users := NewUserService(newFakeUserRepo())
+	admin, err := users.CreateUser(domain.DefaultOrganizationID, domain.UserRoleSuperAdmin, &domain.CreateUserRequest{
+		Username: "root", Password: "password", Role: domain.UserRoleSuperAdmin,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := users.CreateUser("org-b", domain.UserRoleSuperAdmin, &domain.CreateUserRequest{Username: "b", Password: "password", Role: domain.UserRoleOwner}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	if list, _ := users.ListUsers("org-b"); len(list) != 1 || list[0].Username != "b" {
+		t.Fatalf("unexpected org-b users: %+v", list)
+	}
+	if _, err := users.GetOrganizationUser("org-b", admin.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound across organizations, got %v", err)
+	}
+	if err := users.DeleteUser("org-b", domain.UserRoleOwner, admin.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound deleting across organizations, got %v", err)
+	}
+
+	// 所有者不能授予或管理超级管理员
+	if _, err := users.CreateUser("org-b", domain.UserRoleOwner, &domain.CreateUserRequest{Username: "c", Password: "password", Role: domain.UserRoleSuperAdmin}); !errors.Is(err, domain.ErrRoleNotAllowed) {
+		t.Fatalf("expected ErrRoleNotAllowed, got %v", err)
+	}
+	if err := users.DeleteUser(domain.DefaultOrganizationID, domain.UserRoleOwner, admin.ID); !errors.Is(err, domain.ErrRoleNotAllowed) {
+		t.Fatalf("expected ErrRoleNotAllowed, got %v", err)
+	}
+	if err := users.DeleteUser(domain.DefaultOrganizationID, domain.UserRoleSuperAdmin, admin.ID); !errors.Is(err, domain.ErrLastSuperAdmin) {
+		t.Fatalf("expected ErrLastSuperAdmin, got %v", err)
+	}
+}
+
+func TestUserRoleAtLeast(t *testing.T) {
+	cases := []struct {
+		role, minimum domain.UserRole
+		want          bool
+	}{
+		{domain.UserRoleSuperAdmin, domain.UserRoleOwner, true},
+		{domain.UserRoleOwner, domain.UserRoleSuperAdmin, false},
+		{domain.UserRoleOwner, domain.UserRoleOrganizer, true},
+		{domain.UserRoleOrganizer, domain.UserRoleOperator, true},
+		{domain.UserRoleOperator, domain.UserRoleOrganizer, false},
+		{domain.UserRoleReadOnly, domain.UserRoleReadOnly, true},
+		{domain.UserRoleReadOnly, domain.UserRoleOperator, false},
+		{"admin", domain.UserRoleReadOnly, false},
+	}
+	for _, tc := range cases {
+		if got := tc.role.AtLeast(tc.minimum); got != tc.want {
+			t.Errorf("%s.AtLeast(%s) = %v, want %v", tc.role, tc.minimum, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// viewerSendBuffer 观众发送队列长度
+const viewerSendBuffer = 100
+
+// ViewerConnection 观众连接
+// 经发送队列投递的消息（PARTIAL 除外）逐条编号，客户端据 seq 发现缺失后可请求从历史补发
+type ViewerConnection struct {
+	ID          string
+	Languages   []string                      // 订阅的语言，加入后只能在活动广播器的锁内读写
+	SendChannel chan *domain.WebSocketMessage // 发送字幕（SUBTITLE / PARTIAL）与状态的 channel
+
+	seq          uint64         // 最近一条编号消息的序列号，活动锁内读写
+	laggingSince time.Time      // 发送队列积压过半的起始时间，活动锁内读写
+	dropped      atomic.Uint64  // 未送达的消息数
+	droppedTotal *atomic.Uint64 // 广播器的未送达总数
+}
+
+func newViewerConnection(id string, languages []string, droppedTotal *atomic.Uint64) *ViewerConnection {
+	return &ViewerConnection{
+		ID:           id,
+		Languages:    languages,
+		SendChannel:  make(chan *domain.WebSocketMessage, viewerSendBuffer),
+		droppedTotal: droppedTotal,
+	}
+}
+
+// Dropped 返回该观众未送达的消息数
+func (v *ViewerConnection) Dropped() uint64 {
+	return v.dropped.Load()
+}
+
+// recordDropped 记录一条未送达的消息
+func (v *ViewerConnection) recordDropped() {
+	v.dropped.Add(1)
+	if v.droppedTotal != nil {
+		v.droppedTotal.Add(1)
+	}
+}
+
+// numbered 复制消息并分配该观众的下一个序列号，调用方需持有活动锁
+func (v *ViewerConnection) numbered(msg *domain.WebSocketMessage) *domain.WebSocketMessage {
+	v.seq++
+	copied := *msg
+	copied.Seq = v.seq
+	return &copied
+}
+
+// send 非阻塞投递编号消息，队列已满时丢弃并计数；序列号照常占用，客户端可据此发现缺失
+func (v *ViewerConnection) send(msg *domain.WebSocketMessage) bool {
+	select {
+	case v.SendChannel <- v.numbered(msg):
+		return true
+	default:
+		v.recordDropped()
+		return false
+	}
+}
+
+// sendPartial 投递中间结果，不编号，队列已满时直接丢弃（后续结果会覆盖）
+func (v *ViewerConnection) sendPartial(msg *domain.WebSocketMessage) {
+	select {
+	case v.SendChannel <- msg:
+	default:
+	}
+}
+
+// sendPriority 发送必须送达的编号消息（状态变化、历史回放、连接关闭前的最后一条），队列已满时丢弃最旧的消息
+func (v *ViewerConnection) sendPriority(msg *domain.WebSocketMessage) {
+	numbered := v.numbered(msg)
+	for {
+		select {
+		case v.SendChannel <- numbered:
+			return
+		default:
+		}
+		select {
+		case <-v.SendChannel:
+			v.recordDropped()
+		default:
+		}
+	}
+}
+
+// laggingFor 更新积压状态，返回发送队列持续积压过半的时长，调用方需持有活动锁
+func (v *ViewerConnection) laggingFor(now time.Time) time.Duration {
+	if len(v.SendChannel) < cap(v.SendChannel)/2 {
+		v.laggingSince = time.Time{}
+		return 0
+	}
+	if v.laggingSince.IsZero() {
+		v.laggingSince = now
+	}
+	return now.Sub(v.laggingSince)
+}
@@ -21,14 +21,17 @@ const (
 
 // ActivityToken 活动令牌实体
 type ActivityToken struct {
-	ID          string      `json:"id"`
-	ActivityID  string      `json:"activityId"`
-	Type        TokenType   `json:"type"`
-	Value       string      `json:"value"`
-	ExpiresAt   time.Time   `json:"expiresAt"`
-	MaxAudience *int        `json:"maxAudience,omitempty"`
-	CreatedAt   time.Time   `json:"createdAt"`
-	Status      TokenStatus `json:"status"`
+	ID             string      `json:"id"`
+	OrganizationID string      `json:"organizationId"`
+	ActivityID     string      `json:"activityId"`
+	Type           TokenType   `json:"type"`
+	Value          string      `json:"value"`
+	ExpiresAt      time.Time   `json:"expiresAt"`
+	MaxAudience    *int        `json:"maxAudience,omitempty"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	Status         TokenStatus `json:"status"`
+	// CurrentAudience 当前在线观众数（运行时统计，不持久化）
+	CurrentAudience int `json:"currentAudience"`
 }
 
 // GenerateViewerTokenRequest 观众令牌生成请求
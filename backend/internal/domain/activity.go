@@ -20,6 +20,7 @@ const (
 // Activity 活动实体
 type Activity struct {
 	ID              string         `json:"id"`
+	OrganizationID  string         `json:"organizationId"`
 	Title           string         `json:"title"`
 	Description     string         `json:"description"`
 	Speaker         string         `json:"speaker"`
@@ -30,8 +31,18 @@ type Activity struct {
 	CoverURL        string         `json:"coverUrl,omitempty"`
 	Status          ActivityStatus `json:"status"`
 	ViewerURL       string         `json:"viewerUrl,omitempty"` // 观众端访问链接
-	CreatedAt       time.Time      `json:"createdAt"`
-	UpdatedAt       time.Time      `json:"updatedAt"`
+	// InterimTranslationIntervalMs 中间识别结果的翻译间隔（毫秒），0 表示中间结果只推送原文
+	InterimTranslationIntervalMs int `json:"interimTranslationIntervalMs"`
+	// AutoPublish 草稿是否在开始时间前由调度器自动发布
+	AutoPublish bool `json:"autoPublish"`
+	// ScheduledEndTime 计划结束时间，到达后由调度器自动关闭
+	ScheduledEndTime *time.Time `json:"scheduledEndTime,omitempty"`
+	// IdleCloseMinutes 演讲者连续无音频多少分钟后自动关闭，0 表示不启用
+	IdleCloseMinutes int `json:"idleCloseMinutes"`
+	// LastAudioAt 最近一次收到演讲者音频的时间（只读，约 30 秒精度）
+	LastAudioAt *time.Time `json:"lastAudioAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
 }
 
 // Validate 验证活动数据
@@ -54,6 +65,12 @@ func (a *Activity) Validate() error {
 	if a.StartTime.IsZero() {
 		return errors.New("开始时间不能为空")
 	}
+	if a.ScheduledEndTime != nil && !a.ScheduledEndTime.After(a.StartTime) {
+		return errors.New("计划结束时间必须晚于开始时间")
+	}
+	if a.IdleCloseMinutes < 0 {
+		return errors.New("无音频自动关闭时长不能为负数")
+	}
 	return nil
 }
 
@@ -98,6 +115,14 @@ type CreateActivityRequest struct {
 	InputLanguage   string    `json:"inputLanguage" binding:"required"`
 	TargetLanguages []string  `json:"targetLanguages" binding:"required,min=1"`
 	CoverURL        string    `json:"coverUrl" binding:"omitempty,url"`
+	// InterimTranslationIntervalMs 中间结果翻译间隔（毫秒），不传或为 0 时不翻译中间结果
+	InterimTranslationIntervalMs int `json:"interimTranslationIntervalMs" binding:"omitempty,min=0,max=60000"`
+	// AutoPublish 是否在开始时间前自动发布
+	AutoPublish bool `json:"autoPublish"`
+	// ScheduledEndTime 计划结束时间，可选
+	ScheduledEndTime *time.Time `json:"scheduledEndTime"`
+	// IdleCloseMinutes 无音频自动关闭时长（分钟），0 表示不启用
+	IdleCloseMinutes int `json:"idleCloseMinutes" binding:"omitempty,min=0,max=1440"`
 }
 
 // UpdateActivityRequest 更新活动请求
@@ -109,20 +134,56 @@ type UpdateActivityRequest struct {
 	InputLanguage   *string    `json:"inputLanguage"`
 	TargetLanguages []string   `json:"targetLanguages" binding:"omitempty,min=1"`
 	CoverURL        *string    `json:"coverUrl" binding:"omitempty,url"`
+	// InterimTranslationIntervalMs 中间结果翻译间隔（毫秒），0 表示关闭
+	InterimTranslationIntervalMs *int `json:"interimTranslationIntervalMs" binding:"omitempty,min=0,max=60000"`
+	// AutoPublish 是否在开始时间前自动发布
+	AutoPublish *bool `json:"autoPublish"`
+	// ScheduledEndTime 计划结束时间，传 clearScheduledEndTime 清除
+	ScheduledEndTime      *time.Time `json:"scheduledEndTime"`
+	ClearScheduledEndTime bool       `json:"clearScheduledEndTime"`
+	IdleCloseMinutes      *int       `json:"idleCloseMinutes" binding:"omitempty,min=0,max=1440"`
 }
 
 // ActivityRepository 活动仓储接口
 type ActivityRepository interface {
 	// Create 创建活动
 	Create(activity *Activity) error
-	// Update 更新活动
+	// Update 更新活动的可编辑字段，不修改状态与结束时间；
+	// 读取后状态已被修改时返回 ErrActivityStatusChanged
 	Update(activity *Activity) error
+	// UpdateStatus 仅当当前状态为 from 时写入状态、结束时间与更新时间，不修改其他字段；
+	// 状态已被修改时返回 ErrActivityStatusChanged
+	UpdateStatus(activity *Activity, from ActivityStatus) error
 	// Delete 删除活动
 	Delete(id string) error
-	// FindByID 根据 ID 查找活动
+	// FindByID 根据 ID 查找活动（不限组织，用于令牌校验等内部流程）
 	FindByID(id string) (*Activity, error)
-	// FindAll 查找所有活动
-	FindAll() ([]*Activity, error)
-	// FindByStatus 根据状态查找活动
-	FindByStatus(status ActivityStatus) ([]*Activity, error)
+	// FindByIDInOrganization 在组织内根据 ID 查找活动，不属于该组织时返回 ErrActivityNotFound
+	FindByIDInOrganization(orgID, id string) (*Activity, error)
+	// FindAll 查找组织内所有活动
+	FindAll(orgID string) ([]*Activity, error)
+	// FindByStatus 根据状态查找组织内的活动
+	FindByStatus(orgID string, status ActivityStatus) ([]*Activity, error)
+}
+
+// 状态变更原因
+const (
+	// TransitionReasonManual 管理员手动发布或关闭
+	TransitionReasonManual = "manual"
+	// TransitionReasonAutoPublish 调度器在开始时间前自动发布
+	TransitionReasonAutoPublish = "auto_publish"
+	// TransitionReasonScheduledEnd 到达计划结束时间自动关闭
+	TransitionReasonScheduledEnd = "scheduled_end"
+	// TransitionReasonIdle 演讲者长时间无音频自动关闭
+	TransitionReasonIdle = "idle_timeout"
+)
+
+// ActivityTransition 活动状态变更记录
+type ActivityTransition struct {
+	ID         string         `json:"id"`
+	ActivityID string         `json:"activityId"`
+	FromStatus ActivityStatus `json:"fromStatus"`
+	ToStatus   ActivityStatus `json:"toStatus"`
+	Reason     string         `json:"reason"`
+	CreatedAt  time.Time      `json:"createdAt"`
 }
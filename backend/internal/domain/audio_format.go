@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AudioEncoding 演讲者音频编码
+type AudioEncoding string
+
+const (
+	AudioEncodingLinear16 AudioEncoding = "LINEAR16"  // 16bit 小端 PCM
+	AudioEncodingMulaw    AudioEncoding = "MULAW"     // G.711 μ-law
+	AudioEncodingOggOpus  AudioEncoding = "OGG_OPUS"  // Ogg 封装的 Opus
+	AudioEncodingWebMOpus AudioEncoding = "WEBM_OPUS" // WebM 封装的 Opus（浏览器 MediaRecorder 默认输出）
+)
+
+const (
+	defaultAudioSampleRate = 16000
+	opusAudioSampleRate    = 48000
+	maxAudioChannels       = 8
+)
+
+// ErrUnsupportedAudioFormat 音频格式不受支持
+var ErrUnsupportedAudioFormat = errors.New("不支持的音频格式")
+
+// AudioFormat 演讲者声明的音频格式
+type AudioFormat struct {
+	Encoding        AudioEncoding `json:"encoding"`
+	SampleRateHertz int32         `json:"sampleRate"`
+	Channels        int32         `json:"channels"`
+}
+
+// DefaultAudioFormat 未声明格式时的默认值：16kHz 单声道 LINEAR16
+func DefaultAudioFormat() AudioFormat {
+	return AudioFormat{Encoding: AudioEncodingLinear16, SampleRateHertz: defaultAudioSampleRate, Channels: 1}
+}
+
+// Normalize 统一编码名称大小写并补全缺省的采样率与声道数
+func (f AudioFormat) Normalize() AudioFormat {
+	f.Encoding = AudioEncoding(strings.ToUpper(strings.TrimSpace(string(f.Encoding))))
+	if f.Encoding == "" {
+		f.Encoding = AudioEncodingLinear16
+	}
+	if f.SampleRateHertz == 0 {
+		f.SampleRateHertz = defaultAudioSampleRate
+		if f.Encoding.IsOpus() {
+			f.SampleRateHertz = opusAudioSampleRate
+		}
+	}
+	if f.Channels == 0 {
+		f.Channels = 1
+	}
+	return f
+}
+
+// Validate 校验格式
+func (f AudioFormat) Validate() error {
+	switch f.Encoding {
+	case AudioEncodingLinear16, AudioEncodingMulaw:
+		if f.SampleRateHertz < 8000 || f.SampleRateHertz > 48000 {
+			return fmt.Errorf("%w: 采样率需在 8000-48000 之间", ErrUnsupportedAudioFormat)
+		}
+	case AudioEncodingOggOpus, AudioEncodingWebMOpus:
+		switch f.SampleRateHertz {
+		case 8000, 12000, 16000, 24000, 48000:
+		default:
+			return fmt.Errorf("%w: Opus 采样率仅支持 8000/12000/16000/24000/48000", ErrUnsupportedAudioFormat)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAudioFormat, f.Encoding)
+	}
+	if f.Channels < 1 || f.Channels > maxAudioChannels {
+		return fmt.Errorf("%w: 声道数需在 1-%d 之间", ErrUnsupportedAudioFormat, maxAudioChannels)
+	}
+	return nil
+}
+
+// IsOpus 是否为 Opus 压缩格式
+func (e AudioEncoding) IsOpus() bool {
+	return e == AudioEncodingOggOpus || e == AudioEncodingWebMOpus
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// 二进制音频帧：1 字节版本号 + 4 字节大端序列号 + 音频数据
+const (
+	// AudioFrameVersion 当前音频帧格式版本
+	AudioFrameVersion byte = 1
+	// AudioFrameHeaderSize 音频帧头长度
+	AudioFrameHeaderSize = 5
+)
+
+// ErrInvalidAudioFrame 音频帧格式错误
+var ErrInvalidAudioFrame = errors.New("音频帧格式错误")
+
+// AudioFrame 演讲者通过二进制 WebSocket 帧发送的音频
+type AudioFrame struct {
+	Sequence uint32
+	Data     []byte
+}
+
+// ParseAudioFrame 解析二进制音频帧，Data 与 frame 共享底层数组
+func ParseAudioFrame(frame []byte) (*AudioFrame, error) {
+	if len(frame) < AudioFrameHeaderSize || frame[0] != AudioFrameVersion {
+		return nil, ErrInvalidAudioFrame
+	}
+	return &AudioFrame{
+		Sequence: binary.BigEndian.Uint32(frame[1:AudioFrameHeaderSize]),
+		Data:     frame[AudioFrameHeaderSize:],
+	}, nil
+}
+
+// EncodeAudioFrame 编码二进制音频帧
+func EncodeAudioFrame(sequence uint32, data []byte) []byte {
+	frame := make([]byte, AudioFrameHeaderSize+len(data))
+	frame[0] = AudioFrameVersion
+	binary.BigEndian.PutUint32(frame[1:AudioFrameHeaderSize], sequence)
+	copy(frame[AudioFrameHeaderSize:], data)
+	return frame
+}
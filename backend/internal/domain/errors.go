@@ -12,4 +12,40 @@ var (
 	ErrInvalidActivityStatus = errors.New("无效的活动状态")
 	// ErrActivityCannotBeModified 活动无法修改
 	ErrActivityCannotBeModified = errors.New("活动无法修改")
+	// ErrActivityStatusChanged 活动状态已被并发修改
+	ErrActivityStatusChanged = errors.New("活动状态已变更，请刷新后重试")
+	// ErrUnsupportedLanguage 活动未启用该语言
+	ErrUnsupportedLanguage = errors.New("活动未启用该语言")
+	// ErrAudienceLimitReached 观众人数已达令牌上限
+	ErrAudienceLimitReached = errors.New("观众人数已达上限")
+	// ErrViewerEntryInactive 观众入口未启用
+	ErrViewerEntryInactive = errors.New("观众入口未启用")
+	// ErrGlossaryTermNotFound 术语不存在
+	ErrGlossaryTermNotFound = errors.New("术语不存在")
+	// ErrGlossaryTermExists 术语已存在
+	ErrGlossaryTermExists = errors.New("术语已存在")
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("用户不存在")
+	// ErrUserExists 用户名已存在
+	ErrUserExists = errors.New("用户名已存在")
+	// ErrInvalidUserRole 无效的用户角色
+	ErrInvalidUserRole = errors.New("无效的用户角色")
+	// ErrLastOwner 不能删除或降级最后一名所有者
+	ErrLastOwner = errors.New("至少需要保留一名所有者")
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	// ErrRefreshTokenInvalid 刷新令牌无效、已过期或已注销
+	ErrRefreshTokenInvalid = errors.New("刷新令牌无效")
+	// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，会话已整体注销
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，会话已注销")
+	// ErrSessionNotFound 会话不存在
+	ErrSessionNotFound = errors.New("会话不存在")
+	// ErrOrganizationNotFound 组织不存在
+	ErrOrganizationNotFound = errors.New("组织不存在")
+	// ErrOrganizationExists 组织名称已存在
+	ErrOrganizationExists = errors.New("组织名称已存在")
+	// ErrRoleNotAllowed 无权授予或管理该角色
+	ErrRoleNotAllowed = errors.New("无权管理超级管理员")
+	// ErrLastSuperAdmin 不能删除或降级最后一名超级管理员
+	ErrLastSuperAdmin = errors.New("至少需要保留一名超级管理员")
 )
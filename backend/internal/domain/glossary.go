@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// GlossaryTerm 活动术语表条目
+type GlossaryTerm struct {
+	ID           string            `json:"id"`
+	ActivityID   string            `json:"activityId"`
+	Source       string            `json:"source"`       // 源语言术语，例如产品名、演讲者姓名
+	Translations map[string]string `json:"translations"` // 目标语言 -> 固定译法
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// CreateGlossaryTermRequest 新增术语请求
+type CreateGlossaryTermRequest struct {
+	Source       string            `json:"source" binding:"required,max=100"`
+	Translations map[string]string `json:"translations"`
+}
+
+// UpdateGlossaryTermRequest 更新术语请求，translations 传入时整体替换
+type UpdateGlossaryTermRequest struct {
+	Source       *string           `json:"source" binding:"omitempty,min=1,max=100"`
+	Translations map[string]string `json:"translations"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// DefaultOrganizationID 默认组织，升级前的活动、令牌与用户都归属该组织
+const DefaultOrganizationID = "00000000-0000-0000-0000-000000000001"
+
+// Organization 组织（租户），活动、令牌与管理后台用户按组织隔离
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateOrganizationRequest 创建组织请求
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// UpdateOrganizationRequest 更新组织请求
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// RefreshSession 刷新令牌记录
+// 每次刷新都会轮换出新的记录，同一次登录产生的记录共享 FamilyID（即会话 ID）
+type RefreshSession struct {
+	ID       string
+	FamilyID string
+	UserID   string
+	// OrganizationID 会话当前所在组织，超级管理员切换组织后与用户所属组织不同
+	OrganizationID string
+	TokenHash      string // 刷新令牌的 SHA-256 摘要，不保存明文
+	Device         string
+	UserAgent      string
+	IP             string
+	StartedAt      time.Time // 会话登录时间，轮换时沿用
+	CreatedAt      time.Time // 本条记录签发时间，即最近一次刷新时间
+	ExpiresAt      time.Time
+	RotatedAt      *time.Time // 已被新令牌替换的时间
+	RevokedAt      *time.Time // 注销时间
+}
+
+// SessionMetadata 登录或刷新时记录的客户端信息
+type SessionMetadata struct {
+	Device    string
+	UserAgent string
+	IP        string
+}
+
+// SessionInfo 当前用户的登录会话
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	StartedAt  time.Time `json:"startedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current"` // 是否为发起请求的会话
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Subtitle 字幕实体
 type Subtitle struct {
@@ -10,7 +13,9 @@ type Subtitle struct {
 	SourceLang   string            `json:"sourceLang"`   // 源语言
 	Translations map[string]string `json:"translations"` // 翻译结果 {语言代码: 翻译文本}
 	Confidence   float32           `json:"confidence"`   // 置信度
-	Timestamp    time.Time         `json:"timestamp"`    // 时间戳
+	StartedAt    time.Time         `json:"startedAt"`    // 句子开始时间（近似为首个识别结果到达时间）
+	Timestamp    time.Time         `json:"timestamp"`    // 时间戳（识别完成时间）
+	Partial      bool              `json:"partial"`      // 是否为中间识别结果
 }
 
 // SubtitleForLanguage 特定语言的字幕
@@ -22,3 +27,71 @@ type SubtitleForLanguage struct {
 	Text       string    `json:"text"`
 	Timestamp  time.Time `json:"timestamp"`
 }
+
+// TranscriptEntry 文字稿条目
+type TranscriptEntry struct {
+	ID           string            `json:"id"`
+	Original     string            `json:"original"`
+	SourceLang   string            `json:"sourceLang"`
+	TargetLang   string            `json:"targetLang,omitempty"`   // 指定 lang 时返回
+	Text         string            `json:"text,omitempty"`         // 指定 lang 时返回对应译文
+	Translations map[string]string `json:"translations,omitempty"` // 未指定 lang 时返回全部译文
+	Confidence   float32           `json:"confidence"`
+	Timestamp    time.Time         `json:"timestamp"`
+}
+
+// TranscriptPage 分页文字稿
+type TranscriptPage struct {
+	ActivityID string            `json:"activityId"`
+	Language   string            `json:"language,omitempty"`
+	Items      []TranscriptEntry `json:"items"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"pageSize"`
+}
+
+// PayloadFor 生成指定语言的字幕负载
+// 订阅语言与源语言一致时直接返回原文；没有对应翻译时返回 false
+func (s *Subtitle) PayloadFor(language string) (SubtitlePayload, bool) {
+	text, ok := s.Translations[language]
+	if !ok {
+		if !strings.EqualFold(language, s.SourceLang) {
+			return SubtitlePayload{}, false
+		}
+		text = s.Original
+	}
+
+	return SubtitlePayload{
+		ID:         s.ID,
+		Original:   s.Original,
+		SourceLang: s.SourceLang,
+		TargetLang: language,
+		Text:       text,
+		Timestamp:  s.Timestamp,
+		Confidence: s.Confidence,
+	}, true
+}
+
+// PayloadForLanguages 生成多语言订阅的字幕负载
+// TargetLang / Text 取第一个有文本的语言；订阅多个语言时 Translations 包含全部有文本的语言，
+// 一个语言都没有时返回 false
+func (s *Subtitle) PayloadForLanguages(languages []string) (SubtitlePayload, bool) {
+	var payload SubtitlePayload
+	found := false
+	for _, language := range languages {
+		item, ok := s.PayloadFor(language)
+		if !ok {
+			continue
+		}
+		if !found {
+			payload, found = item, true
+		}
+		if len(languages) > 1 {
+			if payload.Translations == nil {
+				payload.Translations = make(map[string]string, len(languages))
+			}
+			payload.Translations[language] = item.Text
+		}
+	}
+	return payload, found
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// UserRole 管理后台用户角色
+type UserRole string
+
+const (
+	// UserRoleSuperAdmin 超级管理员：管理组织，可切换到任意组织
+	UserRoleSuperAdmin UserRole = "super-admin"
+	// UserRoleOwner 所有者：管理本组织用户及全部活动
+	UserRoleOwner UserRole = "owner"
+	// UserRoleOrganizer 组织者：创建、编辑、删除活动及术语表
+	UserRoleOrganizer UserRole = "organizer"
+	// UserRoleOperator 现场操作员：发布/关闭活动，管理令牌与观众入口
+	UserRoleOperator UserRole = "operator"
+	// UserRoleReadOnly 只读：查看活动、文字稿与令牌
+	UserRoleReadOnly UserRole = "read-only"
+)
+
+// userRoleRank 角色权限等级，高等级包含低等级的全部权限
+var userRoleRank = map[UserRole]int{
+	UserRoleReadOnly:   1,
+	UserRoleOperator:   2,
+	UserRoleOrganizer:  3,
+	UserRoleOwner:      4,
+	UserRoleSuperAdmin: 5,
+}
+
+// Valid 是否为已知角色
+func (r UserRole) Valid() bool {
+	_, ok := userRoleRank[r]
+	return ok
+}
+
+// AtLeast 是否拥有 minimum 角色的权限
+func (r UserRole) AtLeast(minimum UserRole) bool {
+	rank, ok := userRoleRank[r]
+	return ok && rank >= userRoleRank[minimum]
+}
+
+// User 管理后台用户
+type User struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organizationId"`
+	Username       string    `json:"username"`
+	DisplayName    string    `json:"displayName"`
+	Role           UserRole  `json:"role"`
+	PasswordHash   string    `json:"-"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// CreateUserRequest 创建用户请求
+type CreateUserRequest struct {
+	Username    string   `json:"username" binding:"required,min=3,max=64"`
+	Password    string   `json:"password" binding:"required,min=8,max=72"`
+	DisplayName string   `json:"displayName" binding:"max=100"`
+	Role        UserRole `json:"role" binding:"required"`
+}
+
+// UpdateUserRequest 更新用户请求，未传入的字段保持不变
+type UpdateUserRequest struct {
+	DisplayName *string   `json:"displayName" binding:"omitempty,max=100"`
+	Role        *UserRole `json:"role"`
+	Password    *string   `json:"password" binding:"omitempty,min=8,max=72"`
+}
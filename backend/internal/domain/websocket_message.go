@@ -9,55 +9,79 @@ type MessageType string
 
 const (
 	// 通用消息类型
-	MessageTypeAuth    MessageType = "AUTH"    // 认证
-	MessageTypePing    MessageType = "PING"    // 心跳请求
-	MessageTypePong    MessageType = "PONG"    // 心跳响应
-	MessageTypeState   MessageType = "STATE"   // 状态消息
-	MessageTypeError   MessageType = "ERROR"   // 错误消息
+	MessageTypeAuth  MessageType = "AUTH"  // 认证
+	MessageTypePing  MessageType = "PING"  // 心跳请求
+	MessageTypePong  MessageType = "PONG"  // 心跳响应
+	MessageTypeState MessageType = "STATE" // 状态消息
+	MessageTypeError MessageType = "ERROR" // 错误消息
 
 	// 演讲者端消息类型
-	MessageTypeAudio   MessageType = "AUDIO"   // 音频数据
-	MessageTypeControl MessageType = "CONTROL" // 控制消息
+	MessageTypeAudio    MessageType = "AUDIO"     // 音频数据
+	MessageTypeControl  MessageType = "CONTROL"   // 控制消息
+	MessageTypeAudioGap MessageType = "AUDIO_GAP" // 音频序列缺失或乱序
+	MessageTypeAck      MessageType = "ACK"       // 已接收的音频序列号，断线重连后从其后续传
 
 	// 观众端消息类型
-	MessageTypeSubtitle MessageType = "SUBTITLE" // 字幕消息
-	MessageTypeHistory  MessageType = "HISTORY"  // 历史字幕
+	MessageTypeSubtitle  MessageType = "SUBTITLE"  // 字幕消息
+	MessageTypePartial   MessageType = "PARTIAL"   // 中间识别结果（同一句子 ID 会被后续 PARTIAL/SUBTITLE 替换）
+	MessageTypeHistory   MessageType = "HISTORY"   // 历史字幕
+	MessageTypeSubscribe MessageType = "SUBSCRIBE" // 观众切换订阅语言
+	MessageTypeResend    MessageType = "RESEND"    // 观众发现序列号缺失后请求从历史补发
 )
 
 // WebSocketMessage WebSocket 消息基础结构
 type WebSocketMessage struct {
-	Type      MessageType     `json:"type"`
-	Payload   interface{}     `json:"payload,omitempty"`
-	Timestamp time.Time       `json:"timestamp"`
+	Type      MessageType `json:"type"`
+	Payload   interface{} `json:"payload,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	Seq       uint64      `json:"seq,omitempty"` // 观众消息序列号，逐条递增（PARTIAL 不编号），缺号表示有消息未送达
 }
 
 // AuthPayload 认证消息负载
 type AuthPayload struct {
-	Token      string `json:"token"`       // JWT Token
-	ActivityID string `json:"activityId"`  // 活动 ID
-	Language   string `json:"language"`    // 语言（演讲者：输入语种，观众：订阅语种）
+	Token      string   `json:"token"`               // JWT Token
+	ActivityID string   `json:"activityId"`          // 活动 ID
+	Language   string   `json:"language"`            // 语言（演讲者：输入语种，观众：首个订阅语种）
+	Languages  []string `json:"languages,omitempty"` // 观众订阅的全部语种
 }
 
-// AudioPayload 音频消息负载
+// AudioPayload 音频消息负载（JSON 兼容格式，推荐使用二进制帧，见 AudioFrame）
 type AudioPayload struct {
-	Chunk    string `json:"chunk"`    // Base64 编码的音频数据
-	Sequence int    `json:"sequence"` // 序列号
+	Chunk    string  `json:"chunk"`              // Base64 编码的音频数据
+	Sequence *uint32 `json:"sequence,omitempty"` // 序列号，缺省时不做连续性检查
+}
+
+// AudioGapPayload 音频序列异常通知
+type AudioGapPayload struct {
+	Expected   uint32 `json:"expected"`          // 期望的序列号
+	Received   uint32 `json:"received"`          // 实际收到的序列号
+	Missing    uint32 `json:"missing"`           // 本次缺失的块数，乱序时为 0
+	OutOfOrder bool   `json:"outOfOrder"`        // 是否为迟到或重复的块（已丢弃）
+	Dropped    bool   `json:"dropped,omitempty"` // 序列号正常但未能送入识别（缓冲已满或会话重建中），需要重发
+}
+
+// AckPayload 音频确认
+type AckPayload struct {
+	Sequence uint32 `json:"sequence"` // 最近送入识别的音频序列号
+	Resumed  bool   `json:"resumed"`  // 连接时的确认：是否接管了原会话，false 表示新会话，客户端应补发缓存的全部音频
 }
 
 // ControlPayload 控制消息负载
 type ControlPayload struct {
-	Action string `json:"action"` // 动作：START, STOP, PAUSE
+	Action string       `json:"action"`           // 动作：START, STOP, PAUSE, FORMAT
+	Format *AudioFormat `json:"format,omitempty"` // FORMAT 动作切换到的音频格式
 }
 
 // SubtitlePayload 字幕消息负载
 type SubtitlePayload struct {
-	ID         string    `json:"id"`         // 句子 ID
-	Original   string    `json:"original"`   // 原文
-	SourceLang string    `json:"sourceLang"` // 源语言
-	TargetLang string    `json:"targetLang"` // 目标语言
-	Text       string    `json:"text"`       // 翻译后的文本
-	Timestamp  time.Time `json:"timestamp"`  // 时间戳
-	Confidence float32   `json:"confidence"` // 置信度
+	ID           string            `json:"id"`                     // 句子 ID
+	Original     string            `json:"original"`               // 原文
+	SourceLang   string            `json:"sourceLang"`             // 源语言
+	TargetLang   string            `json:"targetLang"`             // 目标语言
+	Text         string            `json:"text"`                   // 翻译后的文本
+	Timestamp    time.Time         `json:"timestamp"`              // 时间戳
+	Confidence   float32           `json:"confidence"`             // 置信度
+	Translations map[string]string `json:"translations,omitempty"` // 订阅多个语言时的全部文本 {语言代码: 文本}
 }
 
 // StatePayload 状态消息负载
@@ -74,5 +98,18 @@ type ErrorPayload struct {
 
 // HistoryPayload 历史字幕负载
 type HistoryPayload struct {
-	Subtitles []SubtitlePayload `json:"subtitles"` // 字幕列表
+	Language  string            `json:"language,omitempty"`  // 首个订阅语言
+	Languages []string          `json:"languages,omitempty"` // 全部订阅语言
+	Subtitles []SubtitlePayload `json:"subtitles"`           // 字幕列表
+}
+
+// ResendPayload 观众请求补发字幕
+type ResendPayload struct {
+	After string `json:"after"` // 最后收到的句子 ID，补发其后的字幕；为空或已过期时补发最近的历史
+}
+
+// SubscribePayload 观众切换订阅语言，languages 非空时优先
+type SubscribePayload struct {
+	Language  string   `json:"language,omitempty"`  // 新的订阅语言
+	Languages []string `json:"languages,omitempty"` // 同时订阅的多个语言
 }
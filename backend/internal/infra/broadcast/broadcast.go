@@ -0,0 +1,57 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+)
+
+// Handler 处理订阅到的消息，同一订阅内按发布顺序串行调用
+type Handler func(payload []byte)
+
+// Backend 广播后端，负责在实例之间分发按主题（活动）划分的消息
+// 内存实现仅在进程内分发；Redis 实现通过 pub/sub 在多个实例间分发
+type Backend interface {
+	// Publish 向主题发布消息，所有实例上该主题的订阅者都会收到（包括本实例）
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe 订阅主题，返回取消订阅函数；返回时订阅已生效
+	Subscribe(ctx context.Context, topic string, handler Handler) (func(), error)
+	Close() error
+}
+
+// HistoryStore 多实例共享的字幕历史存储，按活动保存编码后的字幕（按追加顺序）
+// Redis 后端实现该接口，使未订阅活动的实例也能读取完整历史
+type HistoryStore interface {
+	// AppendHistory 追加一条记录，只保留最近 maxSize 条，ttl 内没有新记录时整体过期
+	AppendHistory(ctx context.Context, topic string, entry []byte, maxSize int, ttl time.Duration) error
+	// History 按追加顺序返回全部记录
+	History(ctx context.Context, topic string) ([][]byte, error)
+	// ClearHistory 删除主题的全部记录
+	ClearHistory(ctx context.Context, topic string) error
+}
+
+// LeaseStore 多实例共享的名额租约，按键（观众令牌）统计未过期的租约
+// 实例异常退出时未释放的租约到期后自动失效。Redis 后端实现该接口
+type LeaseStore interface {
+	// AcquireLease 占用或续期租约；租约不存在且未过期的租约数已达 limit（> 0）时返回 false
+	AcquireLease(ctx context.Context, key, leaseID string, limit int, ttl time.Duration) (bool, error)
+	// ReleaseLease 释放租约
+	ReleaseLease(ctx context.Context, key, leaseID string) error
+	// CountLeases 返回未过期的租约数
+	CountLeases(ctx context.Context, key string) (int, error)
+}
+
+// New 根据配置（BROADCAST_BACKEND）创建广播后端
+func New(ctx context.Context, cfg *config.Config) (Backend, error) {
+	switch name := strings.ToLower(strings.TrimSpace(cfg.Broadcast.Backend)); name {
+	case "", "memory":
+		return NewMemoryBackend(), nil
+	case "redis":
+		return NewRedisBackend(ctx, cfg.Redis.URL)
+	default:
+		return nil, fmt.Errorf("unknown broadcast backend %q (available: memory, redis)", name)
+	}
+}
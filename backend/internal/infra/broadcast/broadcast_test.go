@@ -0,0 +1,182 @@
+package broadcast
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// testRedisURL 集成测试使用的本地 Redis，可通过 TEST_REDIS_URL 覆盖
+func testRedisURL() string {
+	if url := os.Getenv("TEST_REDIS_URL"); url != "" {
+		return url
+	}
+	return "redis://localhost:6379/15"
+}
+
+func TestMemoryBackend(t *testing.T) {
+	backend := NewMemoryBackend()
+	testBackend(t, backend, backend)
+}
+
+func TestRedisBackend(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	publisher, err := NewRedisBackend(ctx, testRedisURL())
+	if err != nil {
+		t.Skipf("redis not available: %v", err)
+	}
+	defer publisher.Close()
+
+	// 使用两个客户端模拟两个实例
+	subscriber, err := NewRedisBackend(ctx, testRedisURL())
+	if err != nil {
+		t.Fatalf("NewRedisBackend() error = %v", err)
+	}
+	defer subscriber.Close()
+
+	testBackend(t, publisher, subscriber)
+	testHistoryStore(t, publisher, subscriber)
+	testLeaseStore(t, publisher, subscriber)
+}
+
+// testLeaseStore 两个实例共享名额上限，过期租约不再计数
+func testLeaseStore(t *testing.T, first, second LeaseStore) {
+	t.Helper()
+	ctx := context.Background()
+	key := "lease-" + time.Now().Format("150405.000000000")
+
+	acquire := func(store LeaseStore, leaseID string, ttl time.Duration) bool {
+		t.Helper()
+		ok, err := store.AcquireLease(ctx, key, leaseID, 2, ttl)
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+		return ok
+	}
+
+	if !acquire(first, "a", time.Minute) || !acquire(second, "b", 200*time.Millisecond) {
+		t.Fatal("first two leases should be acquired")
+	}
+	if acquire(first, "c", time.Minute) {
+		t.Fatal("third lease should be rejected")
+	}
+	// 已有租约续期不受上限限制
+	if !acquire(second, "a", time.Minute) {
+		t.Fatal("existing lease should be renewed")
+	}
+	if count, err := second.CountLeases(ctx, key); err != nil || count != 2 {
+		t.Fatalf("CountLeases() = %d, %v; want 2", count, err)
+	}
+
+	// b 过期后名额空出
+	time.Sleep(300 * time.Millisecond)
+	if !acquire(first, "c", time.Minute) {
+		t.Fatal("lease should be acquired after expiry")
+	}
+	if err := first.ReleaseLease(ctx, key, "a"); err != nil {
+		t.Fatalf("ReleaseLease() error = %v", err)
+	}
+	if count, err := second.CountLeases(ctx, key); err != nil || count != 1 {
+		t.Fatalf("CountLeases() after release = %d, %v; want 1", count, err)
+	}
+}
+
+// testHistoryStore 一个实例写入的历史，另一个实例无需订阅即可读取
+func testHistoryStore(t *testing.T, writer, reader HistoryStore) {
+	t.Helper()
+	ctx := context.Background()
+	topic := "history-" + time.Now().Format("150405.000000000")
+	defer writer.ClearHistory(ctx, topic)
+
+	for _, entry := range []string{"a", "b", "c"} {
+		if err := writer.AppendHistory(ctx, topic, []byte(entry), 2, time.Minute); err != nil {
+			t.Fatalf("AppendHistory() error = %v", err)
+		}
+	}
+	entries, err := reader.History(ctx, topic)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(entries) != 2 || string(entries[0]) != "b" || string(entries[1]) != "c" {
+		t.Fatalf("History() = %q, want [b c]", entries)
+	}
+
+	if err := writer.ClearHistory(ctx, topic); err != nil {
+		t.Fatalf("ClearHistory() error = %v", err)
+	}
+	if entries, err := reader.History(ctx, topic); err != nil || len(entries) != 0 {
+		t.Fatalf("History() after clear = %q, %v", entries, err)
+	}
+}
+
+func testBackend(t *testing.T, publisher, subscriber Backend) {
+	t.Helper()
+	ctx := context.Background()
+	topic := "test-" + time.Now().Format("150405.000000000")
+
+	received := make(chan string, 10)
+	unsubscribe, err := subscriber.Subscribe(ctx, topic, func(payload []byte) {
+		received <- string(payload)
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	other := make(chan string, 10)
+	unsubscribeOther, err := subscriber.Subscribe(ctx, topic+"-other", func(payload []byte) {
+		other <- string(payload)
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer unsubscribeOther()
+
+	for _, msg := range []string{"one", "two", "three"} {
+		if err := publisher.Publish(ctx, topic, []byte(msg)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	for _, want := range []string{"one", "two", "three"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("received %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+	if len(other) != 0 {
+		t.Fatalf("unexpected message on other topic: %q", <-other)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if err := publisher.Publish(ctx, topic, []byte("after")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	select {
+	case got := <-received:
+		t.Fatalf("received %q after unsubscribe", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestMemoryBackendUnsubscribeInHandler(t *testing.T) {
+	backend := NewMemoryBackend()
+	var unsubscribe func()
+	calls := 0
+	unsubscribe, _ = backend.Subscribe(context.Background(), "topic", func([]byte) {
+		calls++
+		unsubscribe()
+	})
+
+	_ = backend.Publish(context.Background(), "topic", []byte("a"))
+	_ = backend.Publish(context.Background(), "topic", []byte("b"))
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
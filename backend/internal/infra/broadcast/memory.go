@@ -0,0 +1,66 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+)
+
+var _ Backend = (*MemoryBackend)(nil)
+
+// MemoryBackend 进程内广播后端，发布时同步调用本进程的订阅者
+type MemoryBackend struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	handlers map[string]map[uint64]Handler // topic -> 订阅 ID -> 处理函数
+}
+
+// NewMemoryBackend 创建进程内广播后端
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{handlers: make(map[string]map[uint64]Handler)}
+}
+
+// Publish 同步分发消息
+func (b *MemoryBackend) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[topic]))
+	for _, handler := range b.handlers[topic] {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	// 在锁外调用，允许处理函数内取消订阅
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+// Subscribe 订阅主题
+func (b *MemoryBackend) Subscribe(ctx context.Context, topic string, handler Handler) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	if b.handlers[topic] == nil {
+		b.handlers[topic] = make(map[uint64]Handler)
+	}
+	b.handlers[topic][id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.handlers[topic], id)
+			if len(b.handlers[topic]) == 0 {
+				delete(b.handlers, topic)
+			}
+		})
+	}, nil
+}
+
+// Close 实现接口
+func (b *MemoryBackend) Close() error {
+	return nil
+}
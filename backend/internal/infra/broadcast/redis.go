@@ -0,0 +1,174 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisChannelPrefix Redis 频道名前缀，避免与同库其他业务冲突
+	redisChannelPrefix = "orion:broadcast:"
+	// redisHistoryPrefix 字幕历史列表的键前缀
+	redisHistoryPrefix = "orion:history:"
+	// redisLeasePrefix 名额租约有序集合的键前缀，成员为租约 ID，分值为到期时间（毫秒）
+	redisLeasePrefix = "orion:lease:"
+)
+
+var (
+	_ Backend      = (*RedisBackend)(nil)
+	_ HistoryStore = (*RedisBackend)(nil)
+	_ LeaseStore   = (*RedisBackend)(nil)
+)
+
+// acquireLeaseScript 清理过期租约后占用或续期，检查与写入在同一脚本内原子执行
+// KEYS[1] 租约集合；ARGV: 当前时间、TTL（毫秒）、上限、租约 ID
+var acquireLeaseScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if not redis.call('ZSCORE', KEYS[1], ARGV[4]) then
+	if limit > 0 and redis.call('ZCARD', KEYS[1]) >= limit then
+		return 0
+	end
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[4])
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// RedisBackend 基于 Redis pub/sub 的广播后端，用于多实例部署
+// 每个订阅使用独立的 pub/sub 连接，消息在单个 goroutine 中按序分发；取消订阅后可能仍收到已缓冲的消息
+type RedisBackend struct {
+	client *redis.Client
+}
+
+// NewRedisBackend 创建 Redis 广播后端，redisURL 例如 redis://localhost:6379/0
+func NewRedisBackend(ctx context.Context, redisURL string) (*RedisBackend, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect redis: %w", err)
+	}
+	return &RedisBackend{client: client}, nil
+}
+
+// Publish 发布消息到主题对应的 Redis 频道
+func (b *RedisBackend) Publish(ctx context.Context, topic string, payload []byte) error {
+	if err := b.client.Publish(ctx, redisChannelPrefix+topic, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish to redis: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅主题，等待 Redis 确认订阅后返回
+func (b *RedisBackend) Subscribe(ctx context.Context, topic string, handler Handler) (func(), error) {
+	pubsub := b.client.Subscribe(ctx, redisChannelPrefix+topic)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe redis channel: %w", err)
+	}
+
+	go func() {
+		// 连接断开时 go-redis 会自动重连并恢复订阅，Close 后 channel 关闭
+		for msg := range pubsub.Channel() {
+			handler([]byte(msg.Payload))
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := pubsub.Close(); err != nil {
+				log.Printf("Warning: failed to close redis subscription %s: %v", topic, err)
+			}
+		})
+	}, nil
+}
+
+// AppendHistory 追加到主题的历史列表并裁剪、续期，三步在同一事务中执行
+func (b *RedisBackend) AppendHistory(ctx context.Context, topic string, entry []byte, maxSize int, ttl time.Duration) error {
+	key := redisHistoryPrefix + topic
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, entry)
+		if maxSize > 0 {
+			pipe.LTrim(ctx, key, int64(-maxSize), -1)
+		}
+		if ttl > 0 {
+			pipe.Expire(ctx, key, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append history to redis: %w", err)
+	}
+	return nil
+}
+
+// History 读取主题的历史列表
+func (b *RedisBackend) History(ctx context.Context, topic string) ([][]byte, error) {
+	values, err := b.client.LRange(ctx, redisHistoryPrefix+topic, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history from redis: %w", err)
+	}
+	entries := make([][]byte, len(values))
+	for i, value := range values {
+		entries[i] = []byte(value)
+	}
+	return entries, nil
+}
+
+// ClearHistory 删除主题的历史列表
+func (b *RedisBackend) ClearHistory(ctx context.Context, topic string) error {
+	if err := b.client.Del(ctx, redisHistoryPrefix+topic).Err(); err != nil {
+		return fmt.Errorf("failed to clear history in redis: %w", err)
+	}
+	return nil
+}
+
+// AcquireLease 占用或续期名额租约
+func (b *RedisBackend) AcquireLease(ctx context.Context, key, leaseID string, limit int, ttl time.Duration) (bool, error) {
+	now := time.Now().UnixMilli()
+	acquired, err := acquireLeaseScript.Run(ctx, b.client, []string{redisLeasePrefix + key},
+		now, ttl.Milliseconds(), limit, leaseID).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease in redis: %w", err)
+	}
+	return acquired == 1, nil
+}
+
+// ReleaseLease 释放名额租约
+func (b *RedisBackend) ReleaseLease(ctx context.Context, key, leaseID string) error {
+	if err := b.client.ZRem(ctx, redisLeasePrefix+key, leaseID).Err(); err != nil {
+		return fmt.Errorf("failed to release lease in redis: %w", err)
+	}
+	return nil
+}
+
+// CountLeases 统计未过期的名额租约
+func (b *RedisBackend) CountLeases(ctx context.Context, key string) (int, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	count, err := b.client.ZCount(ctx, redisLeasePrefix+key, "("+now, "+inf").Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count leases in redis: %w", err)
+	}
+	return int(count), nil
+}
+
+// Close 关闭 Redis 连接
+func (b *RedisBackend) Close() error {
+	return b.client.Close()
+}
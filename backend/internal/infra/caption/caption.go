@@ -0,0 +1,140 @@
+package caption
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format 字幕文件格式
+type Format string
+
+const (
+	FormatSRT  Format = "srt"
+	FormatVTT  Format = "vtt"
+	FormatTTML Format = "ttml"
+)
+
+// Cue 单条字幕时间轴
+type Cue struct {
+	Start time.Duration // 相对首个音频块的开始时间
+	End   time.Duration // 相对首个音频块的结束时间
+	Text  string
+}
+
+// ContentType 返回格式对应的 MIME 类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatSRT:
+		return "application/x-subrip; charset=utf-8"
+	case FormatVTT:
+		return "text/vtt; charset=utf-8"
+	case FormatTTML:
+		return "application/ttml+xml; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Write 按格式输出字幕文件，language 用于 TTML 的 xml:lang
+func Write(w io.Writer, format Format, language string, cues []Cue) error {
+	switch format {
+	case FormatSRT:
+		return WriteSRT(w, cues)
+	case FormatVTT:
+		return WriteVTT(w, cues)
+	case FormatTTML:
+		return WriteTTML(w, language, cues)
+	default:
+		return fmt.Errorf("unsupported caption format: %s", format)
+	}
+}
+
+// WriteSRT 输出 SubRip 字幕
+func WriteSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, cue := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatTimestamp(cue.Start, ","),
+			formatTimestamp(cue.End, ","),
+			normalizeText(cue.Text),
+		)
+	}
+	return bw.Flush()
+}
+
+// WriteVTT 输出 WebVTT 字幕
+func WriteVTT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n\n")
+	for i, cue := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatTimestamp(cue.Start, "."),
+			formatTimestamp(cue.End, "."),
+			escapeVTT(normalizeText(cue.Text)),
+		)
+	}
+	return bw.Flush()
+}
+
+// WriteTTML 输出 TTML（W3C Timed Text）字幕
+func WriteTTML(w io.Writer, language string, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml" xml:lang="`)
+	xml.EscapeText(bw, []byte(language))
+	bw.WriteString("\">\n  <body>\n    <div>\n")
+	for _, cue := range cues {
+		fmt.Fprintf(bw, `      <p begin="%s" end="%s">`,
+			formatTimestamp(cue.Start, "."),
+			formatTimestamp(cue.End, "."),
+		)
+		lines := strings.Split(normalizeText(cue.Text), "\n")
+		for i, line := range lines {
+			if i > 0 {
+				bw.WriteString("<br/>")
+			}
+			xml.EscapeText(bw, []byte(line))
+		}
+		bw.WriteString("</p>\n")
+	}
+	bw.WriteString("    </div>\n  </body>\n</tt>\n")
+	return bw.Flush()
+}
+
+// formatTimestamp 格式化为 HH:MM:SS<sep>mmm
+func formatTimestamp(d time.Duration, msSeparator string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	hours := ms / 3_600_000
+	ms %= 3_600_000
+	minutes := ms / 60_000
+	ms %= 60_000
+	seconds := ms / 1000
+	ms %= 1000
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, seconds, msSeparator, ms)
+}
+
+// normalizeText 统一换行并去掉空行（空行会截断 SRT/VTT 字幕块）
+func normalizeText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			kept = append(kept, trimmed)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func escapeVTT(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
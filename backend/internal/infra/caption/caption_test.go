@@ -0,0 +1,65 @@
+package caption
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sampleCues = []Cue{
+	{Start: 1500 * time.Millisecond, End: 4 * time.Second, Text: "Hello <everyone>"},
+	{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "Q&A\n\nnext"},
+}
+
+func TestWriteSRT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSRT, "en", sampleCues); err != nil {
+		t.Fatalf("write srt failed: %v", err)
+	}
+	want := "1\n00:00:01,500 --> 00:00:04,000\nHello <everyone>\n\n" +
+		"2\n01:02:03,045 --> 01:02:05,000\nQ&A\nnext\n\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected srt output:\n%s", buf.String())
+	}
+}
+
+func TestWriteVTT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatVTT, "en", sampleCues); err != nil {
+		t.Fatalf("write vtt failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "WEBVTT\n\n") {
+		t.Fatalf("missing WEBVTT header: %s", out)
+	}
+	if !strings.Contains(out, "00:00:01.500 --> 00:00:04.000\nHello &lt;everyone&gt;\n") {
+		t.Fatalf("unexpected vtt cue: %s", out)
+	}
+	if !strings.Contains(out, "Q&amp;A\nnext") {
+		t.Fatalf("expected escaped ampersand: %s", out)
+	}
+}
+
+func TestWriteTTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatTTML, "ja", sampleCues); err != nil {
+		t.Fatalf("write ttml failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `xml:lang="ja"`) {
+		t.Fatalf("missing language attribute: %s", out)
+	}
+	if !strings.Contains(out, `<p begin="00:00:01.500" end="00:00:04.000">Hello &lt;everyone&gt;</p>`) {
+		t.Fatalf("unexpected ttml cue: %s", out)
+	}
+	if !strings.Contains(out, "Q&amp;A<br/>next") {
+		t.Fatalf("expected line break in ttml: %s", out)
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, Format("ass"), "en", sampleCues); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -15,9 +16,15 @@ type Config struct {
 	Server        ServerConfig
 	Auth          AuthConfig
 	Google        GoogleConfig
+	Speech        SpeechConfig
+	Translation   TranslationConfig
 	Redis         RedisConfig
+	Broadcast     BroadcastConfig
 	Cache         CacheConfig
 	Database      DatabaseConfig
+	QRCode        QRCodeConfig
+	Scheduler     SchedulerConfig
+	Speaker       SpeakerConfig
 	ViewerBaseURL string
 }
 
@@ -26,15 +33,18 @@ type ServerConfig struct {
 	Port           int
 	Env            string
 	AllowedOrigins []string
+	MetricsEnabled bool   // 是否开放 /metrics 供 Prometheus 抓取
+	MetricsAddr    string // /metrics 的独立监听地址，不与业务端口共用
 }
 
 // AuthConfig 认证配置
 type AuthConfig struct {
-	JWTSecretPath   string
-	AdminUsername   string
-	AdminPassword   string
-	AccessTokenTTL  time.Duration
-	RefreshTokenTTL time.Duration
+	JWTSecretPath           string
+	JWTVerificationKeyPaths []string // 轮换期间仍需验证的旧密钥文件
+	AdminUsername           string
+	AdminPassword           string
+	AccessTokenTTL          time.Duration
+	RefreshTokenTTL         time.Duration
 }
 
 // GoogleConfig Google API 配置
@@ -45,17 +55,66 @@ type GoogleConfig struct {
 	TranslateAPIKey string // Translation API Key
 }
 
+// SpeechConfig 语音识别引擎配置
+type SpeechConfig struct {
+	Provider            string        // google / mock / vosk / whispercpp
+	VoskServerURL       string        // vosk-server WebSocket 地址
+	WhisperCppServerURL string        // whisper.cpp server HTTP 地址
+	WhisperCppSegment   time.Duration // whisper.cpp 单个片段最长时长
+}
+
+// TranslationConfig 机器翻译引擎配置
+type TranslationConfig struct {
+	Provider             string            // 默认引擎：google / mock / deepl / libretranslate / llm
+	ProviderDefaulted    bool              // 未设置 MT_PROVIDER，默认引擎由 Key 推断
+	Routes               map[string]string // 目标语言 -> 引擎，例如 ja -> deepl
+	DeepLAPIURL          string
+	DeepLAPIKey          string
+	LibreTranslateURL    string
+	LibreTranslateAPIKey string
+	LLMAPIURL            string // OpenAI 兼容 chat-completion 接口地址
+	LLMAPIKey            string
+	LLMModel             string
+}
+
 // RedisConfig Redis 配置
 type RedisConfig struct {
 	URL string
 }
 
+// BroadcastConfig 字幕广播配置
+type BroadcastConfig struct {
+	Backend         string        // 广播后端：memory（单实例）/ redis（多实例，使用 REDIS_URL）
+	ViewerLagWindow time.Duration // 观众发送队列持续积压超过该时长即被断开并要求重新同步，0 表示不断开
+	IdleRelease     time.Duration // 本实例没有演讲者的活动在最后一位观众离开后保留订阅的时长，0 表示立即释放
+}
+
 // CacheConfig 缓存配置
 type CacheConfig struct {
 	HistoryTTL     string
+	HistorySize    int // 每个活动缓存的历史字幕条数
 	WSPingInterval string
 }
 
+// QRCodeConfig 观众入口二维码配置
+type QRCodeConfig struct {
+	Size          int    // 边长（像素）
+	RecoveryLevel string // 纠错等级：low / medium / high / highest，留空时有 logo 用 high，否则 medium
+	LogoPath      string // 可选，居中 logo 图片路径
+}
+
+// SchedulerConfig 活动自动发布 / 关闭调度配置
+type SchedulerConfig struct {
+	Enabled         bool
+	Interval        time.Duration // 调度间隔
+	PublishLeadTime time.Duration // 开始时间前多久自动发布草稿
+}
+
+// SpeakerConfig 演讲者推流配置
+type SpeakerConfig struct {
+	ReconnectGrace time.Duration // 演讲者断线后保留翻译会话与观众订阅的时长，0 表示立即结束
+}
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
 	URL             string
@@ -76,31 +135,79 @@ func Load() (*Config, error) {
 	dbMaxOpen := getEnvAsInt("DATABASE_MAX_OPEN_CONNS", 10)
 	dbMaxIdle := getEnvAsInt("DATABASE_MAX_IDLE_CONNS", 5)
 	dbConnLifetime := getEnvAsDuration("DATABASE_CONN_MAX_LIFETIME", 30*time.Minute)
+	sttAPIKey := getEnv("GOOGLE_STT_API_KEY", "")
+	translateAPIKey := getEnv("GOOGLE_TRANSLATE_API_KEY", "")
+
+	// 未指定识别引擎时保持原有行为：配置了 Google Key 使用 Google，否则使用 mock
+	sttProvider := strings.ToLower(getEnv("STT_PROVIDER", ""))
+	if sttProvider == "" {
+		sttProvider = "mock"
+		if sttAPIKey != "" {
+			sttProvider = "google"
+		}
+	}
+
+	// 未指定翻译引擎时：配置了 Google Key 使用 Google，否则使用 mock
+	mtProvider := strings.ToLower(getEnv("MT_PROVIDER", ""))
+	mtDefaulted := mtProvider == ""
+	if mtDefaulted {
+		mtProvider = "mock"
+		if translateAPIKey != "" {
+			mtProvider = "google"
+		}
+	}
 
 	return &Config{
 		Server: ServerConfig{
 			Port:           port,
 			Env:            env,
 			AllowedOrigins: allowedOrigins,
+			MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
+			MetricsAddr:    getEnv("METRICS_ADDR", ":9090"),
 		},
 		Auth: AuthConfig{
-			JWTSecretPath:   getEnv("JWT_SECRET_PATH", "./secrets/jwt_private.pem"),
-			AdminUsername:   getEnv("ADMIN_USERNAME", "admin"),
-			AdminPassword:   getEnv("ADMIN_PASSWORD", "admin123"),
-			AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
-			RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+			JWTSecretPath:           getEnv("JWT_SECRET_PATH", "./secrets/jwt_private.pem"),
+			JWTVerificationKeyPaths: getEnvAsStringSlice("JWT_VERIFICATION_KEY_PATHS", nil),
+			AdminUsername:           getEnv("ADMIN_USERNAME", "admin"),
+			AdminPassword:           getEnv("ADMIN_PASSWORD", "admin123"),
+			AccessTokenTTL:          getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:         getEnvAsDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
 		},
 		Google: GoogleConfig{
 			CredentialsPath: getEnv("GOOGLE_APPLICATION_CREDENTIALS", "./secrets/google-service-account.json"),
 			ProjectID:       getEnv("GOOGLE_PROJECT_ID", ""),
-			STTAPIKey:       getEnv("GOOGLE_STT_API_KEY", ""),
-			TranslateAPIKey: getEnv("GOOGLE_TRANSLATE_API_KEY", ""),
+			STTAPIKey:       sttAPIKey,
+			TranslateAPIKey: translateAPIKey,
+		},
+		Speech: SpeechConfig{
+			Provider:            sttProvider,
+			VoskServerURL:       getEnv("VOSK_SERVER_URL", "ws://localhost:2700"),
+			WhisperCppServerURL: getEnv("WHISPERCPP_SERVER_URL", "http://localhost:8081"),
+			WhisperCppSegment:   getEnvAsDuration("WHISPERCPP_MAX_SEGMENT", 8*time.Second),
+		},
+		Translation: TranslationConfig{
+			Provider:             mtProvider,
+			ProviderDefaulted:    mtDefaulted,
+			Routes:               getEnvAsMap("MT_ROUTES"),
+			DeepLAPIURL:          getEnv("DEEPL_API_URL", "https://api-free.deepl.com"),
+			DeepLAPIKey:          getEnv("DEEPL_API_KEY", ""),
+			LibreTranslateURL:    getEnv("LIBRETRANSLATE_URL", "http://localhost:5000"),
+			LibreTranslateAPIKey: getEnv("LIBRETRANSLATE_API_KEY", ""),
+			LLMAPIURL:            getEnv("LLM_API_URL", "https://api.openai.com/v1"),
+			LLMAPIKey:            getEnv("LLM_API_KEY", ""),
+			LLMModel:             getEnv("LLM_MODEL", "gpt-4o-mini"),
 		},
 		Redis: RedisConfig{
 			URL: getEnv("REDIS_URL", "redis://localhost:6379/0"),
 		},
+		Broadcast: BroadcastConfig{
+			Backend:         getEnv("BROADCAST_BACKEND", "memory"),
+			ViewerLagWindow: getEnvAsDuration("VIEWER_LAG_WINDOW", 15*time.Second),
+			IdleRelease:     getEnvAsDuration("BROADCAST_IDLE_RELEASE", 30*time.Second),
+		},
 		Cache: CacheConfig{
 			HistoryTTL:     getEnv("HISTORY_CACHE_TTL", "5m"),
+			HistorySize:    getEnvAsInt("HISTORY_CACHE_SIZE", 50),
 			WSPingInterval: getEnv("WS_PING_INTERVAL", "30s"),
 		},
 		Database: DatabaseConfig{
@@ -109,6 +216,19 @@ func Load() (*Config, error) {
 			MaxIdleConns:    dbMaxIdle,
 			ConnMaxLifetime: dbConnLifetime,
 		},
+		QRCode: QRCodeConfig{
+			Size:          getEnvAsInt("QR_SIZE", 512),
+			RecoveryLevel: getEnv("QR_RECOVERY_LEVEL", ""),
+			LogoPath:      getEnv("QR_LOGO_PATH", ""),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:         getEnvAsBool("SCHEDULER_ENABLED", true),
+			Interval:        getEnvAsDuration("SCHEDULER_INTERVAL", 30*time.Second),
+			PublishLeadTime: getEnvAsDuration("SCHEDULER_PUBLISH_LEAD_TIME", 10*time.Minute),
+		},
+		Speaker: SpeakerConfig{
+			ReconnectGrace: getEnvAsDuration("SPEAKER_RECONNECT_GRACE", 30*time.Second),
+		},
 		ViewerBaseURL: getEnv("VIEWER_BASE_URL", "http://localhost:3000"),
 	}, nil
 }
@@ -220,6 +340,20 @@ func getEnvAsStringSlice(key string, defaultValue []string) []string {
 	return result
 }
 
+// 工具函数：获取键值对环境变量（格式 key:value，逗号分隔）
+func getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getEnvAsStringSlice(key, nil) {
+		k, v, ok := strings.Cut(pair, ":")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		result[strings.ToLower(k)] = strings.ToLower(v)
+	}
+	return result
+}
+
 // 工具函数：获取整型环境变量
 func getEnvAsInt(key string, defaultValue int) int {
 	valueStr := getEnv(key, "")
@@ -229,6 +363,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// 工具函数：获取布尔环境变量
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // 工具函数：获取 Duration 环境变量
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := getEnv(key, "")
@@ -246,6 +389,16 @@ func (c *Config) Validate() error {
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid port: %d", c.Server.Port)
 	}
+	if c.Server.MetricsEnabled {
+		// 指标中含活动 ID，只在独立端口开放，避免随业务端口暴露到公网
+		_, port, err := net.SplitHostPort(c.Server.MetricsAddr)
+		if err != nil {
+			return fmt.Errorf("invalid METRICS_ADDR %q: %w", c.Server.MetricsAddr, err)
+		}
+		if port == strconv.Itoa(c.Server.Port) {
+			return fmt.Errorf("METRICS_ADDR 不能与 APP_PORT 使用同一端口")
+		}
+	}
 	if c.Auth.AdminUsername == "" || c.Auth.AdminPassword == "" {
 		return fmt.Errorf("admin credentials must be configured")
 	}
@@ -258,5 +411,9 @@ func (c *Config) Validate() error {
 	if c.Database.URL == "" {
 		return fmt.Errorf("DATABASE_URL 未配置")
 	}
+	// 真实识别配上默认 mock 翻译时，观众看到的是伪造译文，必须显式确认
+	if c.Speech.Provider != "mock" && c.Translation.Provider == "mock" && c.Translation.ProviderDefaulted {
+		return fmt.Errorf("STT_PROVIDER=%s 时未配置翻译引擎：请设置 MT_PROVIDER 或 GOOGLE_TRANSLATE_API_KEY，仅调试 mock 翻译时显式设置 MT_PROVIDER=mock", c.Speech.Provider)
+	}
 	return nil
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate_RealSTTRequiresTranslation(t *testing.T) {
+	newConfig := func(stt, mt string, defaulted bool) *Config {
+		return &Config{
+			Server:      ServerConfig{Port: 8080},
+			Auth:        AuthConfig{AdminUsername: "admin", AdminPassword: "admin123", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour},
+			Database:    DatabaseConfig{URL: "postgres://localhost/orion"},
+			Speech:      SpeechConfig{Provider: stt},
+			Translation: TranslationConfig{Provider: mt, ProviderDefaulted: defaulted},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{"mock stt with default mock mt", newConfig("mock", "mock", true), false},
+		{"real stt with default mock mt", newConfig("vosk", "mock", true), true},
+		{"real stt with explicit mock mt", newConfig("google", "mock", false), false},
+		{"real stt with real mt", newConfig("whispercpp", "deepl", false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "MT_PROVIDER=mock") {
+				t.Fatalf("Validate() error = %v, should mention MT_PROVIDER=mock", err)
+			}
+		})
+	}
+}
+
+func TestValidate_MetricsAddr(t *testing.T) {
+	newConfig := func(enabled bool, addr string) *Config {
+		return &Config{
+			Server:      ServerConfig{Port: 8080, MetricsEnabled: enabled, MetricsAddr: addr},
+			Auth:        AuthConfig{AdminUsername: "admin", AdminPassword: "admin123", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour},
+			Database:    DatabaseConfig{URL: "postgres://localhost/orion"},
+			Speech:      SpeechConfig{Provider: "mock"},
+			Translation: TranslationConfig{Provider: "mock"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{"separate port", newConfig(true, ":9090"), false},
+		{"loopback only", newConfig(true, "127.0.0.1:9090"), false},
+		{"same port as app", newConfig(true, ":8080"), true},
+		{"missing port", newConfig(true, "localhost"), true},
+		{"disabled", newConfig(false, ""), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
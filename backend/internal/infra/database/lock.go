@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LockKeyActivityScheduler 活动调度器使用的 advisory lock 键
+const LockKeyActivityScheduler int64 = 0x6f72696f6e01
+
+// unlockTimeout 释放 advisory lock 的超时时间
+const unlockTimeout = 5 * time.Second
+
+// AdvisoryLock 基于 PostgreSQL 会话级 advisory lock 的跨实例互斥锁
+// 锁绑定在单独的数据库连接上，实例异常退出时连接断开，锁随之释放
+type AdvisoryLock struct {
+	db  *sql.DB
+	key int64
+}
+
+// NewAdvisoryLock 创建 advisory lock
+func NewAdvisoryLock(db *sql.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryLock 尝试获取锁，已被其他实例持有时立即返回 acquired = false
+func (l *AdvisoryLock) TryLock(ctx context.Context) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get lock connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1);`, l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1);`, l.key); err != nil {
+			log.Printf("Warning: failed to release advisory lock %d: %v", l.key, err)
+			// 释放失败时丢弃连接，避免仍持有锁的连接回到连接池
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
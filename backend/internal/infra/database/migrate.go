@@ -25,6 +25,7 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 			updated_at TIMESTAMPTZ NOT NULL
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_activities_status ON activities (status);`,
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS interim_translation_interval_ms INT NOT NULL DEFAULT 0;`,
 		`CREATE TABLE IF NOT EXISTS activity_tokens (
 			id UUID PRIMARY KEY,
 			activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
@@ -45,6 +46,98 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 			status TEXT NOT NULL,
 			updated_at TIMESTAMPTZ NOT NULL
 		);`,
+		`CREATE TABLE IF NOT EXISTS subtitles (
+			id UUID PRIMARY KEY,
+			seq BIGSERIAL NOT NULL,
+			activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+			original TEXT NOT NULL,
+			source_lang TEXT NOT NULL,
+			confidence REAL NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_subtitles_activity ON subtitles (activity_id, created_at, seq);`,
+		`CREATE TABLE IF NOT EXISTS subtitle_translations (
+			subtitle_id UUID NOT NULL REFERENCES subtitles(id) ON DELETE CASCADE,
+			language TEXT NOT NULL,
+			text TEXT NOT NULL,
+			PRIMARY KEY (subtitle_id, language)
+		);`,
+		`ALTER TABLE subtitles ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;`,
+		`CREATE TABLE IF NOT EXISTS activity_audio_starts (
+			activity_id UUID PRIMARY KEY REFERENCES activities(id) ON DELETE CASCADE,
+			first_audio_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS glossary_terms (
+			id UUID PRIMARY KEY,
+			activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+			source TEXT NOT NULL,
+			translations JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_glossary_terms_source ON glossary_terms (activity_id, lower(source));`,
+		`CREATE TABLE IF NOT EXISTS users (
+			id UUID PRIMARY KEY,
+			username TEXT NOT NULL,
+			display_name TEXT NOT NULL DEFAULT '',
+			role TEXT NOT NULL,
+			password_hash TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (lower(username));`,
+		`CREATE TABLE IF NOT EXISTS refresh_sessions (
+			id UUID PRIMARY KEY,
+			family_id UUID NOT NULL,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash TEXT NOT NULL UNIQUE,
+			device TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			started_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			rotated_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_refresh_sessions_family ON refresh_sessions (family_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_refresh_sessions_user ON refresh_sessions (user_id, expires_at);`,
+		`CREATE TABLE IF NOT EXISTS organizations (
+			id UUID PRIMARY KEY,
+			name TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_name ON organizations (lower(name));`,
+		// 升级前的数据归入默认组织
+		`INSERT INTO organizations (id, name, created_at, updated_at)
+			VALUES ('00000000-0000-0000-0000-000000000001', 'Default', NOW(), NOW())
+			ON CONFLICT (id) DO NOTHING;`,
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL
+			DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_organization ON activities (organization_id, created_at);`,
+		`ALTER TABLE activity_tokens ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL
+			DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL
+			DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);`,
+		`CREATE INDEX IF NOT EXISTS idx_users_organization ON users (organization_id);`,
+		`ALTER TABLE refresh_sessions ADD COLUMN IF NOT EXISTS organization_id UUID NOT NULL
+			DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;`,
+		// 已有草稿默认不自动发布
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS auto_publish BOOLEAN NOT NULL DEFAULT FALSE;`,
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS scheduled_end_time TIMESTAMPTZ;`,
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS idle_close_minutes INTEGER NOT NULL DEFAULT 0;`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_status_start ON activities (status, start_time);`,
+		`ALTER TABLE activity_audio_starts ADD COLUMN IF NOT EXISTS last_audio_at TIMESTAMPTZ;`,
+		`CREATE TABLE IF NOT EXISTS activity_transitions (
+			id UUID PRIMARY KEY,
+			activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+			from_status TEXT NOT NULL,
+			to_status TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_activity_transitions_activity ON activity_transitions (activity_id, created_at);`,
 	}
 
 	for _, stmt := range statements {
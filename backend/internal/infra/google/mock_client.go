@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/hoshea/orion-backend/internal/infra/mt"
+	"github.com/hoshea/orion-backend/internal/infra/stt"
 )
 
 // MockSTTClient 用于本地/测试环境的语音识别模拟
@@ -20,12 +23,17 @@ func (c *MockSTTClient) Close() error {
 	return nil
 }
 
-// StreamingRecognize 模拟识别：每收到一个音频块输出一条文本
+// SupportsEncoding 模拟识别不解码音频，接受任意编码
+func (c *MockSTTClient) SupportsEncoding(encoding string) bool {
+	return true
+}
+
+// StreamingRecognize 模拟识别：每收到一个音频块先输出一条中间结果，再输出最终文本
 func (c *MockSTTClient) StreamingRecognize(
 	ctx context.Context,
 	audioStream <-chan []byte,
-	config StreamingRecognizeConfig,
-	results chan<- RecognitionResult,
+	config stt.StreamingRecognizeConfig,
+	results chan<- stt.RecognitionResult,
 ) error {
 	counter := 0
 	for {
@@ -42,7 +50,16 @@ func (c *MockSTTClient) StreamingRecognize(
 			counter++
 			text := fmt.Sprintf("模拟语音片段 %d（%s）", counter, time.Now().Format("15:04:05"))
 			select {
-			case results <- RecognitionResult{
+			case results <- stt.RecognitionResult{
+				Transcript: fmt.Sprintf("模拟语音片段 %d", counter),
+				IsFinal:    false,
+				Confidence: 0.5,
+			}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			select {
+			case results <- stt.RecognitionResult{
 				Transcript: text,
 				IsFinal:    true,
 				Confidence: 0.85,
@@ -73,20 +90,20 @@ func (c *MockTranslationClient) Translate(
 	text string,
 	sourceLang string,
 	targetLangs []string,
-) ([]TranslationResult, error) {
-	results := make([]TranslationResult, 0, len(targetLangs))
+) ([]mt.TranslationResult, error) {
+	results := make([]mt.TranslationResult, 0, len(targetLangs))
 	for _, lang := range targetLangs {
 		if lang == "" {
 			continue
 		}
 		if lang == sourceLang {
-			results = append(results, TranslationResult{
+			results = append(results, mt.TranslationResult{
 				Language: lang,
 				Text:     text,
 			})
 			continue
 		}
-		results = append(results, TranslationResult{
+		results = append(results, mt.TranslationResult{
 			Language: lang,
 			Text:     fmt.Sprintf("[%s] %s", strings.ToUpper(lang), text),
 		})
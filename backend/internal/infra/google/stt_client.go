@@ -4,12 +4,36 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	speech "cloud.google.com/go/speech/apiv1"
 	"cloud.google.com/go/speech/apiv1/speechpb"
 	"google.golang.org/api/option"
+
+	"github.com/hoshea/orion-backend/internal/infra/stt"
+)
+
+const (
+	// streamingLimit 单个流的最长持续时间，必须小于 Google 官方 5 分钟限制
+	streamingLimit = 4*time.Minute + 30*time.Second
+	// phraseHintBoost 短语提示权重，过高会增加误识别
+	phraseHintBoost = 10
 )
 
+var (
+	_ stt.Client            = (*STTClient)(nil)
+	_ stt.StreamLimiter     = (*STTClient)(nil)
+	_ stt.EncodingSupporter = (*STTClient)(nil)
+)
+
+// sttEncodings 可直接提交给 Google 的音频编码
+var sttEncodings = map[string]speechpb.RecognitionConfig_AudioEncoding{
+	stt.EncodingLinear16: speechpb.RecognitionConfig_LINEAR16,
+	stt.EncodingMulaw:    speechpb.RecognitionConfig_MULAW,
+	stt.EncodingOggOpus:  speechpb.RecognitionConfig_OGG_OPUS,
+	stt.EncodingWebMOpus: speechpb.RecognitionConfig_WEBM_OPUS,
+}
+
 // STTClient Google Speech-to-Text 客户端
 type STTClient struct {
 	client *speech.Client
@@ -34,18 +58,15 @@ func (c *STTClient) Close() error {
 	return c.client.Close()
 }
 
-// StreamingRecognizeConfig 流式识别配置
-type StreamingRecognizeConfig struct {
-	LanguageCode       string // 例如 "zh-CN", "en-US"
-	SampleRateHertz    int32  // 采样率，例如 16000
-	EnableAutomaticPunctuation bool   // 是否启用自动标点
+// MaxStreamDuration 实现 stt.StreamLimiter
+func (c *STTClient) MaxStreamDuration() time.Duration {
+	return streamingLimit
 }
 
-// RecognitionResult 识别结果
-type RecognitionResult struct {
-	Transcript string  // 识别的文本
-	IsFinal    bool    // 是否是最终结果
-	Confidence float32 // 置信度 (0-1)
+// SupportsEncoding 实现 stt.EncodingSupporter，Opus 等压缩格式直接提交给 Google 解码
+func (c *STTClient) SupportsEncoding(encoding string) bool {
+	_, ok := sttEncodings[encoding]
+	return ok
 }
 
 // StreamingRecognize 流式语音识别
@@ -55,24 +76,40 @@ type RecognitionResult struct {
 func (c *STTClient) StreamingRecognize(
 	ctx context.Context,
 	audioStream <-chan []byte,
-	config StreamingRecognizeConfig,
-	results chan<- RecognitionResult,
+	config stt.StreamingRecognizeConfig,
+	results chan<- stt.RecognitionResult,
 ) error {
+	encoding := speechpb.RecognitionConfig_LINEAR16
+	if config.Encoding != "" {
+		var ok bool
+		if encoding, ok = sttEncodings[config.Encoding]; !ok {
+			return fmt.Errorf("unsupported audio encoding %q", config.Encoding)
+		}
+	}
+
 	// 创建流式识别客户端
 	stream, err := c.client.StreamingRecognize(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create streaming recognize: %w", err)
 	}
 
+	// 术语表作为语音自适应短语提示
+	var speechContexts []*speechpb.SpeechContext
+	if len(config.PhraseHints) > 0 {
+		speechContexts = []*speechpb.SpeechContext{{Phrases: config.PhraseHints, Boost: phraseHintBoost}}
+	}
+
 	// 发送配置
 	if err := stream.Send(&speechpb.StreamingRecognizeRequest{
 		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
 			StreamingConfig: &speechpb.StreamingRecognitionConfig{
 				Config: &speechpb.RecognitionConfig{
-					Encoding:                   speechpb.RecognitionConfig_LINEAR16,
-					SampleRateHertz:           config.SampleRateHertz,
-					LanguageCode:              config.LanguageCode,
+					Encoding:                   encoding,
+					SampleRateHertz:            config.SampleRateHertz,
+					AudioChannelCount:          config.AudioChannelCount,
+					LanguageCode:               config.LanguageCode,
 					EnableAutomaticPunctuation: config.EnableAutomaticPunctuation,
+					SpeechContexts:             speechContexts,
 				},
 				InterimResults: true, // 启用中间结果
 			},
@@ -128,7 +165,7 @@ func (c *STTClient) StreamingRecognize(
 			if len(result.Alternatives) > 0 {
 				alt := result.Alternatives[0]
 
-				recognitionResult := RecognitionResult{
+				recognitionResult := stt.RecognitionResult{
 					Transcript: alt.Transcript,
 					IsFinal:    result.IsFinal,
 					Confidence: alt.Confidence,
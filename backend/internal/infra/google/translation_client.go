@@ -7,8 +7,12 @@ import (
 	"cloud.google.com/go/translate"
 	"golang.org/x/text/language"
 	"google.golang.org/api/option"
+
+	"github.com/hoshea/orion-backend/internal/infra/mt"
 )
 
+var _ mt.Translator = (*TranslationClient)(nil)
+
 // TranslationClient Google Translation API 客户端
 type TranslationClient struct {
 	client *translate.Client
@@ -33,19 +37,13 @@ func (c *TranslationClient) Close() error {
 	return c.client.Close()
 }
 
-// TranslationResult 翻译结果
-type TranslationResult struct {
-	Language string // 目标语言代码
-	Text     string // 翻译后的文本
-}
-
 // Translate 翻译文本到多个目标语言
 func (c *TranslationClient) Translate(
 	ctx context.Context,
 	text string,
 	sourceLang string,
 	targetLangs []string,
-) ([]TranslationResult, error) {
+) ([]mt.TranslationResult, error) {
 	if text == "" {
 		return nil, fmt.Errorf("text cannot be empty")
 	}
@@ -53,13 +51,13 @@ func (c *TranslationClient) Translate(
 		return nil, fmt.Errorf("target languages cannot be empty")
 	}
 
-	results := make([]TranslationResult, 0, len(targetLangs))
+	results := make([]mt.TranslationResult, 0, len(targetLangs))
 
 	// 遍历每个目标语言进行翻译
 	for _, targetLang := range targetLangs {
 		// 跳过与源语言相同的目标语言
 		if targetLang == sourceLang {
-			results = append(results, TranslationResult{
+			results = append(results, mt.TranslationResult{
 				Language: targetLang,
 				Text:     text, // 直接使用原文
 			})
@@ -93,7 +91,7 @@ func (c *TranslationClient) Translate(
 			return nil, fmt.Errorf("no translation result for %s", targetLang)
 		}
 
-		results = append(results, TranslationResult{
+		results = append(results, mt.TranslationResult{
 			Language: targetLang,
 			Text:     translations[0].Text,
 		})
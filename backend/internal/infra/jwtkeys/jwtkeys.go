@@ -0,0 +1,314 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minHMACSecretLength HS256 共享密钥的最短长度
+const minHMACSecretLength = 32
+
+// Key JWT 签名或验证密钥，kid 为 RFC 7638 JWK 指纹
+type Key struct {
+	ID        string
+	Algorithm string
+	signer    crypto.Signer // 私钥，仅验证用的公钥为 nil
+	public    crypto.PublicKey
+	secret    []byte // HS256 共享密钥
+}
+
+// CanSign 是否持有私钥（或共享密钥）
+func (k *Key) CanSign() bool {
+	return k.signer != nil || k.secret != nil
+}
+
+// Sign 对 JWT 的 header.payload 部分签名
+func (k *Key) Sign(data []byte) ([]byte, error) {
+	switch k.Algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case AlgorithmRS256:
+		if k.signer == nil {
+			return nil, errors.New("verification-only key cannot sign")
+		}
+		digest := sha256.Sum256(data)
+		return k.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgorithmEdDSA:
+		if k.signer == nil {
+			return nil, errors.New("verification-only key cannot sign")
+		}
+		return k.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+}
+
+// Verify 校验签名
+func (k *Key) Verify(data, signature []byte) bool {
+	switch k.Algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(data)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case AlgorithmRS256:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case AlgorithmEdDSA:
+		return ed25519.Verify(k.public.(ed25519.PublicKey), data, signature)
+	default:
+		return false
+	}
+}
+
+// JWK 公钥的 JWK 表示，对称密钥不对外公开
+func (k *Key) JWK() (JWK, bool) {
+	jwk, ok := publicJWK(k.public)
+	if !ok {
+		return JWK{}, false
+	}
+	jwk.KeyID = k.ID
+	jwk.Algorithm = k.Algorithm
+	jwk.Use = "sig"
+	return jwk, true
+}
+
+// JWK JSON Web Key（RFC 7517）
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet 当前签名密钥及全部可用于验证的密钥
+// 轮换时将新密钥设为签名密钥，旧密钥保留为验证密钥直到已签发的访问令牌全部过期
+type KeySet struct {
+	signing *Key
+	keys    []*Key
+}
+
+// Load 加载签名密钥文件及额外的验证密钥文件
+// 文件为 PEM 格式的 RSA / Ed25519 私钥或公钥；签名文件不是 PEM 时按 HS256 共享密钥处理（兼容旧配置）
+func Load(signingPath string, verificationPaths []string) (*KeySet, error) {
+	if strings.TrimSpace(signingPath) == "" {
+		return nil, errors.New("JWT_SECRET_PATH 未配置")
+	}
+
+	signing, err := loadKeyFile(signingPath, true)
+	if err != nil {
+		return nil, err
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("JWT 签名密钥 %s 不是私钥", signingPath)
+	}
+
+	set := &KeySet{signing: signing, keys: []*Key{signing}}
+	for _, path := range verificationPaths {
+		if strings.TrimSpace(path) == "" {
+			continue
+		}
+		key, err := loadKeyFile(path, false)
+		if err != nil {
+			return nil, err
+		}
+		if set.find(key.ID) != nil {
+			continue
+		}
+		set.keys = append(set.keys, key)
+	}
+	return set, nil
+}
+
+// Signing 当前签名密钥
+func (s *KeySet) Signing() *Key {
+	return s.signing
+}
+
+// VerificationKeys 返回可验证指定 kid 与算法的密钥
+// kid 为空时（旧版本签发的令牌）返回全部同算法密钥；算法必须与密钥一致，防止算法混淆攻击
+func (s *KeySet) VerificationKeys(kid, alg string) []*Key {
+	if kid != "" {
+		if key := s.find(kid); key != nil && key.Algorithm == alg {
+			return []*Key{key}
+		}
+		return nil
+	}
+
+	var result []*Key
+	for _, key := range s.keys {
+		if key.Algorithm == alg {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// JWKS 全部非对称密钥的公钥集合
+func (s *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func (s *KeySet) find(kid string) *Key {
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// loadKeyFile 读取密钥文件，allowSecret 为 true 时非 PEM 内容按 HS256 共享密钥处理
+func loadKeyFile(path string, allowSecret bool) (*Key, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 JWT 密钥失败: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		if !allowSecret {
+			return nil, fmt.Errorf("JWT 验证密钥 %s 不是 PEM 格式", path)
+		}
+		return newSecretKey(strings.TrimSpace(string(content)))
+	}
+
+	key, err := parsePEMKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("解析 JWT 密钥 %s 失败: %w", path, err)
+	}
+	return key, nil
+}
+
+func newSecretKey(secret string) (*Key, error) {
+	if secret == "" {
+		return nil, errors.New("JWT 私钥文件内容为空")
+	}
+	if len(secret) < minHMACSecretLength {
+		return nil, errors.New("JWT 私钥长度至少 32 个字符")
+	}
+
+	key := &Key{Algorithm: AlgorithmHS256, secret: []byte(secret)}
+	key.ID = thumbprint(map[string]string{
+		"k":   base64.RawURLEncoding.EncodeToString(key.secret),
+		"kty": "oct",
+	})
+	return key, nil
+}
+
+// parsePEMKey 解析 PKCS#1 / PKCS#8 私钥或 PKIX 公钥
+func parsePEMKey(block *pem.Block) (*Key, error) {
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(parsed)
+}
+
+// NewKey 根据 RSA / Ed25519 私钥或公钥创建密钥
+func NewKey(raw any) (*Key, error) {
+	key := &Key{}
+	switch k := raw.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.signer, key.public = AlgorithmRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.public = AlgorithmRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.signer, key.public = AlgorithmEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.public = AlgorithmEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (RSA or Ed25519 required)", raw)
+	}
+
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA key must be at least 2048 bits")
+	}
+
+	jwk, _ := publicJWK(key.public)
+	members := map[string]string{"kty": jwk.KeyType}
+	switch jwk.KeyType {
+	case "RSA":
+		members["n"], members["e"] = jwk.N, jwk.E
+	case "OKP":
+		members["crv"], members["x"] = jwk.Curve, jwk.X
+	}
+	key.ID = thumbprint(members)
+	return key, nil
+}
+
+func publicJWK(public crypto.PublicKey) (JWK, bool) {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(k),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// thumbprint 计算 RFC 7638 JWK 指纹：按字典序序列化必需成员后取 SHA-256
+func thumbprint(members map[string]string) string {
+	// encoding/json 对 map 按键排序输出，且不含空白，满足规范要求
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
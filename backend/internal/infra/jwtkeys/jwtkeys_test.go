@@ -0,0 +1,174 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad_SignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatalf("marshal ed25519 key: %v", err)
+	}
+	secretPath := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secretPath, []byte(strings.Repeat("s", 32)+"\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		alg  string
+	}{
+		{"RSA PKCS1", writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), AlgorithmRS256},
+		{"Ed25519 PKCS8", writePEM(t, dir, "ed.pem", "PRIVATE KEY", edDER), AlgorithmEdDSA},
+		{"HS256 secret", secretPath, AlgorithmHS256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Load(tt.path, nil)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			key := set.Signing()
+			if key.Algorithm != tt.alg || key.ID == "" {
+				t.Fatalf("unexpected key: alg=%s kid=%q", key.Algorithm, key.ID)
+			}
+
+			data := []byte("header.payload")
+			sig, err := key.Sign(data)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if !key.Verify(data, sig) {
+				t.Fatal("signature should verify")
+			}
+			if key.Verify([]byte("header.tampered"), sig) {
+				t.Fatal("tampered data should not verify")
+			}
+		})
+	}
+}
+
+func TestLoad_Rotation(t *testing.T) {
+	dir := t.TempDir()
+
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldDER, _ := x509.MarshalPKCS8PrivateKey(oldKey)
+	oldPublicDER, _ := x509.MarshalPKIXPublicKey(oldKey.Public())
+	newRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+
+	oldSet, err := Load(writePEM(t, dir, "old.pem", "PRIVATE KEY", oldDER), nil)
+	if err != nil {
+		t.Fatalf("Load(old) error = %v", err)
+	}
+	data := []byte("header.payload")
+	oldSig, _ := oldSet.Signing().Sign(data)
+
+	// 新密钥签名，旧公钥仅用于验证
+	set, err := Load(
+		writePEM(t, dir, "new.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(newRSA)),
+		[]string{writePEM(t, dir, "old.pub", "PUBLIC KEY", oldPublicDER), ""},
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if set.Signing().Algorithm != AlgorithmRS256 {
+		t.Fatalf("signing key should be the new RSA key, got %s", set.Signing().Algorithm)
+	}
+
+	oldID := oldSet.Signing().ID
+	keys := set.VerificationKeys(oldID, AlgorithmEdDSA)
+	if len(keys) != 1 || !keys[0].Verify(data, oldSig) {
+		t.Fatal("old key should still verify tokens it signed")
+	}
+	if keys[0].CanSign() {
+		t.Fatal("public key should not be able to sign")
+	}
+	if keys := set.VerificationKeys(oldID, AlgorithmHS256); len(keys) != 0 {
+		t.Fatal("kid with mismatched alg must not match")
+	}
+	if keys := set.VerificationKeys("unknown", AlgorithmEdDSA); len(keys) != 0 {
+		t.Fatal("unknown kid must not match")
+	}
+
+	jwks := set.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 public keys in JWKS, got %d", len(jwks.Keys))
+	}
+	if jwks.Keys[0].KeyType != "RSA" || jwks.Keys[0].N == "" || jwks.Keys[0].E != "AQAB" {
+		t.Fatalf("unexpected RSA JWK: %+v", jwks.Keys[0])
+	}
+	if jwks.Keys[1].KeyType != "OKP" || jwks.Keys[1].Curve != "Ed25519" || jwks.Keys[1].KeyID != oldID {
+		t.Fatalf("unexpected Ed25519 JWK: %+v", jwks.Keys[1])
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	shortSecret := filepath.Join(dir, "short")
+	_ = os.WriteFile(shortSecret, []byte("too-short"), 0o600)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	publicDER, _ := x509.MarshalPKIXPublicKey(edKey.Public())
+	publicOnly := writePEM(t, dir, "public.pem", "PUBLIC KEY", publicDER)
+	weakRSA, _ := rsa.GenerateKey(rand.Reader, 1024)
+	weak := writePEM(t, dir, "weak.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(weakRSA))
+
+	if _, err := Load("", nil); err == nil {
+		t.Error("empty path should fail")
+	}
+	if _, err := Load(shortSecret, nil); err == nil {
+		t.Error("short HS256 secret should fail")
+	}
+	if _, err := Load(publicOnly, nil); err == nil {
+		t.Error("public key cannot be the signing key")
+	}
+	if _, err := Load(weak, nil); err == nil {
+		t.Error("RSA keys below 2048 bits should fail")
+	}
+	if _, err := Load(publicOnly, []string{shortSecret}); err == nil {
+		t.Error("verification keys must be PEM")
+	}
+}
+
+func TestThumbprint_RFC7638(t *testing.T) {
+	// RFC 7638 第 3.1 节示例
+	members := map[string]string{
+		"kty": "RSA",
+		"e":   "AQAB",
+		"n":   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+	if got, want := thumbprint(members), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Fatalf("thumbprint = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "orion"
+
+// 识别流重建原因
+const (
+	RestartDurationLimit = "duration_limit" // 达到引擎的单流时长上限
+	RestartFormatChange  = "format_change"  // 演讲者切换音频格式
+	RestartError         = "error"          // 识别流异常
+)
+
+// WebSocket 发送失败原因
+const (
+	SendBufferFull = "buffer_full" // 发送缓冲已满，消息被丢弃
+	SendWriteError = "write_error" // 写入连接失败
+)
+
+// latencyBuckets 字幕链路延迟的直方图区间（秒）
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 3, 5, 10}
+
+var (
+	registry = prometheus.NewRegistry()
+
+	audioBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audio_bytes_total",
+		Help:      "演讲者上传的音频字节数",
+	})
+	sttRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stt_stream_restarts_total",
+		Help:      "识别流重建次数",
+	}, []string{"provider", "reason"})
+	broadcastLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "subtitle_broadcast_latency_seconds",
+		Help:      "识别出最终结果到字幕广播的耗时（含翻译）",
+		Buckets:   latencyBuckets,
+	}, []string{"provider", "language"})
+	translationLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "translation_latency_seconds",
+		Help:      "翻译请求耗时，一次请求包含多个目标语言时计入每个语言",
+		Buckets:   latencyBuckets,
+	}, []string{"provider", "language"})
+	translationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "translation_errors_total",
+		Help:      "翻译请求失败次数",
+	}, []string{"provider"})
+	subtitlesDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pipeline_subtitles_dropped_total",
+		Help:      "翻译管线字幕输出队列已满而丢弃的最终字幕数",
+	})
+	wsSendFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "websocket_send_failures_total",
+		Help:      "WebSocket 消息发送失败次数",
+	}, []string{"reason"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时，WebSocket / SSE 等长连接按连接时长计",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	sourcesMu   sync.RWMutex
+	pipeline    PipelineSource
+	broadcaster BroadcasterSource
+)
+
+// PipelineSource 提供翻译管线的实时状态
+type PipelineSource interface {
+	ActiveSessions() int
+}
+
+// BroadcasterSource 提供字幕广播器的实时状态
+type BroadcasterSource interface {
+	ActivityIDs() []string
+	GetViewersByLanguage(activityID string) map[string]int
+	DroppedMessages() uint64
+	EvictedViewers() uint64
+}
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		audioBytes,
+		sttRestarts,
+		broadcastLatency,
+		translationLatency,
+		translationErrors,
+		subtitlesDropped,
+		wsSendFailures,
+		httpDuration,
+		sourceCollector{},
+	)
+}
+
+// Handler 返回 Prometheus 抓取接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// SetPipeline 设置采集会话数的翻译管线，nil 表示未启用
+func SetPipeline(source PipelineSource) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	pipeline = source
+}
+
+// SetBroadcaster 设置采集观众数与丢弃计数的字幕广播器
+func SetBroadcaster(source BroadcasterSource) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	broadcaster = source
+}
+
+// AddAudioBytes 记录演讲者上传的音频字节数
+func AddAudioBytes(n int) {
+	audioBytes.Add(float64(n))
+}
+
+// IncSTTRestart 记录一次识别流重建
+func IncSTTRestart(provider, reason string) {
+	sttRestarts.WithLabelValues(provider, reason).Inc()
+}
+
+// ObserveBroadcastLatency 记录最终结果到广播的耗时，language 为源语言
+func ObserveBroadcastLatency(provider, language string, d time.Duration) {
+	broadcastLatency.WithLabelValues(provider, language).Observe(d.Seconds())
+}
+
+// ObserveTranslation 记录一次翻译请求的耗时，失败时只计入错误数
+func ObserveTranslation(provider string, languages []string, d time.Duration, err error) {
+	if err != nil {
+		translationErrors.WithLabelValues(provider).Inc()
+		return
+	}
+	for _, language := range languages {
+		translationLatency.WithLabelValues(provider, language).Observe(d.Seconds())
+	}
+}
+
+// IncSubtitleDropped 记录一条被翻译管线丢弃的最终字幕
+func IncSubtitleDropped() {
+	subtitlesDropped.Inc()
+}
+
+// IncWebSocketSendFailure 记录一次 WebSocket 发送失败
+func IncWebSocketSendFailure(reason string) {
+	wsSendFailures.WithLabelValues(reason).Inc()
+}
+
+// ObserveHTTPRequest 记录 HTTP 请求耗时，route 为路由模板（如 /api/v1/activities/:id）
+func ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+var (
+	activeSessionsDesc = prometheus.NewDesc(namespace+"_speaker_sessions_active",
+		"本实例进行中的翻译会话数", nil, nil)
+	viewersDesc = prometheus.NewDesc(namespace+"_viewers",
+		"本实例的在线观众数，订阅多个语言的观众计入每个语言", []string{"activity_id", "language"}, nil)
+	viewerDroppedDesc = prometheus.NewDesc(namespace+"_viewer_messages_dropped_total",
+		"观众发送队列已满而未送达的消息数", nil, nil)
+	viewerEvictedDesc = prometheus.NewDesc(namespace+"_viewers_evicted_total",
+		"因长时间积压被断开的观众数", nil, nil)
+)
+
+// sourceCollector 抓取时从翻译管线与广播器读取实时状态
+type sourceCollector struct{}
+
+func (sourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeSessionsDesc
+	ch <- viewersDesc
+	ch <- viewerDroppedDesc
+	ch <- viewerEvictedDesc
+}
+
+func (sourceCollector) Collect(ch chan<- prometheus.Metric) {
+	sourcesMu.RLock()
+	p, b := pipeline, broadcaster
+	sourcesMu.RUnlock()
+
+	if p != nil {
+		ch <- prometheus.MustNewConstMetric(activeSessionsDesc, prometheus.GaugeValue, float64(p.ActiveSessions()))
+	}
+	if b != nil {
+		for _, activityID := range b.ActivityIDs() {
+			for language, count := range b.GetViewersByLanguage(activityID) {
+				ch <- prometheus.MustNewConstMetric(viewersDesc, prometheus.GaugeValue, float64(count), activityID, language)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(viewerDroppedDesc, prometheus.CounterValue, float64(b.DroppedMessages()))
+		ch <- prometheus.MustNewConstMetric(viewerEvictedDesc, prometheus.CounterValue, float64(b.EvictedViewers()))
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeBroadcaster struct{}
+
+func (fakeBroadcaster) ActivityIDs() []string { return []string{"act-1"} }
+func (fakeBroadcaster) GetViewersByLanguage(string) map[string]int {
+	return map[string]int{"en": 2, "ja": 1}
+}
+func (fakeBroadcaster) DroppedMessages() uint64 { return 7 }
+func (fakeBroadcaster) EvictedViewers() uint64  { return 1 }
+
+type fakePipeline struct{}
+
+func (fakePipeline) ActiveSessions() int { return 3 }
+
+func TestHandler(t *testing.T) {
+	SetPipeline(fakePipeline{})
+	SetBroadcaster(fakeBroadcaster{})
+	defer SetPipeline(nil)
+	defer SetBroadcaster(nil)
+
+	AddAudioBytes(320)
+	IncSTTRestart("google", RestartDurationLimit)
+	ObserveTranslation("deepl", []string{"en", "ja"}, 200*time.Millisecond, nil)
+	ObserveHTTPRequest("GET", "/api/v1/activities/:id", 200, 10*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	output := string(body)
+
+	for _, want := range []string{
+		"orion_speaker_sessions_active 3",
+		`orion_viewers{activity_id="act-1",language="en"} 2`,
+		`orion_viewers{activity_id="act-1",language="ja"} 1`,
+		"orion_viewer_messages_dropped_total 7",
+		"orion_viewers_evicted_total 1",
+		"orion_audio_bytes_total 320",
+		`orion_stt_stream_restarts_total{provider="google",reason="duration_limit"} 1`,
+		`orion_translation_latency_seconds_count{language="ja",provider="deepl"} 1`,
+		`orion_http_request_duration_seconds_count{method="GET",route="/api/v1/activities/:id",status="200"} 1`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package mt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var _ Translator = (*DeepLClient)(nil)
+
+// DeepLClient DeepL 风格的 HTTP 翻译接口（POST /v2/translate）
+type DeepLClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewDeepLClient 创建 DeepL 客户端，baseURL 例如 https://api-free.deepl.com
+func NewDeepLClient(baseURL, apiKey string) (*DeepLClient, error) {
+	if apiKey == "" {
+		return nil, errors.New("DEEPL_API_KEY 未配置")
+	}
+	return &DeepLClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+// Close 实现接口
+func (c *DeepLClient) Close() error {
+	return nil
+}
+
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+// Translate 逐个目标语言调用 DeepL（单次请求只支持一个目标语言）
+func (c *DeepLClient) Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]TranslationResult, error) {
+	results := make([]TranslationResult, 0, len(targetLangs))
+	for _, targetLang := range targetLangs {
+		var resp deepLResponse
+		err := postJSON(ctx, c.httpClient, c.baseURL+"/v2/translate",
+			map[string]string{"Authorization": "DeepL-Auth-Key " + c.apiKey},
+			deepLRequest{
+				Text:       []string{text},
+				SourceLang: strings.ToUpper(baseLanguage(sourceLang)),
+				TargetLang: deepLTargetLanguage(targetLang),
+			}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("deepl: %w", err)
+		}
+		if len(resp.Translations) == 0 {
+			return nil, fmt.Errorf("deepl: no translation result for %s", targetLang)
+		}
+		results = append(results, TranslationResult{Language: targetLang, Text: resp.Translations[0].Text})
+	}
+	return results, nil
+}
+
+// deepLTargetLanguage 转换为 DeepL 目标语言代码，需要区分变体的语言补全默认变体
+func deepLTargetLanguage(code string) string {
+	switch strings.ToLower(code) {
+	case "en":
+		return "EN-US"
+	case "pt":
+		return "PT-BR"
+	case "zh", "zh-cn", "zh-hans", "zh-sg":
+		return "ZH-HANS"
+	case "zh-tw", "zh-hk", "zh-hant":
+		return "ZH-HANT"
+	}
+	lang, region, ok := strings.Cut(code, "-")
+	switch strings.ToLower(lang) {
+	case "en", "pt":
+		if ok {
+			return strings.ToUpper(lang + "-" + region)
+		}
+	}
+	return strings.ToUpper(lang)
+}
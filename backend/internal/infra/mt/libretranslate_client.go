@@ -0,0 +1,77 @@
+package mt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var _ Translator = (*LibreTranslateClient)(nil)
+
+// LibreTranslateClient LibreTranslate 兼容的本地翻译服务（POST /translate）
+type LibreTranslateClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewLibreTranslateClient 创建 LibreTranslate 客户端，apiKey 可为空
+func NewLibreTranslateClient(baseURL, apiKey string) (*LibreTranslateClient, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("LIBRETRANSLATE_URL 未配置")
+	}
+	return &LibreTranslateClient{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+// Close 实现接口
+func (c *LibreTranslateClient) Close() error {
+	return nil
+}
+
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText string `json:"translatedText"`
+}
+
+// Translate 逐个目标语言调用 LibreTranslate
+func (c *LibreTranslateClient) Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]TranslationResult, error) {
+	results := make([]TranslationResult, 0, len(targetLangs))
+	for _, targetLang := range targetLangs {
+		var resp libreTranslateResponse
+		err := postJSON(ctx, c.httpClient, c.baseURL+"/translate", nil, libreTranslateRequest{
+			Q:      text,
+			Source: libreTranslateLanguage(sourceLang),
+			Target: libreTranslateLanguage(targetLang),
+			Format: "text",
+			APIKey: c.apiKey,
+		}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("libretranslate: %w", err)
+		}
+		results = append(results, TranslationResult{Language: targetLang, Text: resp.TranslatedText})
+	}
+	return results, nil
+}
+
+// libreTranslateLanguage LibreTranslate 使用主语言代码，繁体中文为 zt
+func libreTranslateLanguage(code string) string {
+	switch strings.ToLower(code) {
+	case "zh-tw", "zh-hk", "zh-hant":
+		return "zt"
+	}
+	return baseLanguage(code)
+}
@@ -0,0 +1,93 @@
+package mt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var _ Translator = (*LLMClient)(nil)
+
+// LLMClient 基于 chat-completion 接口（OpenAI 兼容，POST /chat/completions）的翻译客户端
+type LLMClient struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewLLMClient 创建 LLM 翻译客户端，baseURL 例如 https://api.openai.com/v1，本地服务 apiKey 可为空
+func NewLLMClient(baseURL, apiKey, model string) (*LLMClient, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("LLM_API_URL 未配置")
+	}
+	if model == "" {
+		return nil, errors.New("LLM_MODEL 未配置")
+	}
+	return &LLMClient{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+// Close 实现接口
+func (c *LLMClient) Close() error {
+	return nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Translate 逐个目标语言请求模型翻译
+func (c *LLMClient) Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]TranslationResult, error) {
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+
+	results := make([]TranslationResult, 0, len(targetLangs))
+	for _, targetLang := range targetLangs {
+		var resp chatCompletionResponse
+		err := postJSON(ctx, c.httpClient, c.baseURL+"/chat/completions", headers, chatCompletionRequest{
+			Model: c.model,
+			Messages: []chatMessage{
+				{
+					Role: "system",
+					Content: fmt.Sprintf("You are a professional simultaneous interpreter. Translate the user's text from %s to %s. "+
+						"Reply with the translation only, without quotes, notes or explanations.", sourceLang, targetLang),
+				},
+				{Role: "user", Content: text},
+			},
+		}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("llm: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("llm: no translation result for %s", targetLang)
+		}
+		results = append(results, TranslationResult{
+			Language: targetLang,
+			Text:     strings.TrimSpace(resp.Choices[0].Message.Content),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,24 @@
+package mt
+
+import (
+	"context"
+	"strings"
+)
+
+// Translator 机器翻译客户端
+type Translator interface {
+	Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]TranslationResult, error)
+	Close() error
+}
+
+// TranslationResult 翻译结果
+type TranslationResult struct {
+	Language string // 目标语言代码
+	Text     string // 翻译后的文本
+}
+
+// baseLanguage 返回 BCP-47 语言代码的主语言部分，例如 zh-CN -> zh
+func baseLanguage(code string) string {
+	lang, _, _ := strings.Cut(strings.TrimSpace(code), "-")
+	return strings.ToLower(lang)
+}
@@ -0,0 +1,199 @@
+package mt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+)
+
+func TestDeepLClientTranslate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/translate" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "DeepL-Auth-Key secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req deepLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.SourceLang != "ZH" {
+			t.Errorf("source_lang = %q", req.SourceLang)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"translations": []map[string]string{{"text": req.TargetLang + ":" + req.Text[0]}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewDeepLClient(server.URL, "secret")
+	if err != nil {
+		t.Fatalf("NewDeepLClient() error = %v", err)
+	}
+	results, err := client.Translate(context.Background(), "你好", "zh-CN", []string{"ja", "en", "zh-TW"})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	want := []string{"JA:你好", "EN-US:你好", "ZH-HANT:你好"}
+	for i, result := range results {
+		if result.Text != want[i] {
+			t.Errorf("results[%d] = %+v, want %s", i, result, want[i])
+		}
+	}
+}
+
+func TestLibreTranslateClientTranslate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req libreTranslateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/translate" || req.Source != "zh" || req.Format != "text" {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, req)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"translatedText": req.Target + ":" + req.Q})
+	}))
+	defer server.Close()
+
+	client, err := NewLibreTranslateClient(server.URL, "")
+	if err != nil {
+		t.Fatalf("NewLibreTranslateClient() error = %v", err)
+	}
+	results, err := client.Translate(context.Background(), "你好", "zh-CN", []string{"en-US", "zh-TW"})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Text != "en:你好" || results[1].Text != "zt:你好" || results[1].Language != "zh-TW" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestLLMClientTranslate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req chatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "test-model" || len(req.Messages) != 2 || !strings.Contains(req.Messages[0].Content, "to ja") {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": " こんにちは \n"}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewLLMClient(server.URL+"/v1", "key", "test-model")
+	if err != nil {
+		t.Fatalf("NewLLMClient() error = %v", err)
+	}
+	results, err := client.Translate(context.Background(), "你好", "zh-CN", []string{"ja"})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Text != "こんにちは" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestHTTPClientErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, _ := NewLibreTranslateClient(server.URL, "")
+	if _, err := client.Translate(context.Background(), "hi", "en", []string{"ja"}); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+type stubTranslator struct {
+	name  string
+	err   error
+	calls [][]string
+}
+
+func (s *stubTranslator) Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]TranslationResult, error) {
+	s.calls = append(s.calls, targetLangs)
+	if s.err != nil {
+		return nil, s.err
+	}
+	results := make([]TranslationResult, 0, len(targetLangs))
+	for _, lang := range targetLangs {
+		results = append(results, TranslationResult{Language: lang, Text: s.name + ":" + text})
+	}
+	return results, nil
+}
+
+func (s *stubTranslator) Close() error { return nil }
+
+func TestRouterTranslate(t *testing.T) {
+	google := &stubTranslator{name: "google"}
+	deepl := &stubTranslator{name: "deepl"}
+	router := NewRouter(google, map[string]Translator{"ja": deepl, "ZH-TW": deepl})
+
+	results, err := router.Translate(context.Background(), "hello", "en-US", []string{"ja-JP", "fr", "en-US", "zh-TW", "de"})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+
+	want := map[string]string{
+		"ja-JP": "deepl:hello",
+		"fr":    "google:hello",
+		"en-US": "hello",
+		"zh-TW": "deepl:hello",
+		"de":    "google:hello",
+	}
+	if len(results) != len(want) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	for i, lang := range []string{"ja-JP", "fr", "en-US", "zh-TW", "de"} {
+		if results[i].Language != lang || results[i].Text != want[lang] {
+			t.Errorf("results[%d] = %+v, want %s=%s", i, results[i], lang, want[lang])
+		}
+	}
+	if len(deepl.calls) != 1 || len(google.calls) != 1 {
+		t.Errorf("expected one grouped call per provider, got deepl=%v google=%v", deepl.calls, google.calls)
+	}
+}
+
+func TestRouterFallbackToDefault(t *testing.T) {
+	google := &stubTranslator{name: "google"}
+	deepl := &stubTranslator{name: "deepl", err: errors.New("unavailable")}
+	router := NewRouter(google, map[string]Translator{"ja": deepl})
+
+	results, err := router.Translate(context.Background(), "hello", "en", []string{"ja"})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Text != "google:hello" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestRouterNoFallbackToMockDefault(t *testing.T) {
+	mock := &stubTranslator{name: "mock"}
+	deepl := &stubTranslator{name: "deepl", err: errors.New("unavailable")}
+	registry := NewRegistry()
+	registry.Register("mock", func(context.Context, *config.Config) (Translator, error) { return mock, nil })
+	registry.Register("deepl", func(context.Context, *config.Config) (Translator, error) { return deepl, nil })
+
+	cfg := &config.Config{Translation: config.TranslationConfig{Provider: "mock", Routes: map[string]string{"ja": "deepl"}}}
+	router, err := registry.NewRouter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	if results, err := router.Translate(context.Background(), "hello", "en", []string{"ja"}); err == nil {
+		t.Fatalf("Translate() = %+v, want error instead of mock fallback", results)
+	}
+	if len(mock.calls) != 0 {
+		t.Fatalf("mock default should not be called, got %v", mock.calls)
+	}
+}
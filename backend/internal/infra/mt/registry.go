@@ -0,0 +1,106 @@
+package mt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+)
+
+// Factory 根据配置创建翻译客户端
+type Factory func(ctx context.Context, cfg *config.Config) (Translator, error)
+
+// Registry 机器翻译引擎注册表，按名称（MT_PROVIDER / MT_ROUTES）选择实现
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry 创建空注册表
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register 注册引擎，重复注册会覆盖旧实现
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[normalizeName(name)] = factory
+}
+
+// New 创建指定名称的翻译客户端
+func (r *Registry) New(ctx context.Context, name string, cfg *config.Config) (Translator, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[normalizeName(name)]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown MT provider %q (available: %s)", name, strings.Join(r.Names(), ", "))
+	}
+	return factory(ctx, cfg)
+}
+
+// Names 返回已注册的引擎名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRouter 按配置创建默认引擎与按目标语言路由的引擎，同名引擎只创建一次
+func (r *Registry) NewRouter(ctx context.Context, cfg *config.Config) (*Router, error) {
+	instances := make(map[string]Translator)
+	build := func(name string) (Translator, error) {
+		name = normalizeName(name)
+		if translator, ok := instances[name]; ok {
+			return translator, nil
+		}
+		translator, err := r.New(ctx, name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		instances[name] = translator
+		return translator, nil
+	}
+	closeAll := func() {
+		for _, translator := range instances {
+			_ = translator.Close()
+		}
+	}
+
+	defaultTranslator, err := build(cfg.Translation.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string]Translator, len(cfg.Translation.Routes))
+	for language, name := range cfg.Translation.Routes {
+		translator, err := build(name)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to create MT provider for %s: %w", language, err)
+		}
+		routes[language] = translator
+	}
+
+	router := NewRouter(defaultTranslator, routes)
+	// mock 只生成伪造译文，不能顶替失败的真实引擎
+	router.fallback = normalizeName(cfg.Translation.Provider) != "mock"
+	router.names = make(map[Translator]string, len(instances))
+	for name, translator := range instances {
+		router.names[translator] = name
+	}
+	return router, nil
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
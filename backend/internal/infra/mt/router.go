@@ -0,0 +1,130 @@
+package mt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hoshea/orion-backend/internal/infra/metrics"
+)
+
+var _ Translator = (*Router)(nil)
+
+// Router 按目标语言将翻译请求分派给不同引擎，未配置路由的语言使用默认引擎
+type Router struct {
+	defaultTranslator Translator
+	routes            map[string]Translator // 小写语言代码 -> 引擎
+	names             map[Translator]string // 引擎 -> 名称，用于监控指标
+	fallback          bool                  // 路由引擎失败时是否回退到默认引擎
+}
+
+// NewRouter 创建翻译路由，routes 的键可以是完整代码（zh-TW）或主语言（ja）
+func NewRouter(defaultTranslator Translator, routes map[string]Translator) *Router {
+	normalized := make(map[string]Translator, len(routes))
+	for language, translator := range routes {
+		normalized[strings.ToLower(strings.TrimSpace(language))] = translator
+	}
+	return &Router{
+		defaultTranslator: defaultTranslator,
+		routes:            normalized,
+		fallback:          true,
+	}
+}
+
+// providerName 返回引擎名称，未命名的默认引擎记为 default
+func (r *Router) providerName(translator Translator) string {
+	if name, ok := r.names[translator]; ok {
+		return name
+	}
+	if translator == r.defaultTranslator {
+		return "default"
+	}
+	return "unknown"
+}
+
+// translateWith 调用单个引擎并记录耗时
+func (r *Router) translateWith(ctx context.Context, translator Translator, text, sourceLang string, targetLangs []string) ([]TranslationResult, error) {
+	start := time.Now()
+	results, err := translator.Translate(ctx, text, sourceLang, targetLangs)
+	metrics.ObserveTranslation(r.providerName(translator), targetLangs, time.Since(start), err)
+	return results, err
+}
+
+// Route 返回目标语言使用的引擎：先精确匹配，再按主语言匹配
+func (r *Router) Route(targetLang string) Translator {
+	if translator, ok := r.routes[strings.ToLower(targetLang)]; ok {
+		return translator
+	}
+	if translator, ok := r.routes[baseLanguage(targetLang)]; ok {
+		return translator
+	}
+	return r.defaultTranslator
+}
+
+// Translate 按路由分组翻译，结果顺序与 targetLangs 一致
+// 路由引擎失败时回退到默认引擎；默认引擎是 mock 时不回退，直接返回错误
+func (r *Router) Translate(ctx context.Context, text, sourceLang string, targetLangs []string) ([]TranslationResult, error) {
+	if text == "" {
+		return nil, errors.New("text cannot be empty")
+	}
+
+	var (
+		order  []Translator
+		groups = make(map[Translator][]string)
+		texts  = make(map[string]string, len(targetLangs))
+	)
+	for _, lang := range targetLangs {
+		if lang == "" {
+			continue
+		}
+		if strings.EqualFold(lang, sourceLang) {
+			texts[lang] = text
+			continue
+		}
+		translator := r.Route(lang)
+		if _, ok := groups[translator]; !ok {
+			order = append(order, translator)
+		}
+		groups[translator] = append(groups[translator], lang)
+	}
+
+	for _, translator := range order {
+		langs := groups[translator]
+		results, err := r.translateWith(ctx, translator, text, sourceLang, langs)
+		if err != nil && r.fallback && translator != r.defaultTranslator {
+			log.Printf("MT provider failed for %v, fallback to default: %v", langs, err)
+			results, err = r.translateWith(ctx, r.defaultTranslator, text, sourceLang, langs)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate to %v: %w", langs, err)
+		}
+		for _, result := range results {
+			texts[result.Language] = result.Text
+		}
+	}
+
+	results := make([]TranslationResult, 0, len(texts))
+	for _, lang := range targetLangs {
+		if translated, ok := texts[lang]; ok {
+			results = append(results, TranslationResult{Language: lang, Text: translated})
+		}
+	}
+	return results, nil
+}
+
+// Close 关闭全部引擎
+func (r *Router) Close() error {
+	closed := map[Translator]bool{r.defaultTranslator: true}
+	errs := []error{r.defaultTranslator.Close()}
+	for _, translator := range r.routes {
+		if closed[translator] {
+			continue
+		}
+		closed[translator] = true
+		errs = append(errs, translator.Close())
+	}
+	return errors.Join(errs...)
+}
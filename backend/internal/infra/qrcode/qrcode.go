@@ -0,0 +1,203 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // 支持 JPEG 格式 logo
+	"image/png"
+	"os"
+	"strings"
+
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	// DefaultSize 默认边长（像素）
+	DefaultSize = 512
+	// logoRatio logo 占二维码边长的比例，超过纠错能力会导致无法识别
+	logoRatio = 0.2
+)
+
+// Options 二维码生成配置
+type Options struct {
+	Size     int    // 边长（像素）
+	Recovery string // 纠错等级：low / medium / high / highest
+	LogoPath string // 可选，居中 logo 图片路径（PNG / JPEG）
+}
+
+// Generator 二维码生成器
+type Generator struct {
+	size     int
+	level    goqrcode.RecoveryLevel
+	logo     image.Image
+	logoPNG  []byte
+	logoSize image.Point
+}
+
+// NewGenerator 创建二维码生成器，配置了 logo 且未指定纠错等级时使用 high
+func NewGenerator(opts Options) (*Generator, error) {
+	g := &Generator{size: opts.Size}
+	if g.size <= 0 {
+		g.size = DefaultSize
+	}
+
+	recovery := strings.ToLower(strings.TrimSpace(opts.Recovery))
+	if recovery == "" && opts.LogoPath != "" {
+		recovery = "high"
+	}
+	level, err := parseRecoveryLevel(recovery)
+	if err != nil {
+		return nil, err
+	}
+	g.level = level
+
+	if opts.LogoPath != "" {
+		file, err := os.Open(opts.LogoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open qr logo: %w", err)
+		}
+		defer file.Close()
+
+		logo, _, err := image.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode qr logo: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, logo); err != nil {
+			return nil, fmt.Errorf("failed to encode qr logo: %w", err)
+		}
+		g.logo = logo
+		g.logoPNG = buf.Bytes()
+		g.logoSize = logo.Bounds().Size()
+	}
+	return g, nil
+}
+
+// Size 返回默认边长
+func (g *Generator) Size() int {
+	return g.size
+}
+
+// PNG 生成 PNG 图片，size <= 0 时使用默认边长
+func (g *Generator) PNG(content string, size int) ([]byte, error) {
+	if size <= 0 {
+		size = g.size
+	}
+	code, err := goqrcode.New(content, g.level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+
+	img := code.Image(size)
+	if g.logo != nil {
+		canvas := image.NewRGBA(img.Bounds())
+		draw.Draw(canvas, canvas.Bounds(), img, image.Point{}, draw.Src)
+		x, y, w, h := g.logoRect(canvas.Bounds().Dx())
+		drawScaled(canvas, image.Rect(x, y, x+w, y+h), g.logo)
+		img = canvas
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG 生成 SVG 矢量图，适合打印海报
+func (g *Generator) SVG(content string) ([]byte, error) {
+	code, err := goqrcode.New(content, g.level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		g.size, g.size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, modules, modules)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// 合并同一行连续的黑色模块
+			start := x
+			for x+1 < len(row) && row[x+1] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start+1, x-start+1)
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if g.logoPNG != nil {
+		x, y, w, h := g.logoRectF(float64(modules))
+		fmt.Fprintf(&buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="data:image/png;base64,%s"/>`,
+			x, y, w, h, base64.StdEncoding.EncodeToString(g.logoPNG))
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// SVGDataURL 生成 SVG data URL
+func (g *Generator) SVGDataURL(content string) (string, error) {
+	svg, err := g.SVG(content)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(svg), nil
+}
+
+// logoRect 计算 logo 在像素画布中的位置，保持 logo 宽高比
+func (g *Generator) logoRect(canvas int) (x, y, w, h int) {
+	fx, fy, fw, fh := g.logoRectF(float64(canvas))
+	return int(fx), int(fy), int(fw), int(fh)
+}
+
+func (g *Generator) logoRectF(canvas float64) (x, y, w, h float64) {
+	box := canvas * logoRatio
+	w, h = box, box
+	if g.logoSize.X > 0 && g.logoSize.Y > 0 {
+		if g.logoSize.X >= g.logoSize.Y {
+			h = box * float64(g.logoSize.Y) / float64(g.logoSize.X)
+		} else {
+			w = box * float64(g.logoSize.X) / float64(g.logoSize.Y)
+		}
+	}
+	return (canvas - w) / 2, (canvas - h) / 2, w, h
+}
+
+// drawScaled 以最近邻采样将 src 缩放绘制到 dst 的 rect 区域
+func drawScaled(dst *image.RGBA, rect image.Rectangle, src image.Image) {
+	scaled := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	sb := src.Bounds()
+	for y := 0; y < rect.Dy(); y++ {
+		sy := sb.Min.Y + y*sb.Dy()/rect.Dy()
+		for x := 0; x < rect.Dx(); x++ {
+			sx := sb.Min.X + x*sb.Dx()/rect.Dx()
+			scaled.Set(x, y, src.At(sx, sy))
+		}
+	}
+	draw.Draw(dst, rect, scaled, image.Point{}, draw.Over)
+}
+
+func parseRecoveryLevel(value string) (goqrcode.RecoveryLevel, error) {
+	switch value {
+	case "low", "l":
+		return goqrcode.Low, nil
+	case "", "medium", "m":
+		return goqrcode.Medium, nil
+	case "high", "q":
+		return goqrcode.High, nil
+	case "highest", "h":
+		return goqrcode.Highest, nil
+	default:
+		return goqrcode.Medium, fmt.Errorf("invalid QR recovery level %q (low/medium/high/highest)", value)
+	}
+}
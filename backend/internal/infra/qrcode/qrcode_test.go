@@ -0,0 +1,92 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratorPNG(t *testing.T) {
+	g, err := NewGenerator(Options{Size: 256})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	data, err := g.PNG("http://localhost:3000/activity/1?code=ABCDEF", 0)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if size := img.Bounds().Dx(); size != 256 {
+		t.Fatalf("size = %d, want 256", size)
+	}
+
+	data, err = g.PNG("http://localhost:3000/activity/1?code=ABCDEF", 1024)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, _ = png.Decode(bytes.NewReader(data))
+	if size := img.Bounds().Dx(); size != 1024 {
+		t.Fatalf("size = %d, want 1024", size)
+	}
+}
+
+func TestGeneratorSVGWithLogo(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 20; y++ {
+			logo.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	logoPath := filepath.Join(t.TempDir(), "logo.png")
+	file, err := os.Create(logoPath)
+	if err != nil {
+		t.Fatalf("create logo: %v", err)
+	}
+	if err := png.Encode(file, logo); err != nil {
+		t.Fatalf("encode logo: %v", err)
+	}
+	file.Close()
+
+	g, err := NewGenerator(Options{LogoPath: logoPath})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	svg, err := g.SVG("https://orion.example.com/activity/1")
+	if err != nil {
+		t.Fatalf("SVG() error = %v", err)
+	}
+	if !bytes.HasPrefix(svg, []byte("<svg")) || !bytes.Contains(svg, []byte(`<image `)) {
+		t.Fatalf("unexpected svg: %s", svg)
+	}
+
+	dataURL, err := g.SVGDataURL("https://orion.example.com/activity/1")
+	if err != nil || !strings.HasPrefix(dataURL, "data:image/svg+xml;base64,") {
+		t.Fatalf("SVGDataURL() error = %v", err)
+	}
+
+	// logo 居中绘制到 PNG 上
+	data, err := g.PNG("https://orion.example.com/activity/1", 200)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, _ := png.Decode(bytes.NewReader(data))
+	if r, g, b, _ := img.At(100, 100).RGBA(); r>>8 != 255 || g != 0 || b != 0 {
+		t.Fatalf("expected logo at center, got %v", img.At(100, 100))
+	}
+}
+
+func TestNewGeneratorInvalidRecovery(t *testing.T) {
+	if _, err := NewGenerator(Options{Recovery: "extreme"}); err == nil {
+		t.Fatal("expected error for invalid recovery level")
+	}
+}
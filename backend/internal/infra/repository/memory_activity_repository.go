@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/hoshea/orion-backend/internal/domain"
 )
@@ -33,16 +35,43 @@ func (r *MemoryActivityRepository) Create(activity *domain.Activity) error {
 	return nil
 }
 
-// Update 更新活动
+// Update 更新活动，保留已存储的状态与结束时间
 func (r *MemoryActivityRepository) Update(activity *domain.Activity) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.activities[activity.ID]; !exists {
+	stored, exists := r.activities[activity.ID]
+	if !exists {
 		return domain.ErrActivityNotFound
 	}
+	if stored.Status != activity.Status {
+		return domain.ErrActivityStatusChanged
+	}
 
-	r.activities[activity.ID] = activity
+	updated := copyActivity(activity)
+	updated.EndTime = stored.EndTime
+	r.activities[activity.ID] = updated
+	return nil
+}
+
+// UpdateStatus 条件更新活动状态
+func (r *MemoryActivityRepository) UpdateStatus(activity *domain.Activity, from domain.ActivityStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.activities[activity.ID]
+	if !exists {
+		return domain.ErrActivityNotFound
+	}
+	if stored.Status != from {
+		return domain.ErrActivityStatusChanged
+	}
+
+	updated := copyActivity(stored)
+	updated.Status = activity.Status
+	updated.EndTime = activity.EndTime
+	updated.UpdatedAt = activity.UpdatedAt
+	r.activities[activity.ID] = updated
 	return nil
 }
 
@@ -73,27 +102,42 @@ func (r *MemoryActivityRepository) FindByID(id string) (*domain.Activity, error)
 	return copyActivity(activity), nil
 }
 
-// FindAll 查找所有活动
-func (r *MemoryActivityRepository) FindAll() ([]*domain.Activity, error) {
+// FindByIDInOrganization 在组织内根据 ID 查找活动
+func (r *MemoryActivityRepository) FindByIDInOrganization(orgID, id string) (*domain.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activity, exists := r.activities[id]
+	if !exists || activity.OrganizationID != orgID {
+		return nil, domain.ErrActivityNotFound
+	}
+
+	return copyActivity(activity), nil
+}
+
+// FindAll 查找组织内所有活动
+func (r *MemoryActivityRepository) FindAll(orgID string) ([]*domain.Activity, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	activities := make([]*domain.Activity, 0, len(r.activities))
 	for _, activity := range r.activities {
-		activities = append(activities, copyActivity(activity))
+		if activity.OrganizationID == orgID {
+			activities = append(activities, copyActivity(activity))
+		}
 	}
 
 	return activities, nil
 }
 
-// FindByStatus 根据状态查找活动
-func (r *MemoryActivityRepository) FindByStatus(status domain.ActivityStatus) ([]*domain.Activity, error) {
+// FindByStatus 根据状态查找组织内的活动
+func (r *MemoryActivityRepository) FindByStatus(orgID string, status domain.ActivityStatus) ([]*domain.Activity, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	activities := make([]*domain.Activity, 0)
 	for _, activity := range r.activities {
-		if activity.Status == status {
+		if activity.OrganizationID == orgID && activity.Status == status {
 			activities = append(activities, copyActivity(activity))
 		}
 	}
@@ -109,6 +153,7 @@ func copyActivity(src *domain.Activity) *domain.Activity {
 
 	dst := &domain.Activity{
 		ID:              src.ID,
+		OrganizationID:  src.OrganizationID,
 		Title:           src.Title,
 		Description:     src.Description,
 		Speaker:         src.Speaker,
@@ -120,6 +165,10 @@ func copyActivity(src *domain.Activity) *domain.Activity {
 		ViewerURL:       src.ViewerURL,
 		CreatedAt:       src.CreatedAt,
 		UpdatedAt:       src.UpdatedAt,
+
+		InterimTranslationIntervalMs: src.InterimTranslationIntervalMs,
+		AutoPublish:                  src.AutoPublish,
+		IdleCloseMinutes:             src.IdleCloseMinutes,
 	}
 
 	copy(dst.TargetLanguages, src.TargetLanguages)
@@ -128,6 +177,42 @@ func copyActivity(src *domain.Activity) *domain.Activity {
 		endTime := *src.EndTime
 		dst.EndTime = &endTime
 	}
+	if src.ScheduledEndTime != nil {
+		scheduledEnd := *src.ScheduledEndTime
+		dst.ScheduledEndTime = &scheduledEnd
+	}
+	if src.LastAudioAt != nil {
+		lastAudio := *src.LastAudioAt
+		dst.LastAudioAt = &lastAudio
+	}
 
 	return dst
 }
+
+// FindAutoPublishDue 跨组织查找开启自动发布、开始时间不晚于 before 的草稿
+func (r *MemoryActivityRepository) FindAutoPublishDue(_ context.Context, before time.Time) ([]*domain.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activities := make([]*domain.Activity, 0)
+	for _, activity := range r.activities {
+		if activity.Status == domain.ActivityStatusDraft && activity.AutoPublish && !activity.StartTime.After(before) {
+			activities = append(activities, copyActivity(activity))
+		}
+	}
+	return activities, nil
+}
+
+// FindAutoCloseCandidates 跨组织查找设置了计划结束时间或无音频自动关闭的已发布活动
+func (r *MemoryActivityRepository) FindAutoCloseCandidates(_ context.Context) ([]*domain.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activities := make([]*domain.Activity, 0)
+	for _, activity := range r.activities {
+		if activity.Status == domain.ActivityStatusPublished && (activity.ScheduledEndTime != nil || activity.IdleCloseMinutes > 0) {
+			activities = append(activities, copyActivity(activity))
+		}
+	}
+	return activities, nil
+}
@@ -28,8 +28,8 @@ func (r *PostgresAccessRepository) CreateToken(ctx context.Context, token *domai
 		return fmt.Errorf("invalid token id: %w", err)
 	}
 	query := `INSERT INTO activity_tokens (
-		id, activity_id, type, value, expires_at, max_audience, created_at, status
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+		id, activity_id, type, value, expires_at, max_audience, created_at, status, organization_id
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
 
 	_, err := r.db.ExecContext(
 		ctx,
@@ -42,6 +42,7 @@ func (r *PostgresAccessRepository) CreateToken(ctx context.Context, token *domai
 		token.MaxAudience,
 		token.CreatedAt,
 		string(token.Status),
+		token.OrganizationID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert token: %w", err)
@@ -51,7 +52,7 @@ func (r *PostgresAccessRepository) CreateToken(ctx context.Context, token *domai
 
 // ListTokens 列出活动所有令牌
 func (r *PostgresAccessRepository) ListTokens(ctx context.Context, activityID string) ([]*domain.ActivityToken, error) {
-	query := `SELECT id, activity_id, type, value, expires_at, max_audience, created_at, status
+	query := `SELECT id, activity_id, type, value, expires_at, max_audience, created_at, status, organization_id
 		FROM activity_tokens
 		WHERE activity_id = $1
 		ORDER BY created_at DESC;`
@@ -75,7 +76,7 @@ func (r *PostgresAccessRepository) ListTokens(ctx context.Context, activityID st
 
 // FindToken 根据值查找令牌
 func (r *PostgresAccessRepository) FindTokenByID(ctx context.Context, id string) (*domain.ActivityToken, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, activity_id, type, value, expires_at, max_audience, created_at, status FROM activity_tokens WHERE id = $1;`, id)
+	row := r.db.QueryRowContext(ctx, `SELECT id, activity_id, type, value, expires_at, max_audience, created_at, status, organization_id FROM activity_tokens WHERE id = $1;`, id)
 	token, err := scanToken(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -87,7 +88,7 @@ func (r *PostgresAccessRepository) FindTokenByID(ctx context.Context, id string)
 }
 
 func (r *PostgresAccessRepository) FindToken(ctx context.Context, activityID string, tokenType domain.TokenType, value string) (*domain.ActivityToken, error) {
-	query := `SELECT id, activity_id, type, value, expires_at, max_audience, created_at, status
+	query := `SELECT id, activity_id, type, value, expires_at, max_audience, created_at, status, organization_id
 		FROM activity_tokens
 		WHERE activity_id = $1 AND type = $2 AND value = $3
 		LIMIT 1;`
@@ -182,8 +183,9 @@ func scanToken(scanner interface {
 		maxAudience sql.NullInt64
 		createdAt   time.Time
 		status      string
+		orgID       string
 	)
-	if err := scanner.Scan(&id, &activityID, &tokenType, &value, &expiresAt, &maxAudience, &createdAt, &status); err != nil {
+	if err := scanner.Scan(&id, &activityID, &tokenType, &value, &expiresAt, &maxAudience, &createdAt, &status, &orgID); err != nil {
 		return nil, fmt.Errorf("failed to scan token: %w", err)
 	}
 	var maxAudiencePtr *int
@@ -192,14 +194,15 @@ func scanToken(scanner interface {
 		maxAudiencePtr = &v
 	}
 	return &domain.ActivityToken{
-		ID:          id,
-		ActivityID:  activityID,
-		Type:        domain.TokenType(tokenType),
-		Value:       value,
-		ExpiresAt:   expiresAt,
-		MaxAudience: maxAudiencePtr,
-		CreatedAt:   createdAt,
-		Status:      domain.TokenStatus(status),
+		ID:             id,
+		OrganizationID: orgID,
+		ActivityID:     activityID,
+		Type:           domain.TokenType(tokenType),
+		Value:          value,
+		ExpiresAt:      expiresAt,
+		MaxAudience:    maxAudiencePtr,
+		CreatedAt:      createdAt,
+		Status:         domain.TokenStatus(status),
 	}, nil
 }
 
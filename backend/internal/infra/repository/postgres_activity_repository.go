@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,6 +13,15 @@ import (
 	"github.com/hoshea/orion-backend/internal/domain"
 )
 
+// activitySelect 活动查询的公共部分，最近音频时间来自 activity_audio_starts
+const activitySelect = `SELECT
+		a.id, a.title, a.description, a.speaker, a.start_time, a.end_time,
+		a.input_language, a.target_languages, a.cover_url, a.status,
+		a.viewer_url, a.created_at, a.updated_at, a.interim_translation_interval_ms, a.organization_id,
+		a.auto_publish, a.scheduled_end_time, a.idle_close_minutes, s.last_audio_at
+	FROM activities a
+	LEFT JOIN activity_audio_starts s ON s.activity_id = a.id`
+
 // PostgresActivityRepository PostgreSQL 实现的活动仓储
 type PostgresActivityRepository struct {
 	db *sql.DB
@@ -34,10 +45,14 @@ func (r *PostgresActivityRepository) Create(activity *domain.Activity) error {
 
 	query := `INSERT INTO activities (
 		id, title, description, speaker, start_time, end_time, input_language,
-		target_languages, cover_url, status, viewer_url, created_at, updated_at
+		target_languages, cover_url, status, viewer_url, created_at, updated_at,
+		interim_translation_interval_ms, organization_id,
+		auto_publish, scheduled_end_time, idle_close_minutes
 	) VALUES (
 		$1, $2, $3, $4, $5, $6, $7,
-		$8, $9, $10, $11, $12, $13
+		$8, $9, $10, $11, $12, $13,
+		$14, $15,
+		$16, $17, $18
 	);`
 
 	_, err = r.db.Exec(
@@ -55,6 +70,11 @@ func (r *PostgresActivityRepository) Create(activity *domain.Activity) error {
 		activity.ViewerURL,
 		activity.CreatedAt,
 		activity.UpdatedAt,
+		activity.InterimTranslationIntervalMs,
+		activity.OrganizationID,
+		activity.AutoPublish,
+		activity.ScheduledEndTime,
+		activity.IdleCloseMinutes,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert activity: %w", err)
@@ -62,7 +82,8 @@ func (r *PostgresActivityRepository) Create(activity *domain.Activity) error {
 	return nil
 }
 
-// Update 更新活动
+// Update 更新活动的可编辑字段，状态、结束时间由 UpdateStatus 修改
+// WHERE 中带上读取时的状态，编辑期间活动被发布或关闭时返回 ErrActivityStatusChanged
 func (r *PostgresActivityRepository) Update(activity *domain.Activity) error {
 	targetLanguages, err := json.Marshal(activity.TargetLanguages)
 	if err != nil {
@@ -70,33 +91,36 @@ func (r *PostgresActivityRepository) Update(activity *domain.Activity) error {
 	}
 
 	query := `UPDATE activities SET
-		title = $2,
-		description = $3,
-		speaker = $4,
-		start_time = $5,
-		end_time = $6,
+		title = $3,
+		description = $4,
+		speaker = $5,
+		start_time = $6,
 		input_language = $7,
 		target_languages = $8,
 		cover_url = $9,
-		status = $10,
-		viewer_url = $11,
-		updated_at = $12
-	WHERE id = $1;`
+		updated_at = $10,
+		interim_translation_interval_ms = $11,
+		auto_publish = $12,
+		scheduled_end_time = $13,
+		idle_close_minutes = $14
+	WHERE id = $1 AND status = $2;`
 
 	res, err := r.db.Exec(
 		query,
 		activity.ID,
+		activity.Status,
 		activity.Title,
 		activity.Description,
 		activity.Speaker,
 		activity.StartTime,
-		activity.EndTime,
 		activity.InputLanguage,
 		targetLanguages,
 		activity.CoverURL,
-		activity.Status,
-		activity.ViewerURL,
 		activity.UpdatedAt,
+		activity.InterimTranslationIntervalMs,
+		activity.AutoPublish,
+		activity.ScheduledEndTime,
+		activity.IdleCloseMinutes,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update activity: %w", err)
@@ -106,10 +130,49 @@ func (r *PostgresActivityRepository) Update(activity *domain.Activity) error {
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
+	if rows > 0 {
+		return nil
+	}
+	return r.statusChangedOrNotFound(activity.ID)
+}
+
+// statusChangedOrNotFound 条件更新未命中时区分活动不存在与状态已变化
+func (r *PostgresActivityRepository) statusChangedOrNotFound(id string) error {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM activities WHERE id = $1);`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check activity: %w", err)
+	}
+	if !exists {
 		return domain.ErrActivityNotFound
 	}
-	return nil
+	return domain.ErrActivityStatusChanged
+}
+
+// UpdateStatus 条件更新活动状态，WHERE 中带上原状态，避免覆盖并发的状态变更与其他字段的编辑
+func (r *PostgresActivityRepository) UpdateStatus(activity *domain.Activity, from domain.ActivityStatus) error {
+	res, err := r.db.Exec(`UPDATE activities SET
+		status = $3,
+		end_time = $4,
+		updated_at = $5
+	WHERE id = $1 AND status = $2;`,
+		activity.ID,
+		from,
+		activity.Status,
+		activity.EndTime,
+		activity.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update activity status: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		return nil
+	}
+	return r.statusChangedOrNotFound(activity.ID)
 }
 
 // Delete 删除活动
@@ -130,18 +193,32 @@ func (r *PostgresActivityRepository) Delete(id string) error {
 
 // FindByID 根据 ID 查找活动
 func (r *PostgresActivityRepository) FindByID(id string) (*domain.Activity, error) {
-	query := `SELECT
-		id, title, description, speaker, start_time, end_time,
-		input_language, target_languages, cover_url, status,
-		viewer_url, created_at, updated_at
-	FROM activities
-	WHERE id = $1;`
+	query := activitySelect + `
+	WHERE a.id = $1;`
 
 	row := r.db.QueryRow(query, id)
 
 	activity, err := scanActivity(row)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrActivityNotFound
+		}
+		return nil, err
+	}
+	return activity, nil
+}
+
+// FindByIDInOrganization 在组织内根据 ID 查找活动
+func (r *PostgresActivityRepository) FindByIDInOrganization(orgID, id string) (*domain.Activity, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domain.ErrActivityNotFound
+	}
+	query := activitySelect + `
+	WHERE a.id = $1 AND a.organization_id = $2;`
+
+	activity, err := scanActivity(r.db.QueryRow(query, id, orgID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrActivityNotFound
 		}
 		return nil, err
@@ -149,16 +226,13 @@ func (r *PostgresActivityRepository) FindByID(id string) (*domain.Activity, erro
 	return activity, nil
 }
 
-// FindAll 查找所有活动
-func (r *PostgresActivityRepository) FindAll() ([]*domain.Activity, error) {
-	query := `SELECT
-		id, title, description, speaker, start_time, end_time,
-		input_language, target_languages, cover_url, status,
-		viewer_url, created_at, updated_at
-	FROM activities
-	ORDER BY created_at DESC;`
+// FindAll 查找组织内所有活动
+func (r *PostgresActivityRepository) FindAll(orgID string) ([]*domain.Activity, error) {
+	query := activitySelect + `
+	WHERE a.organization_id = $1
+	ORDER BY a.created_at DESC;`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, orgID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query activities: %w", err)
 	}
@@ -175,17 +249,13 @@ func (r *PostgresActivityRepository) FindAll() ([]*domain.Activity, error) {
 	return activities, rows.Err()
 }
 
-// FindByStatus 根据状态查找活动
-func (r *PostgresActivityRepository) FindByStatus(status domain.ActivityStatus) ([]*domain.Activity, error) {
-	query := `SELECT
-		id, title, description, speaker, start_time, end_time,
-		input_language, target_languages, cover_url, status,
-		viewer_url, created_at, updated_at
-	FROM activities
-	WHERE status = $1
-	ORDER BY start_time DESC;`
-
-	rows, err := r.db.Query(query, status)
+// FindByStatus 根据状态查找组织内的活动
+func (r *PostgresActivityRepository) FindByStatus(orgID string, status domain.ActivityStatus) ([]*domain.Activity, error) {
+	query := activitySelect + `
+	WHERE a.organization_id = $1 AND a.status = $2
+	ORDER BY a.start_time DESC;`
+
+	rows, err := r.db.Query(query, orgID, status)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query activities by status: %w", err)
 	}
@@ -202,6 +272,42 @@ func (r *PostgresActivityRepository) FindByStatus(status domain.ActivityStatus)
 	return activities, rows.Err()
 }
 
+// FindAutoPublishDue 跨组织查找开启自动发布、开始时间不晚于 before 的草稿
+func (r *PostgresActivityRepository) FindAutoPublishDue(ctx context.Context, before time.Time) ([]*domain.Activity, error) {
+	query := activitySelect + `
+	WHERE a.status = $1 AND a.auto_publish AND a.start_time <= $2
+	ORDER BY a.start_time;`
+
+	return r.queryActivities(ctx, query, domain.ActivityStatusDraft, before)
+}
+
+// FindAutoCloseCandidates 跨组织查找设置了计划结束时间或无音频自动关闭的已发布活动
+func (r *PostgresActivityRepository) FindAutoCloseCandidates(ctx context.Context) ([]*domain.Activity, error) {
+	query := activitySelect + `
+	WHERE a.status = $1 AND (a.scheduled_end_time IS NOT NULL OR a.idle_close_minutes > 0)
+	ORDER BY a.start_time;`
+
+	return r.queryActivities(ctx, query, domain.ActivityStatusPublished)
+}
+
+func (r *PostgresActivityRepository) queryActivities(ctx context.Context, query string, args ...any) ([]*domain.Activity, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activities: %w", err)
+	}
+	defer rows.Close()
+
+	activities := make([]*domain.Activity, 0)
+	for rows.Next() {
+		activity, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+	return activities, rows.Err()
+}
+
 func scanActivity(scanner interface {
 	Scan(dest ...any) error
 }) (*domain.Activity, error) {
@@ -219,6 +325,12 @@ func scanActivity(scanner interface {
 		viewerURL     sql.NullString
 		createdAt     time.Time
 		updatedAt     time.Time
+		interimMs     int
+		orgID         string
+		autoPublish   bool
+		scheduledEnd  sql.NullTime
+		idleMinutes   int
+		lastAudioAt   sql.NullTime
 	)
 
 	if err := scanner.Scan(
@@ -235,6 +347,12 @@ func scanActivity(scanner interface {
 		&viewerURL,
 		&createdAt,
 		&updatedAt,
+		&interimMs,
+		&orgID,
+		&autoPublish,
+		&scheduledEnd,
+		&idleMinutes,
+		&lastAudioAt,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan activity: %w", err)
 	}
@@ -250,9 +368,18 @@ func scanActivity(scanner interface {
 	if endTime.Valid {
 		endPtr = &endTime.Time
 	}
+	var scheduledEndPtr *time.Time
+	if scheduledEnd.Valid {
+		scheduledEndPtr = &scheduledEnd.Time
+	}
+	var lastAudioPtr *time.Time
+	if lastAudioAt.Valid {
+		lastAudioPtr = &lastAudioAt.Time
+	}
 
 	return &domain.Activity{
 		ID:              id,
+		OrganizationID:  orgID,
 		Title:           title,
 		Description:     description.String,
 		Speaker:         speaker,
@@ -265,5 +392,11 @@ func scanActivity(scanner interface {
 		ViewerURL:       viewerURL.String,
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
+
+		InterimTranslationIntervalMs: interimMs,
+		AutoPublish:                  autoPublish,
+		ScheduledEndTime:             scheduledEndPtr,
+		IdleCloseMinutes:             idleMinutes,
+		LastAudioAt:                  lastAudioPtr,
 	}, nil
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+func TestPostgresActivityRepository_UpdateStatus(t *testing.T) {
+	db := openTestDB(t)
+	activity := createTestActivity(t, db)
+	repo := NewPostgresActivityRepository(db)
+
+	// 调度器持有的快照之后，标题被编辑
+	edited := *activity
+	edited.Title = "已编辑"
+	if err := repo.Update(&edited); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if err := activity.Publish(); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := repo.UpdateStatus(activity, domain.ActivityStatusDraft); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	stored, err := repo.FindByID(activity.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if stored.Status != domain.ActivityStatusPublished || stored.Title != "已编辑" {
+		t.Fatalf("UpdateStatus() should only write status fields, got status=%s title=%q", stored.Status, stored.Title)
+	}
+
+	// 原状态不匹配时不写入
+	activity.Status = domain.ActivityStatusClosed
+	end := time.Now().UTC()
+	activity.EndTime = &end
+	if err := repo.UpdateStatus(activity, domain.ActivityStatusDraft); !errors.Is(err, domain.ErrActivityStatusChanged) {
+		t.Fatalf("UpdateStatus() with stale status error = %v, want ErrActivityStatusChanged", err)
+	}
+	if stored, _ := repo.FindByID(activity.ID); stored.Status != domain.ActivityStatusPublished {
+		t.Fatalf("stale UpdateStatus() should not change status, got %s", stored.Status)
+	}
+
+	// 编辑时读到的状态已过期：不写入，也不改回状态
+	stale := *stored
+	stale.Status = domain.ActivityStatusDraft
+	stale.Title = "过期编辑"
+	if err := repo.Update(&stale); !errors.Is(err, domain.ErrActivityStatusChanged) {
+		t.Fatalf("Update() with stale status error = %v, want ErrActivityStatusChanged", err)
+	}
+	if stored, _ := repo.FindByID(activity.ID); stored.Status != domain.ActivityStatusPublished || stored.Title != "已编辑" {
+		t.Fatalf("stale Update() should not write, got status=%s title=%q", stored.Status, stored.Title)
+	}
+
+	missing := &domain.Activity{ID: uuid.New().String(), Status: domain.ActivityStatusClosed}
+	if err := repo.UpdateStatus(missing, domain.ActivityStatusPublished); !errors.Is(err, domain.ErrActivityNotFound) {
+		t.Fatalf("UpdateStatus() on missing activity error = %v, want ErrActivityNotFound", err)
+	}
+}
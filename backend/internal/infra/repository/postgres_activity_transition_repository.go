@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// PostgresActivityTransitionRepository 负责活动状态变更记录的持久化
+type PostgresActivityTransitionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresActivityTransitionRepository 构造函数
+func NewPostgresActivityTransitionRepository(db *sql.DB) *PostgresActivityTransitionRepository {
+	return &PostgresActivityTransitionRepository{db: db}
+}
+
+// RecordTransition 写入状态变更记录
+func (r *PostgresActivityTransitionRepository) RecordTransition(ctx context.Context, transition *domain.ActivityTransition) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO activity_transitions (
+		id, activity_id, from_status, to_status, reason, created_at
+	) VALUES ($1, $2, $3, $4, $5, $6);`,
+		transition.ID,
+		transition.ActivityID,
+		transition.FromStatus,
+		transition.ToStatus,
+		transition.Reason,
+		transition.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert activity transition: %w", err)
+	}
+	return nil
+}
+
+// ListTransitions 按时间顺序列出活动的状态变更记录
+func (r *PostgresActivityTransitionRepository) ListTransitions(ctx context.Context, activityID string) ([]*domain.ActivityTransition, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, activity_id, from_status, to_status, reason, created_at
+		FROM activity_transitions
+		WHERE activity_id = $1
+		ORDER BY created_at;`, activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := make([]*domain.ActivityTransition, 0)
+	for rows.Next() {
+		var transition domain.ActivityTransition
+		if err := rows.Scan(
+			&transition.ID,
+			&transition.ActivityID,
+			&transition.FromStatus,
+			&transition.ToStatus,
+			&transition.Reason,
+			&transition.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan activity transition: %w", err)
+		}
+		transitions = append(transitions, &transition)
+	}
+	return transitions, rows.Err()
+}
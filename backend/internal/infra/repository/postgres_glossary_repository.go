@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// PostgresGlossaryRepository 负责活动术语表的持久化
+type PostgresGlossaryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresGlossaryRepository 构造函数
+func NewPostgresGlossaryRepository(db *sql.DB) *PostgresGlossaryRepository {
+	return &PostgresGlossaryRepository{db: db}
+}
+
+// CreateTerm 新增术语
+func (r *PostgresGlossaryRepository) CreateTerm(ctx context.Context, term *domain.GlossaryTerm) error {
+	if _, err := uuid.Parse(term.ID); err != nil {
+		return fmt.Errorf("invalid glossary term id: %w", err)
+	}
+	translations, err := json.Marshal(term.Translations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal translations: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `INSERT INTO glossary_terms (
+		id, activity_id, source, translations, created_at, updated_at
+	) VALUES ($1, $2, $3, $4, $5, $6);`,
+		term.ID, term.ActivityID, term.Source, translations, term.CreatedAt, term.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrGlossaryTermExists
+		}
+		return fmt.Errorf("failed to insert glossary term: %w", err)
+	}
+	return nil
+}
+
+// UpdateTerm 更新术语
+func (r *PostgresGlossaryRepository) UpdateTerm(ctx context.Context, term *domain.GlossaryTerm) error {
+	translations, err := json.Marshal(term.Translations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal translations: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, `UPDATE glossary_terms
+		SET source = $3, translations = $4, updated_at = $5
+		WHERE id = $1 AND activity_id = $2;`,
+		term.ID, term.ActivityID, term.Source, translations, term.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrGlossaryTermExists
+		}
+		return fmt.Errorf("failed to update glossary term: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrGlossaryTermNotFound
+	}
+	return nil
+}
+
+// DeleteTerm 删除术语
+func (r *PostgresGlossaryRepository) DeleteTerm(ctx context.Context, activityID, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM glossary_terms WHERE id = $1 AND activity_id = $2;`, id, activityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete glossary term: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrGlossaryTermNotFound
+	}
+	return nil
+}
+
+// FindTerm 获取单个术语
+func (r *PostgresGlossaryRepository) FindTerm(ctx context.Context, activityID, id string) (*domain.GlossaryTerm, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domain.ErrGlossaryTermNotFound
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT id, activity_id, source, translations, created_at, updated_at
+		FROM glossary_terms
+		WHERE id = $1 AND activity_id = $2;`, id, activityID)
+	term, err := scanGlossaryTerm(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrGlossaryTermNotFound
+		}
+		return nil, err
+	}
+	return term, nil
+}
+
+// ListTerms 列出活动全部术语
+func (r *PostgresGlossaryRepository) ListTerms(ctx context.Context, activityID string) ([]*domain.GlossaryTerm, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, activity_id, source, translations, created_at, updated_at
+		FROM glossary_terms
+		WHERE activity_id = $1
+		ORDER BY created_at, source;`, activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query glossary terms: %w", err)
+	}
+	defer rows.Close()
+
+	terms := make([]*domain.GlossaryTerm, 0)
+	for rows.Next() {
+		term, err := scanGlossaryTerm(rows)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, rows.Err()
+}
+
+func scanGlossaryTerm(scanner interface {
+	Scan(dest ...any) error
+}) (*domain.GlossaryTerm, error) {
+	var (
+		term             domain.GlossaryTerm
+		translationsJSON []byte
+		createdAt        time.Time
+		updatedAt        time.Time
+	)
+	if err := scanner.Scan(&term.ID, &term.ActivityID, &term.Source, &translationsJSON, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan glossary term: %w", err)
+	}
+	if err := json.Unmarshal(translationsJSON, &term.Translations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal glossary translations: %w", err)
+	}
+	if term.Translations == nil {
+		term.Translations = make(map[string]string)
+	}
+	term.CreatedAt = createdAt
+	term.UpdatedAt = updatedAt
+	return &term, nil
+}
+
+// isUniqueViolation 判断是否违反唯一约束
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
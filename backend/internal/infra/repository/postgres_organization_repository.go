@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// PostgresOrganizationRepository 负责组织的持久化
+type PostgresOrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOrganizationRepository 构造函数
+func NewPostgresOrganizationRepository(db *sql.DB) *PostgresOrganizationRepository {
+	return &PostgresOrganizationRepository{db: db}
+}
+
+const organizationColumns = `id, name, created_at, updated_at`
+
+// Create 新增组织
+func (r *PostgresOrganizationRepository) Create(ctx context.Context, org *domain.Organization) error {
+	if _, err := uuid.Parse(org.ID); err != nil {
+		return fmt.Errorf("invalid organization id: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO organizations (`+organizationColumns+`)
+		VALUES ($1, $2, $3, $4);`,
+		org.ID, org.Name, org.CreatedAt, org.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrOrganizationExists
+		}
+		return fmt.Errorf("failed to insert organization: %w", err)
+	}
+	return nil
+}
+
+// Update 更新组织名称
+func (r *PostgresOrganizationRepository) Update(ctx context.Context, org *domain.Organization) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE organizations SET name = $2, updated_at = $3 WHERE id = $1;`,
+		org.ID, org.Name, org.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrOrganizationExists
+		}
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrOrganizationNotFound
+	}
+	return nil
+}
+
+// FindByID 根据 ID 获取组织
+func (r *PostgresOrganizationRepository) FindByID(ctx context.Context, id string) (*domain.Organization, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT `+organizationColumns+` FROM organizations WHERE id = $1;`, id)
+	org, err := scanOrganization(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+// List 列出全部组织
+func (r *PostgresOrganizationRepository) List(ctx context.Context) ([]*domain.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+organizationColumns+` FROM organizations ORDER BY created_at, name;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := make([]*domain.Organization, 0)
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+func scanOrganization(scanner interface {
+	Scan(dest ...any) error
+}) (*domain.Organization, error) {
+	var org domain.Organization
+	if err := scanner.Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan organization: %w", err)
+	}
+	return &org, nil
+}
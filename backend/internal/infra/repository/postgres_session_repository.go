@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// PostgresSessionRepository 负责刷新令牌会话的持久化
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionRepository 构造函数
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+const sessionColumns = `id, family_id, user_id, token_hash, device, user_agent, ip, started_at, created_at, expires_at, rotated_at, revoked_at, organization_id`
+
+// CreateSession 新增刷新令牌记录
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context, session *domain.RefreshSession) error {
+	if _, err := uuid.Parse(session.ID); err != nil {
+		return fmt.Errorf("invalid session id: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO refresh_sessions (`+sessionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);`,
+		session.ID, session.FamilyID, session.UserID, session.TokenHash, session.Device, session.UserAgent, session.IP,
+		session.StartedAt, session.CreatedAt, session.ExpiresAt, session.RotatedAt, session.RevokedAt, session.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to insert refresh session: %w", err)
+	}
+	return nil
+}
+
+// FindSessionByTokenHash 根据令牌摘要查找记录（包括已轮换、已注销的记录）
+func (r *PostgresSessionRepository) FindSessionByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshSession, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM refresh_sessions WHERE token_hash = $1;`, tokenHash)
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// RotateSession 将仍有效的记录标记为已轮换，返回是否成功（并发刷新时只有一个请求成功）
+func (r *PostgresSessionRepository) RotateSession(ctx context.Context, id string, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE refresh_sessions SET rotated_at = $2
+		WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL;`, id, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate refresh session: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	return rows > 0, nil
+}
+
+// RevokeFamily 注销用户的某个会话（同一 family 的全部记录）
+func (r *PostgresSessionRepository) RevokeFamily(ctx context.Context, userID, familyID string, at time.Time) error {
+	if _, err := uuid.Parse(familyID); err != nil {
+		return domain.ErrSessionNotFound
+	}
+	res, err := r.db.ExecContext(ctx, `UPDATE refresh_sessions SET revoked_at = $3
+		WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL;`, familyID, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh session: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions 注销用户的全部会话，返回注销的会话数
+func (r *PostgresSessionRepository) RevokeUserSessions(ctx context.Context, userID string, at time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `WITH revoked AS (
+			UPDATE refresh_sessions SET revoked_at = $2
+			WHERE user_id = $1 AND revoked_at IS NULL
+			RETURNING family_id
+		)
+		SELECT COUNT(DISTINCT family_id) FROM revoked;`, userID, at).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+	return count, nil
+}
+
+// ListActiveSessions 列出用户仍有效的会话，每个会话返回其当前记录
+func (r *PostgresSessionRepository) ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]*domain.RefreshSession, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sessionColumns+` FROM refresh_sessions
+		WHERE user_id = $1 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC;`, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refresh sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*domain.RefreshSession, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteExpiredSessions 清理过期记录，返回删除条数
+func (r *PostgresSessionRepository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM refresh_sessions WHERE expires_at < $1;`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh sessions: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	return rows, nil
+}
+
+func scanSession(scanner interface {
+	Scan(dest ...any) error
+}) (*domain.RefreshSession, error) {
+	var (
+		session   domain.RefreshSession
+		rotatedAt sql.NullTime
+		revokedAt sql.NullTime
+	)
+	if err := scanner.Scan(&session.ID, &session.FamilyID, &session.UserID, &session.TokenHash, &session.Device,
+		&session.UserAgent, &session.IP, &session.StartedAt, &session.CreatedAt, &session.ExpiresAt,
+		&rotatedAt, &revokedAt, &session.OrganizationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan refresh session: %w", err)
+	}
+	if rotatedAt.Valid {
+		session.RotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
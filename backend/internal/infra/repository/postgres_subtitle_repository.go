@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// PostgresSubtitleRepository 负责字幕归档的持久化
+type PostgresSubtitleRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSubtitleRepository 构造函数
+func NewPostgresSubtitleRepository(db *sql.DB) *PostgresSubtitleRepository {
+	return &PostgresSubtitleRepository{db: db}
+}
+
+// SaveSubtitle 保存一条字幕及其全部译文
+func (r *PostgresSubtitleRepository) SaveSubtitle(ctx context.Context, subtitle *domain.Subtitle) error {
+	if _, err := uuid.Parse(subtitle.ID); err != nil {
+		return fmt.Errorf("invalid subtitle id: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var startedAt sql.NullTime
+	if !subtitle.StartedAt.IsZero() {
+		startedAt = sql.NullTime{Time: subtitle.StartedAt, Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO subtitles (
+		id, activity_id, original, source_lang, confidence, started_at, created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (id) DO NOTHING;`,
+		subtitle.ID,
+		subtitle.ActivityID,
+		subtitle.Original,
+		subtitle.SourceLang,
+		subtitle.Confidence,
+		startedAt,
+		subtitle.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert subtitle: %w", err)
+	}
+
+	for language, text := range subtitle.Translations {
+		_, err := tx.ExecContext(ctx, `INSERT INTO subtitle_translations (subtitle_id, language, text)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (subtitle_id, language) DO UPDATE SET text = EXCLUDED.text;`,
+			subtitle.ID, language, text)
+		if err != nil {
+			return fmt.Errorf("failed to insert subtitle translation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit subtitle: %w", err)
+	}
+	return nil
+}
+
+// ListSubtitles 按时间顺序分页获取活动字幕，limit <= 0 时返回全部
+func (r *PostgresSubtitleRepository) ListSubtitles(ctx context.Context, activityID string, offset, limit int) ([]*domain.Subtitle, error) {
+	var limitArg any
+	if limit > 0 {
+		limitArg = limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `SELECT s.id, s.activity_id, s.original, s.source_lang, s.confidence, s.started_at, s.created_at, t.language, t.text
+		FROM (
+			SELECT id, seq, activity_id, original, source_lang, confidence, started_at, created_at
+			FROM subtitles
+			WHERE activity_id = $1
+			ORDER BY created_at, seq
+			LIMIT $2 OFFSET $3
+		) s
+		LEFT JOIN subtitle_translations t ON t.subtitle_id = s.id
+		ORDER BY s.created_at, s.seq, t.language;`
+
+	rows, err := r.db.QueryContext(ctx, query, activityID, limitArg, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subtitles: %w", err)
+	}
+	defer rows.Close()
+
+	subtitles := make([]*domain.Subtitle, 0)
+	var current *domain.Subtitle
+	for rows.Next() {
+		var (
+			id         string
+			actID      string
+			original   string
+			sourceLang string
+			confidence float32
+			startedAt  sql.NullTime
+			createdAt  time.Time
+			language   sql.NullString
+			text       sql.NullString
+		)
+		if err := rows.Scan(&id, &actID, &original, &sourceLang, &confidence, &startedAt, &createdAt, &language, &text); err != nil {
+			return nil, fmt.Errorf("failed to scan subtitle: %w", err)
+		}
+
+		if current == nil || current.ID != id {
+			current = &domain.Subtitle{
+				ID:           id,
+				ActivityID:   actID,
+				Original:     original,
+				SourceLang:   sourceLang,
+				Translations: make(map[string]string),
+				Confidence:   confidence,
+				StartedAt:    startedAt.Time,
+				Timestamp:    createdAt,
+			}
+			subtitles = append(subtitles, current)
+		}
+		if language.Valid {
+			current.Translations[language.String] = text.String
+		}
+	}
+	return subtitles, rows.Err()
+}
+
+// CountSubtitles 统计活动字幕条数
+func (r *PostgresSubtitleRepository) CountSubtitles(ctx context.Context, activityID string) (int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM subtitles WHERE activity_id = $1;`, activityID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count subtitles: %w", err)
+	}
+	return total, nil
+}
+
+// MarkAudioStarted 记录活动首个音频块的到达时间（仅首次写入生效）
+func (r *PostgresSubtitleRepository) MarkAudioStarted(ctx context.Context, activityID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO activity_audio_starts (activity_id, first_audio_at)
+		VALUES ($1, $2)
+		ON CONFLICT (activity_id) DO NOTHING;`, activityID, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark audio start: %w", err)
+	}
+	return nil
+}
+
+// MarkAudioActivity 记录活动最近一次收到音频的时间，供调度器判断无音频自动关闭
+func (r *PostgresSubtitleRepository) MarkAudioActivity(ctx context.Context, activityID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO activity_audio_starts (activity_id, first_audio_at, last_audio_at)
+		VALUES ($1, $2, $2)
+		ON CONFLICT (activity_id) DO UPDATE
+		SET last_audio_at = GREATEST(activity_audio_starts.last_audio_at, EXCLUDED.last_audio_at);`, activityID, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark audio activity: %w", err)
+	}
+	return nil
+}
+
+// GetAudioStartedAt 获取活动首个音频块的到达时间，未记录时返回 nil
+func (r *PostgresSubtitleRepository) GetAudioStartedAt(ctx context.Context, activityID string) (*time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT first_audio_at FROM activity_audio_starts WHERE activity_id = $1;`, activityID).Scan(&at)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query audio start: %w", err)
+	}
+	return &at, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+func TestPostgresSubtitleRepository(t *testing.T) {
+	db := openTestDB(t)
+	activity := createTestActivity(t, db)
+	repo := NewPostgresSubtitleRepository(db)
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	// 写入顺序与时间顺序相反，查询按字幕时间排序
+	second := &domain.Subtitle{ID: uuid.New().String(), ActivityID: activity.ID, Original: "第二句", SourceLang: "zh-CN",
+		Translations: map[string]string{"en": "second"}, Timestamp: base.Add(2 * time.Second)}
+	first := &domain.Subtitle{ID: uuid.New().String(), ActivityID: activity.ID, Original: "第一句", SourceLang: "zh-CN",
+		Translations: map[string]string{"en": "first", "ja": "最初"}, StartedAt: base, Timestamp: base.Add(time.Second)}
+	for _, subtitle := range []*domain.Subtitle{second, first, first} {
+		if err := repo.SaveSubtitle(ctx, subtitle); err != nil {
+			t.Fatalf("SaveSubtitle() error = %v", err)
+		}
+	}
+	if err := repo.SaveSubtitle(ctx, &domain.Subtitle{ID: "not-a-uuid", ActivityID: activity.ID}); err == nil {
+		t.Fatal("SaveSubtitle() with invalid id should fail")
+	}
+
+	if total, err := repo.CountSubtitles(ctx, activity.ID); err != nil || total != 2 {
+		t.Fatalf("CountSubtitles() = %d, %v; want 2", total, err)
+	}
+	subtitles, err := repo.ListSubtitles(ctx, activity.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("ListSubtitles() error = %v", err)
+	}
+	if len(subtitles) != 2 || subtitles[0].ID != first.ID || subtitles[1].ID != second.ID {
+		t.Fatalf("ListSubtitles() order = %+v", subtitles)
+	}
+	if got := subtitles[0]; len(got.Translations) != 2 || got.Translations["ja"] != "最初" || !got.StartedAt.Equal(base) {
+		t.Fatalf("first subtitle = %+v", got)
+	}
+	if page, err := repo.ListSubtitles(ctx, activity.ID, 1, 1); err != nil || len(page) != 1 || page[0].ID != second.ID {
+		t.Fatalf("ListSubtitles(offset 1) = %+v, %v", page, err)
+	}
+
+	// 首个音频时间只记录一次
+	if at, err := repo.GetAudioStartedAt(ctx, activity.ID); err != nil || at != nil {
+		t.Fatalf("GetAudioStartedAt() before mark = %v, %v", at, err)
+	}
+	if err := repo.MarkAudioStarted(ctx, activity.ID, base); err != nil {
+		t.Fatalf("MarkAudioStarted() error = %v", err)
+	}
+	if err := repo.MarkAudioStarted(ctx, activity.ID, base.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkAudioStarted() error = %v", err)
+	}
+	if at, err := repo.GetAudioStartedAt(ctx, activity.ID); err != nil || at == nil || !at.Equal(base) {
+		t.Fatalf("GetAudioStartedAt() = %v, %v; want %v", at, err, base)
+	}
+}
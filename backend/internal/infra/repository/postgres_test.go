@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/config"
+	"github.com/hoshea/orion-backend/internal/infra/database"
+)
+
+// openTestDB 连接 TEST_DATABASE_URL 指定的 PostgreSQL 并执行迁移，未设置或不可用时跳过
+// 测试数据按活动 ID 隔离，不要指向生产库
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := database.Open(config.DatabaseConfig{URL: url})
+	if err != nil {
+		t.Skipf("postgres not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return db
+}
+
+// createTestActivity 创建一个草稿活动，测试结束后删除
+func createTestActivity(t *testing.T, db *sql.DB) *domain.Activity {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	activity := &domain.Activity{
+		ID:              uuid.New().String(),
+		OrganizationID:  domain.DefaultOrganizationID,
+		Title:           "测试活动",
+		Speaker:         "演讲者",
+		StartTime:       now.Add(time.Hour),
+		InputLanguage:   "zh-CN",
+		TargetLanguages: []string{"en", "ja"},
+		Status:          domain.ActivityStatusDraft,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	repo := NewPostgresActivityRepository(db)
+	if err := repo.Create(activity); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Delete(activity.ID) })
+	return activity
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// PostgresUserRepository 负责管理后台用户的持久化
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserRepository 构造函数
+func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+const userColumns = `id, username, display_name, role, password_hash, created_at, updated_at, organization_id`
+
+// Create 新增用户
+func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
+	if _, err := uuid.Parse(user.ID); err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO users (`+userColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`,
+		user.ID, user.Username, user.DisplayName, string(user.Role), user.PasswordHash, user.CreatedAt, user.UpdatedAt, user.OrganizationID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrUserExists
+		}
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+	return nil
+}
+
+// Update 更新用户资料、角色与密码
+func (r *PostgresUserRepository) Update(ctx context.Context, user *domain.User) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users
+		SET display_name = $2, role = $3, password_hash = $4, updated_at = $5
+		WHERE id = $1;`,
+		user.ID, user.DisplayName, string(user.Role), user.PasswordHash, user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete 删除用户
+func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.ErrUserNotFound
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1;`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// FindByID 根据 ID 获取用户
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1;`, id)
+	return scanUserRow(row)
+}
+
+// FindByUsername 根据用户名（不区分大小写）获取用户
+func (r *PostgresUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE lower(username) = lower($1);`, username)
+	return scanUserRow(row)
+}
+
+// List 列出组织内的全部用户
+func (r *PostgresUserRepository) List(ctx context.Context, orgID string) ([]*domain.User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users
+		WHERE organization_id = $1
+		ORDER BY created_at, username;`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// CountByRole 统计组织内指定角色的用户数，orgID 为空时不限组织，role 为空时统计全部角色
+func (r *PostgresUserRepository) CountByRole(ctx context.Context, orgID string, role domain.UserRole) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users
+		WHERE ($1 = '' OR organization_id::text = $1) AND ($2 = '' OR role = $2);`,
+		orgID, string(role)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+func scanUserRow(row *sql.Row) (*domain.User, error) {
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func scanUser(scanner interface {
+	Scan(dest ...any) error
+}) (*domain.User, error) {
+	var (
+		user domain.User
+		role string
+	)
+	if err := scanner.Scan(&user.ID, &user.Username, &user.DisplayName, &role, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt, &user.OrganizationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan user: %w", err)
+	}
+	user.Role = domain.UserRole(role)
+	return &user, nil
+}
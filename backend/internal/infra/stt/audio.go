@@ -0,0 +1,155 @@
+package stt
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	// maxContainerHeader 容器头的最大长度，超过仍未找到音频数据时放弃记录
+	maxContainerHeader = 64 * 1024
+	// ContainerSyncMarkerSize Ogg 页与 WebM Cluster 同步标记的长度，跨音频块查找时需保留尾部字节
+	ContainerSyncMarkerSize = 4
+)
+
+var (
+	oggCapturePattern = []byte("OggS")
+	webmClusterID     = []byte{0x1F, 0x43, 0xB6, 0x75}
+)
+
+// PCMConverter 将 LINEAR16 / μ-law 多声道音频转换为指定采样率的单声道 LINEAR16
+// 转换状态跨音频块保留，单个实例只能被一个协程使用
+type PCMConverter struct {
+	mulaw     bool
+	channels  int
+	step      float64 // 源采样率 / 目标采样率
+	pending   []byte  // 不足一个采样帧的剩余字节
+	pos       float64 // 下一个输出采样在源序列中的位置，0 对应 prev
+	prev      float64
+	hasPrev   bool
+	frameSize int
+}
+
+// NewPCMConverter 创建转换器，mulaw 为 false 时输入为 16bit 小端 PCM
+func NewPCMConverter(mulaw bool, channels int, fromRate, toRate int) *PCMConverter {
+	if channels < 1 {
+		channels = 1
+	}
+	sampleSize := 2
+	if mulaw {
+		sampleSize = 1
+	}
+	return &PCMConverter{
+		mulaw:     mulaw,
+		channels:  channels,
+		step:      float64(fromRate) / float64(toRate),
+		frameSize: sampleSize * channels,
+	}
+}
+
+// Convert 转换一个音频块，输入不足一个采样帧时返回空切片
+func (c *PCMConverter) Convert(data []byte) []byte {
+	if len(c.pending) > 0 {
+		data = append(c.pending, data...)
+	}
+	frames := len(data) / c.frameSize
+	c.pending = append([]byte(nil), data[frames*c.frameSize:]...)
+	if frames == 0 {
+		return nil
+	}
+
+	// 下混为单声道，首位保留上一块的最后一个采样用于插值
+	source := make([]float64, 0, frames+1)
+	if c.hasPrev {
+		source = append(source, c.prev)
+	}
+	for i := 0; i < frames; i++ {
+		frame := data[i*c.frameSize : (i+1)*c.frameSize]
+		var sum float64
+		for ch := 0; ch < c.channels; ch++ {
+			if c.mulaw {
+				sum += float64(mulawToLinear(frame[ch]))
+			} else {
+				sum += float64(int16(binary.LittleEndian.Uint16(frame[ch*2:])))
+			}
+		}
+		source = append(source, sum/float64(c.channels))
+	}
+
+	// 线性插值重采样
+	last := float64(len(source) - 1)
+	out := make([]byte, 0, int(last/c.step+1)*2)
+	for ; c.pos < last; c.pos += c.step {
+		i := int(c.pos)
+		frac := c.pos - float64(i)
+		sample := source[i] + (source[i+1]-source[i])*frac
+		out = binary.LittleEndian.AppendUint16(out, uint16(int16(sample)))
+	}
+	c.pos -= last
+	c.prev = source[len(source)-1]
+	c.hasPrev = true
+	return out
+}
+
+// mulawToLinear G.711 μ-law 解码
+func mulawToLinear(u byte) int16 {
+	u = ^u
+	t := (int16(u&0x0F) << 3) + 0x84
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return 0x84 - t
+	}
+	return t - 0x84
+}
+
+// ContainerHeader 从 Ogg / WebM 流开头提取容器头（音频数据之前的部分）
+// complete 为 false 表示需要更多数据；数据无法识别时返回 nil, true
+func ContainerHeader(encoding string, data []byte) (header []byte, complete bool) {
+	switch encoding {
+	case EncodingWebMOpus:
+		if idx := bytes.Index(data, webmClusterID); idx >= 0 {
+			return data[:idx], true
+		}
+	case EncodingOggOpus:
+		// Opus 的 OpusHead / OpusTags 页粒度位置为 0，首个音频页之前即为容器头
+		offset := 0
+		for offset+27 <= len(data) {
+			if !bytes.Equal(data[offset:offset+4], oggCapturePattern) {
+				return nil, true
+			}
+			segments := int(data[offset+26])
+			if offset+27+segments > len(data) {
+				break
+			}
+			if binary.LittleEndian.Uint64(data[offset+6:]) != 0 {
+				if offset == 0 {
+					return nil, true
+				}
+				return data[:offset], true
+			}
+			size := 27 + segments
+			for _, lacing := range data[offset+27 : offset+27+segments] {
+				size += int(lacing)
+			}
+			offset += size
+		}
+	default:
+		return nil, true
+	}
+	if len(data) > maxContainerHeader {
+		return nil, true
+	}
+	return nil, false
+}
+
+// ContainerSync 返回下一个 Ogg 页或 WebM Cluster 的起点，未找到时返回 -1
+func ContainerSync(encoding string, data []byte) int {
+	switch encoding {
+	case EncodingWebMOpus:
+		return bytes.Index(data, webmClusterID)
+	case EncodingOggOpus:
+		return bytes.Index(data, oggCapturePattern)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,89 @@
+package stt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestPCMConverter_DownmixAndResample(t *testing.T) {
+	converter := NewPCMConverter(false, 2, 48000, 16000)
+
+	// 48kHz 立体声，左右声道取平均后每 3 个采样输出 1 个
+	var input []byte
+	for i := 0; i < 480; i++ {
+		input = binary.LittleEndian.AppendUint16(input, uint16(int16(1000)))
+		input = binary.LittleEndian.AppendUint16(input, uint16(int16(3000)))
+	}
+
+	// 分两块送入，且第一块截断在采样帧中间
+	out := converter.Convert(input[:1001])
+	out = append(out, converter.Convert(input[1001:])...)
+	if len(out) != 160*2 {
+		t.Fatalf("output samples = %d, want 160", len(out)/2)
+	}
+	for i := 0; i < len(out); i += 2 {
+		if sample := int16(binary.LittleEndian.Uint16(out[i:])); sample != 2000 {
+			t.Fatalf("sample %d = %d, want 2000", i/2, sample)
+		}
+	}
+}
+
+func TestPCMConverter_Mulaw(t *testing.T) {
+	converter := NewPCMConverter(true, 1, 8000, 16000)
+
+	// 0xFF 为 μ-law 的 0，0x80 为正向最大值
+	out := converter.Convert([]byte{0xFF, 0x80})
+	if len(out) != 2*2 {
+		t.Fatalf("output samples = %d, want 2", len(out)/2)
+	}
+	if first := int16(binary.LittleEndian.Uint16(out)); first != 0 {
+		t.Fatalf("first sample = %d, want 0", first)
+	}
+	if got := mulawToLinear(0x80); got != 32124 {
+		t.Fatalf("mulawToLinear(0x80) = %d, want 32124", got)
+	}
+}
+
+func oggPage(granule uint64, body []byte) []byte {
+	page := append([]byte("OggS"), 0, 0)
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = append(page, make([]byte, 12)...) // serial, sequence, crc
+	page = append(page, 1, byte(len(body)))
+	return append(page, body...)
+}
+
+func TestContainerHeader_Ogg(t *testing.T) {
+	head := oggPage(0, []byte("OpusHead"))
+	tags := oggPage(0, []byte("OpusTags"))
+	audio := oggPage(960, []byte("audio"))
+
+	if _, complete := ContainerHeader(EncodingOggOpus, append(head, tags[:10]...)); complete {
+		t.Fatal("header should be incomplete before the first audio page")
+	}
+
+	stream := bytes.Join([][]byte{head, tags, audio}, nil)
+	header, complete := ContainerHeader(EncodingOggOpus, stream)
+	if !complete || !bytes.Equal(header, stream[:len(head)+len(tags)]) {
+		t.Fatalf("ContainerHeader() = %d bytes, complete %t", len(header), complete)
+	}
+	if idx := ContainerSync(EncodingOggOpus, []byte("xx")); idx != -1 {
+		t.Fatalf("ContainerSync() = %d, want -1", idx)
+	}
+	if idx := ContainerSync(EncodingOggOpus, append([]byte("tail"), audio...)); idx != 4 {
+		t.Fatalf("ContainerSync() = %d, want 4", idx)
+	}
+}
+
+func TestContainerHeader_WebM(t *testing.T) {
+	header := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x01, 0x02}
+	stream := append(append([]byte{}, header...), 0x1F, 0x43, 0xB6, 0x75, 0x09)
+
+	got, complete := ContainerHeader(EncodingWebMOpus, stream)
+	if !complete || !bytes.Equal(got, header) {
+		t.Fatalf("ContainerHeader() = %x, complete %t", got, complete)
+	}
+	if _, complete := ContainerHeader(EncodingWebMOpus, header); complete {
+		t.Fatal("header should be incomplete before the first cluster")
+	}
+}
@@ -0,0 +1,56 @@
+package stt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+)
+
+// Factory 根据配置创建识别客户端
+type Factory func(ctx context.Context, cfg *config.Config) (Client, error)
+
+// Registry 语音识别引擎注册表，按名称（STT_PROVIDER）选择实现
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry 创建空注册表
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register 注册引擎，重复注册会覆盖旧实现
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[strings.ToLower(strings.TrimSpace(name))] = factory
+}
+
+// New 创建指定名称的识别客户端
+func (r *Registry) New(ctx context.Context, name string, cfg *config.Config) (Client, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[strings.ToLower(strings.TrimSpace(name))]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown STT provider %q (available: %s)", name, strings.Join(r.Names(), ", "))
+	}
+	return factory(ctx, cfg)
+}
+
+// Names 返回已注册的引擎名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,49 @@
+package stt
+
+import (
+	"context"
+	"time"
+)
+
+// Client 流式语音识别客户端
+// 实现需持续读取 audioStream 直至其关闭或 ctx 取消，并把识别结果写入 results
+type Client interface {
+	StreamingRecognize(ctx context.Context, audioStream <-chan []byte, config StreamingRecognizeConfig, results chan<- RecognitionResult) error
+	Close() error
+}
+
+// StreamLimiter 单个识别流存在时长上限的引擎实现该接口，管线会在到期前重建识别流
+type StreamLimiter interface {
+	MaxStreamDuration() time.Duration
+}
+
+// 音频编码名称
+const (
+	EncodingLinear16 = "LINEAR16"
+	EncodingMulaw    = "MULAW"
+	EncodingOggOpus  = "OGG_OPUS"
+	EncodingWebMOpus = "WEBM_OPUS"
+)
+
+// EncodingSupporter 可直接接收非 PCM 编码的引擎实现该接口
+// 未实现的引擎只接受 16kHz 单声道 LINEAR16，管线会先在 Go 中转换
+type EncodingSupporter interface {
+	SupportsEncoding(encoding string) bool
+}
+
+// StreamingRecognizeConfig 流式识别配置
+type StreamingRecognizeConfig struct {
+	LanguageCode               string   // 例如 "zh-CN", "en-US"
+	Encoding                   string   // 音频编码，空值为 LINEAR16
+	SampleRateHertz            int32    // 采样率，例如 16000
+	AudioChannelCount          int32    // 声道数，0 表示单声道
+	EnableAutomaticPunctuation bool     // 是否启用自动标点
+	PhraseHints                []string // 短语提示（术语表），引擎不支持时忽略
+}
+
+// RecognitionResult 识别结果
+type RecognitionResult struct {
+	Transcript string  // 识别的文本
+	IsFinal    bool    // 是否是最终结果
+	Confidence float32 // 置信度 (0-1)
+}
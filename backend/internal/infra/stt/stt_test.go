@@ -0,0 +1,166 @@
+package stt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hoshea/orion-backend/internal/infra/config"
+)
+
+func TestRegistryNew(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Vosk", func(ctx context.Context, cfg *config.Config) (Client, error) {
+		return NewVoskClient(cfg.Speech.VoskServerURL)
+	})
+
+	cfg := &config.Config{Speech: config.SpeechConfig{VoskServerURL: "ws://localhost:2700"}}
+	client, err := registry.New(context.Background(), "vosk", cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := client.(*VoskClient); !ok {
+		t.Fatalf("New() returned %T", client)
+	}
+
+	if _, err := registry.New(context.Background(), "unknown", cfg); err == nil || !strings.Contains(err.Error(), "vosk") {
+		t.Fatalf("expected unknown provider error listing available providers, got %v", err)
+	}
+}
+
+func TestVoskClientStreamingRecognize(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var cfg voskConfigMessage
+		if err := conn.ReadJSON(&cfg); err != nil || cfg.Config.SampleRate != 16000 {
+			t.Errorf("unexpected config message: %+v, %v", cfg, err)
+			return
+		}
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.BinaryMessage {
+				_ = conn.WriteJSON(map[string]string{"partial": "你好"})
+				continue
+			}
+			if strings.Contains(string(data), "eof") {
+				_ = conn.WriteJSON(map[string]any{
+					"text":   "你好 世界",
+					"result": []map[string]any{{"conf": 0.8}, {"conf": 0.6}},
+				})
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewVoskClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatalf("NewVoskClient() error = %v", err)
+	}
+
+	audio := make(chan []byte, 1)
+	audio <- make([]byte, 3200)
+	close(audio)
+	results := make(chan RecognitionResult, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.StreamingRecognize(ctx, audio, StreamingRecognizeConfig{LanguageCode: "zh-CN", SampleRateHertz: 16000}, results); err != nil {
+		t.Fatalf("StreamingRecognize() error = %v", err)
+	}
+	close(results)
+
+	var got []RecognitionResult
+	for result := range results {
+		got = append(got, result)
+	}
+	if len(got) != 2 || got[0].IsFinal || got[0].Transcript != "你好" {
+		t.Fatalf("unexpected results: %+v", got)
+	}
+	if !got[1].IsFinal || got[1].Transcript != "你好 世界" || got[1].Confidence < 0.69 || got[1].Confidence > 0.71 {
+		t.Fatalf("unexpected final result: %+v", got[1])
+	}
+}
+
+func TestWhisperCppClientStreamingRecognize(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inference" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+		}
+		if got := r.FormValue("language"); got != "en" {
+			t.Errorf("language = %q, want en", got)
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile() error = %v", err)
+		} else {
+			header := make([]byte, 44)
+			_, _ = file.Read(header)
+			if string(header[0:4]) != "RIFF" || binary.LittleEndian.Uint32(header[24:28]) != 16000 {
+				t.Errorf("unexpected wav header: %v", header)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"text": " hello world "})
+	}))
+	defer server.Close()
+
+	client, err := NewWhisperCppClient(server.URL, 2*time.Second)
+	if err != nil {
+		t.Fatalf("NewWhisperCppClient() error = %v", err)
+	}
+
+	// 1 秒语音 + 1 秒静音，应切分为一个片段；其后的纯静音不应提交
+	voice := make([]byte, 3200)
+	for i := 0; i+1 < len(voice); i += 2 {
+		binary.LittleEndian.PutUint16(voice[i:], uint16(int16(8000)))
+	}
+	silence := make([]byte, 3200)
+
+	audio := make(chan []byte, 40)
+	for i := 0; i < 10; i++ {
+		audio <- voice
+	}
+	for i := 0; i < 20; i++ {
+		audio <- silence
+	}
+	close(audio)
+	results := make(chan RecognitionResult, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.StreamingRecognize(ctx, audio, StreamingRecognizeConfig{LanguageCode: "en-US", SampleRateHertz: 16000}, results); err != nil {
+		t.Fatalf("StreamingRecognize() error = %v", err)
+	}
+	close(results)
+
+	var got []RecognitionResult
+	for result := range results {
+		got = append(got, result)
+	}
+	if requests != 1 || len(got) != 1 || got[0].Transcript != "hello world" || !got[0].IsFinal {
+		t.Fatalf("requests = %d, results = %+v", requests, got)
+	}
+}
@@ -0,0 +1,172 @@
+package stt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const voskWriteTimeout = 10 * time.Second
+
+var _ Client = (*VoskClient)(nil)
+
+// VoskClient 对接本地 vosk-server 的离线识别客户端
+// 协议：先发送 {"config":{...}} 文本帧，随后发送 LINEAR16 PCM 二进制帧，结束时发送 {"eof":1}
+// 识别语种由 vosk-server 加载的模型决定，不支持短语提示
+type VoskClient struct {
+	serverURL string
+	dialer    *websocket.Dialer
+}
+
+// NewVoskClient 创建 Vosk 客户端，serverURL 例如 ws://localhost:2700
+func NewVoskClient(serverURL string) (*VoskClient, error) {
+	serverURL = strings.TrimSpace(serverURL)
+	if serverURL == "" {
+		return nil, errors.New("vosk server url is empty")
+	}
+	return &VoskClient{
+		serverURL: serverURL,
+		dialer:    &websocket.Dialer{HandshakeTimeout: 10 * time.Second},
+	}, nil
+}
+
+// Close 实现接口
+func (c *VoskClient) Close() error {
+	return nil
+}
+
+type voskConfigMessage struct {
+	Config voskConfig `json:"config"`
+}
+
+type voskConfig struct {
+	SampleRate float64 `json:"sample_rate"`
+	Words      int     `json:"words"`
+}
+
+type voskResponse struct {
+	Partial *string `json:"partial"`
+	Text    *string `json:"text"`
+	Result  []struct {
+		Conf float32 `json:"conf"`
+	} `json:"result"`
+}
+
+// StreamingRecognize 流式识别：partial 作为中间结果，text 作为最终结果
+func (c *VoskClient) StreamingRecognize(
+	ctx context.Context,
+	audioStream <-chan []byte,
+	config StreamingRecognizeConfig,
+	results chan<- RecognitionResult,
+) error {
+	conn, _, err := c.dialer.DialContext(ctx, c.serverURL, http.Header{})
+	if err != nil {
+		return fmt.Errorf("failed to connect vosk server: %w", err)
+	}
+	defer conn.Close()
+
+	sampleRate := config.SampleRateHertz
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+
+	var writeMu sync.Mutex
+	writeJSON := func(v any) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(voskWriteTimeout))
+		return conn.WriteJSON(v)
+	}
+
+	if err := writeJSON(voskConfigMessage{Config: voskConfig{SampleRate: float64(sampleRate), Words: 1}}); err != nil {
+		return fmt.Errorf("failed to send vosk config: %w", err)
+	}
+
+	// ctx 取消时关闭连接，使读取循环退出
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case audioData, ok := <-audioStream:
+				if !ok {
+					// 音频流关闭，通知服务端输出最终结果
+					_ = writeJSON(map[string]int{"eof": 1})
+					return
+				}
+				writeMu.Lock()
+				_ = conn.SetWriteDeadline(time.Now().Add(voskWriteTimeout))
+				err := conn.WriteMessage(websocket.BinaryMessage, audioData)
+				writeMu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("failed to receive vosk response: %w", err)
+		}
+
+		var resp voskResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("failed to decode vosk response: %w", err)
+		}
+
+		var result RecognitionResult
+		switch {
+		case resp.Text != nil:
+			result = RecognitionResult{
+				Transcript: strings.TrimSpace(*resp.Text),
+				IsFinal:    true,
+				Confidence: resp.confidence(),
+			}
+		case resp.Partial != nil:
+			result = RecognitionResult{Transcript: strings.TrimSpace(*resp.Partial)}
+		default:
+			continue
+		}
+		if result.Transcript == "" {
+			continue
+		}
+
+		select {
+		case results <- result:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// confidence 计算逐词置信度均值，服务端未返回时视为 1
+func (r voskResponse) confidence() float32 {
+	if len(r.Result) == 0 {
+		return 1
+	}
+	var sum float32
+	for _, word := range r.Result {
+		sum += word.Conf
+	}
+	return sum / float32(len(r.Result))
+}
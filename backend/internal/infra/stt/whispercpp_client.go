@@ -0,0 +1,277 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// whisperSilenceRMS 低于该均方根幅值的音频块视为静音
+	whisperSilenceRMS = 500
+	// whisperMinSilence 语音后持续静音达到该时长即切分片段
+	whisperMinSilence = 600 * time.Millisecond
+	// whisperMinSegment 片段最短时长，过短的片段识别效果差
+	whisperMinSegment = time.Second
+	// whisperQueueSize 待识别片段队列长度
+	whisperQueueSize = 8
+)
+
+var _ Client = (*WhisperCppClient)(nil)
+
+// WhisperCppClient 对接本地 whisper.cpp server（/inference 接口）的离线识别客户端
+// whisper.cpp 不支持流式识别，客户端按静音或最长时长切分片段后逐段提交，只产出最终结果；短语提示作为 prompt 提交
+type WhisperCppClient struct {
+	serverURL  string
+	maxSegment time.Duration
+	httpClient *http.Client
+}
+
+// NewWhisperCppClient 创建 whisper.cpp 客户端，serverURL 例如 http://localhost:8081
+func NewWhisperCppClient(serverURL string, maxSegment time.Duration) (*WhisperCppClient, error) {
+	serverURL = strings.TrimRight(strings.TrimSpace(serverURL), "/")
+	if serverURL == "" {
+		return nil, errors.New("whisper.cpp server url is empty")
+	}
+	if maxSegment < whisperMinSegment {
+		maxSegment = 8 * time.Second
+	}
+	return &WhisperCppClient{
+		serverURL:  serverURL,
+		maxSegment: maxSegment,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Close 实现接口
+func (c *WhisperCppClient) Close() error {
+	return nil
+}
+
+// StreamingRecognize 读取 LINEAR16 PCM 音频，切分片段后提交识别
+func (c *WhisperCppClient) StreamingRecognize(
+	ctx context.Context,
+	audioStream <-chan []byte,
+	config StreamingRecognizeConfig,
+	results chan<- RecognitionResult,
+) error {
+	sampleRate := int(config.SampleRateHertz)
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+	bytesPerSecond := sampleRate * 2
+	durationBytes := func(d time.Duration) int {
+		return int(d.Seconds()*float64(bytesPerSecond)) &^ 1
+	}
+	minSilenceBytes := durationBytes(whisperMinSilence)
+	minSegmentBytes := durationBytes(whisperMinSegment)
+	maxSegmentBytes := durationBytes(c.maxSegment)
+
+	// 识别在独立 goroutine 中串行执行，避免阻塞音频读取
+	segments := make(chan []byte, whisperQueueSize)
+	workerErr := make(chan error, 1)
+	go func() {
+		workerErr <- c.transcribeSegments(ctx, segments, sampleRate, whisperLanguage(config.LanguageCode), whisperPrompt(config.PhraseHints), results)
+	}()
+
+	var (
+		buffer      []byte
+		silentBytes int
+		voiced      bool
+	)
+	flush := func() {
+		if voiced && len(buffer) > 0 {
+			select {
+			case segments <- buffer:
+			default:
+				// 识别跟不上音频速度时丢弃片段，保证实时性
+			}
+		}
+		buffer = nil
+		silentBytes = 0
+		voiced = false
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			close(segments)
+			<-workerErr
+			return ctx.Err()
+		case err := <-workerErr:
+			return err
+		case chunk, ok := <-audioStream:
+			if !ok {
+				flush()
+				close(segments)
+				return <-workerErr
+			}
+			if len(chunk) == 0 {
+				continue
+			}
+
+			buffer = append(buffer, chunk...)
+			if pcmRMS(chunk) < whisperSilenceRMS {
+				silentBytes += len(chunk)
+			} else {
+				silentBytes = 0
+				voiced = true
+			}
+
+			switch {
+			case !voiced && silentBytes >= minSilenceBytes:
+				// 纯静音直接丢弃，避免模型对静音产生幻觉文本
+				buffer = buffer[:0]
+				silentBytes = 0
+			case voiced && silentBytes >= minSilenceBytes && len(buffer) >= minSegmentBytes:
+				flush()
+			case len(buffer) >= maxSegmentBytes:
+				flush()
+			}
+		}
+	}
+}
+
+func (c *WhisperCppClient) transcribeSegments(ctx context.Context, segments <-chan []byte, sampleRate int, language, prompt string, results chan<- RecognitionResult) error {
+	for segment := range segments {
+		text, err := c.transcribe(ctx, segment, sampleRate, language, prompt)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if text == "" {
+			continue
+		}
+
+		select {
+		case results <- RecognitionResult{Transcript: text, IsFinal: true, Confidence: 1}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// transcribe 将片段封装为 WAV 后提交 whisper.cpp server
+func (c *WhisperCppClient) transcribe(ctx context.Context, pcm []byte, sampleRate int, language, prompt string) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", "segment.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if err := writeWAV(part, pcm, sampleRate); err != nil {
+		return "", fmt.Errorf("failed to encode wav: %w", err)
+	}
+	_ = writer.WriteField("response_format", "json")
+	_ = writer.WriteField("temperature", "0.0")
+	if language != "" {
+		_ = writer.WriteField("language", language)
+	}
+	if prompt != "" {
+		_ = writer.WriteField("prompt", prompt)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serverURL+"/inference", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call whisper.cpp server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("whisper.cpp server returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode whisper.cpp response: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// whisperLanguage 将 BCP-47 语言代码转换为 whisper 使用的 ISO 639-1 代码
+func whisperLanguage(code string) string {
+	lang, _, _ := strings.Cut(strings.TrimSpace(code), "-")
+	return strings.ToLower(lang)
+}
+
+// whisperPrompt 将短语提示拼接为初始提示词，引导模型输出术语的正确写法
+func whisperPrompt(hints []string) string {
+	return strings.Join(hints, ", ")
+}
+
+// writeWAV 写入单声道 16bit PCM WAV
+func writeWAV(w io.Writer, pcm []byte, sampleRate int) error {
+	header := struct {
+		RIFF          [4]byte
+		ChunkSize     uint32
+		WAVE          [4]byte
+		FMT           [4]byte
+		Subchunk1Size uint32
+		AudioFormat   uint16
+		NumChannels   uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		DATA          [4]byte
+		Subchunk2Size uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		ChunkSize:     uint32(36 + len(pcm)),
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		FMT:           [4]byte{'f', 'm', 't', ' '},
+		Subchunk1Size: 16,
+		AudioFormat:   1,
+		NumChannels:   1,
+		SampleRate:    uint32(sampleRate),
+		ByteRate:      uint32(sampleRate * 2),
+		BlockAlign:    2,
+		BitsPerSample: 16,
+		DATA:          [4]byte{'d', 'a', 't', 'a'},
+		Subchunk2Size: uint32(len(pcm)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	_, err := w.Write(pcm)
+	return err
+}
+
+// pcmRMS 计算 16bit 小端 PCM 的均方根幅值
+func pcmRMS(pcm []byte) float64 {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		sum += sample * sample
+	}
+	return math.Sqrt(sum / float64(samples))
+}
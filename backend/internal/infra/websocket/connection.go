@@ -2,14 +2,25 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hoshea/orion-backend/internal/domain"
+	"github.com/hoshea/orion-backend/internal/infra/metrics"
 )
 
+// ErrSendBufferFull 发送缓冲区已满，消息被丢弃
+var ErrSendBufferFull = errors.New("send buffer full")
+
+// ErrConnectionClosed 连接已关闭
+var ErrConnectionClosed = errors.New("connection closed")
+
+// sendWaitInterval 发送缓冲已满时重试的间隔
+const sendWaitInterval = 50 * time.Millisecond
+
 // Connection WebSocket 连接封装
 type Connection struct {
 	ID         string
@@ -32,6 +43,13 @@ func NewConnection(id string, conn *websocket.Conn) *Connection {
 
 // ReadPump 读取客户端消息
 func (c *Connection) ReadPump(handleMessage func([]byte)) {
+	c.ReadFrames(func(_ bool, message []byte) {
+		handleMessage(message)
+	})
+}
+
+// ReadFrames 读取客户端消息，binary 表示二进制帧
+func (c *Connection) ReadFrames(handleMessage func(binary bool, message []byte)) {
 	defer func() {
 		c.Close()
 	}()
@@ -44,7 +62,7 @@ func (c *Connection) ReadPump(handleMessage func([]byte)) {
 	})
 
 	for {
-		_, message, err := c.conn.ReadMessage()
+		messageType, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket read error: %v", err)
@@ -52,7 +70,7 @@ func (c *Connection) ReadPump(handleMessage func([]byte)) {
 			break
 		}
 
-		handleMessage(message)
+		handleMessage(messageType == websocket.BinaryMessage, message)
 	}
 }
 
@@ -61,6 +79,8 @@ func (c *Connection) WritePump() {
 	defer func() {
 		c.pingTicker.Stop()
 		c.Close()
+		// Drain 后 Close 不再关闭底层连接，这里保证写入结束时断开
+		c.conn.Close()
 	}()
 
 	for {
@@ -74,6 +94,7 @@ func (c *Connection) WritePump() {
 			}
 
 			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				metrics.IncWebSocketSendFailure(metrics.SendWriteError)
 				log.Printf("WebSocket write error: %v", err)
 				return
 			}
@@ -87,13 +108,38 @@ func (c *Connection) WritePump() {
 	}
 }
 
-// SendMessage 发送消息
+// SendMessage 发送消息，发送缓冲已满时丢弃并返回 ErrSendBufferFull
 func (c *Connection) SendMessage(msg *domain.WebSocketMessage) error {
+	err := c.enqueue(msg)
+	switch {
+	case errors.Is(err, ErrConnectionClosed):
+		return nil
+	case errors.Is(err, ErrSendBufferFull):
+		metrics.IncWebSocketSendFailure(metrics.SendBufferFull)
+		log.Printf("Warning: send buffer full for connection %s", c.ID)
+	}
+	return err
+}
+
+// SendMessageWait 发送消息，发送缓冲已满时等待写入协程腾出空间，连接关闭时返回 ErrConnectionClosed
+// 慢速客户端的积压因此留在调用方的队列中，调用方可据队列长度判断连接是否跟不上
+func (c *Connection) SendMessageWait(msg *domain.WebSocketMessage) error {
+	for {
+		err := c.enqueue(msg)
+		if !errors.Is(err, ErrSendBufferFull) {
+			return err
+		}
+		time.Sleep(sendWaitInterval)
+	}
+}
+
+// enqueue 非阻塞写入发送缓冲
+func (c *Connection) enqueue(msg *domain.WebSocketMessage) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.closed {
-		return nil
+		return ErrConnectionClosed
 	}
 
 	msg.Timestamp = time.Now()
@@ -106,9 +152,7 @@ func (c *Connection) SendMessage(msg *domain.WebSocketMessage) error {
 	case c.send <- data:
 		return nil
 	default:
-		// Channel 已满
-		log.Printf("Warning: send buffer full for connection %s", c.ID)
-		return nil
+		return ErrSendBufferFull
 	}
 }
 
@@ -133,6 +177,18 @@ func (c *Connection) Close() {
 	}
 }
 
+// Drain 停止接收新消息，已排队的消息发送完毕后发送关闭帧并断开连接
+// 需要在 WritePump 运行时调用
+func (c *Connection) Drain() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
 // IsClosed 检查连接是否已关闭
 func (c *Connection) IsClosed() bool {
 	c.mu.Lock()
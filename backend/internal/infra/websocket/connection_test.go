@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hoshea/orion-backend/internal/domain"
+)
+
+// newTestConnection 建立一条真实的 WebSocket 连接，返回服务端连接与客户端连接
+func newTestConnection(t *testing.T) (*Connection, *websocket.Conn) {
+	t.Helper()
+	serverConns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		serverConns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return NewConnection("test", <-serverConns), client
+}
+
+func TestConnection_SendMessageWait(t *testing.T) {
+	conn, client := newTestConnection(t)
+	msg := func() *domain.WebSocketMessage {
+		return &domain.WebSocketMessage{Type: domain.MessageTypeState, Payload: domain.StatePayload{Status: "STREAMING"}}
+	}
+
+	// 写入协程未启动，填满发送缓冲
+	for i := 0; i < cap(conn.send); i++ {
+		if err := conn.SendMessage(msg()); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	if err := conn.SendMessage(msg()); !errors.Is(err, ErrSendBufferFull) {
+		t.Fatalf("SendMessage() on full buffer error = %v, want ErrSendBufferFull", err)
+	}
+
+	// 缓冲已满时等待，写入协程腾出空间后送达
+	sent := make(chan error, 1)
+	go func() { sent <- conn.SendMessageWait(msg()) }()
+	select {
+	case err := <-sent:
+		t.Fatalf("SendMessageWait() returned %v before buffer drained", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	go conn.WritePump()
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case err := <-sent:
+		if err != nil {
+			t.Fatalf("SendMessageWait() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SendMessageWait() did not return after buffer drained")
+	}
+
+	conn.Close()
+	if err := conn.SendMessageWait(msg()); !errors.Is(err, ErrConnectionClosed) {
+		t.Fatalf("SendMessageWait() after close error = %v, want ErrConnectionClosed", err)
+	}
+}
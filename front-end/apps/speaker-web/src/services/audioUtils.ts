@@ -47,15 +47,19 @@ export function floatTo16BitPCM(floatArray: Float32Array): Int16Array {
   return buffer;
 }
 
-export function pcm16ToBase64(pcm16: Int16Array): string {
-  const bytes = new Uint8Array(pcm16.buffer);
-  const chunkSize = 0x8000;
-  let binary = "";
-  for (let offset = 0; offset < bytes.length; offset += chunkSize) {
-    const chunk = bytes.subarray(offset, offset + chunkSize);
-    binary += String.fromCharCode(...chunk);
-  }
-  return btoa(binary);
+/**
+ * 编码二进制音频帧：1 字节版本号 + 4 字节大端序列号 + PCM 数据。
+ */
+const AUDIO_FRAME_VERSION = 1;
+const AUDIO_FRAME_HEADER_SIZE = 5;
+
+export function encodeAudioFrame(sequence: number, pcm16: Int16Array): ArrayBuffer {
+  const frame = new Uint8Array(AUDIO_FRAME_HEADER_SIZE + pcm16.byteLength);
+  const view = new DataView(frame.buffer);
+  view.setUint8(0, AUDIO_FRAME_VERSION);
+  view.setUint32(1, sequence >>> 0, false);
+  frame.set(new Uint8Array(pcm16.buffer, pcm16.byteOffset, pcm16.byteLength), AUDIO_FRAME_HEADER_SIZE);
+  return frame.buffer;
 }
 
 export function calculateRMS(sample: Float32Array): number {
@@ -4,8 +4,8 @@ import { envConfig } from "@/config/env";
 import {
   calculateRMS,
   downsampleBuffer,
-  floatTo16BitPCM,
-  pcm16ToBase64
+  encodeAudioFrame,
+  floatTo16BitPCM
 } from "@/services/audioUtils";
 import {
   loadSpeakerToken,
@@ -47,6 +47,16 @@ export interface SubtitleItem {
 
 type StreamingStatus = "idle" | "connecting" | "streaming";
 
+interface PendingFrame {
+  sequence: number;
+  data: ArrayBuffer;
+}
+
+// 未被服务端确认的音频帧最多缓存约 200 帧（4096 采样/帧，约 17 秒），断线重连后补发
+const MAX_PENDING_FRAMES = 200;
+const MAX_RECONNECT_ATTEMPTS = 5;
+const RECONNECT_BASE_DELAY_MS = 1000;
+
 export const useSpeakerSessionStore = defineStore("speakerSession", () => {
   const currentActivity = ref<ActivitySummary | null>(null);
   const streamingStatus = ref<StreamingStatus>("idle");
@@ -66,6 +76,9 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
 
   const sequence = ref(0);
   const reconnectAttempts = ref(0);
+  let pendingFrames: PendingFrame[] = [];
+  let resendOnAck = false;
+  let reconnectTimer: ReturnType<typeof setTimeout> | null = null;
 
   const speakableLanguages = computed(() => currentActivity.value?.targetLanguages ?? []);
   const isStreaming = computed(() => streamingStatus.value === "streaming");
@@ -241,19 +254,16 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
     }
   }
 
-  async function establishWebSocket(url: string) {
+  async function establishWebSocket(url: string, sendStart = true) {
     return new Promise<void>((resolve, reject) => {
       const ws = new WebSocket(url);
       websocket.value = ws;
 
       const handleOpen = () => {
         ws.removeEventListener("error", handleError);
-        ws.send(
-          JSON.stringify({
-            type: "CONTROL",
-            payload: { action: "START" }
-          })
-        );
+        if (sendStart) {
+          sendControl(ws, "START");
+        }
         resolve();
       };
 
@@ -267,6 +277,14 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
 
       ws.onmessage = (event) => handleSocketMessage(event.data);
       ws.onclose = () => {
+        if (websocket.value !== ws) {
+          return;
+        }
+        websocket.value = null;
+        // 推流中意外断开：保留采集继续缓存音频，服务端在宽限期内保留会话
+        if (streamingStatus.value === "streaming" && scheduleReconnect(url)) {
+          return;
+        }
         if (streamingStatus.value === "streaming") {
           updateConnection({
             status: "degraded",
@@ -285,6 +303,54 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
     });
   }
 
+  function scheduleReconnect(url: string) {
+    if (reconnectAttempts.value >= MAX_RECONNECT_ATTEMPTS) {
+      return false;
+    }
+    reconnectAttempts.value += 1;
+    const delay = RECONNECT_BASE_DELAY_MS * 2 ** (reconnectAttempts.value - 1);
+    updateConnection({
+      status: "reconnecting",
+      reconnectAttempts: reconnectAttempts.value,
+      stateMessage: `连接已断开，${delay / 1000} 秒后进行第 ${reconnectAttempts.value} 次重连...`
+    });
+
+    reconnectTimer = setTimeout(() => {
+      reconnectTimer = null;
+      if (streamingStatus.value !== "streaming") {
+        return;
+      }
+      // 重连后暂停发送新帧，收到 ACK 后从确认位置按序补发
+      resendOnAck = true;
+      establishWebSocket(url, false).catch(() => {
+        // 连接失败时 onclose 会安排下一次重连
+      });
+    }, delay);
+    return true;
+  }
+
+  function handleAck(acked: number, resumed: boolean) {
+    pendingFrames = pendingFrames.filter((frame) => frame.sequence > acked);
+    if (!resendOnAck) {
+      return;
+    }
+    // 服务端每次连接都会先发送 ACK；resumed 为 false 表示原会话已结束（宽限期已过、服务重启或连到其他实例），
+    // 此时 acked 为 0，缓存的音频全部补发到新会话
+    resendOnAck = false;
+    reconnectAttempts.value = 0;
+    if (!resumed) {
+      updateConnection({
+        status: "connected",
+        stateMessage: "原会话已结束，已开启新会话继续推流"
+      });
+    }
+    const ws = websocket.value;
+    if (!ws || ws.readyState !== WebSocket.OPEN) {
+      return;
+    }
+    pendingFrames.forEach((frame) => ws.send(frame.data));
+  }
+
   function handleSocketMessage(raw: string) {
     let parsed: { type: string; payload?: any };
     try {
@@ -302,11 +368,15 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
           status: mapStateToSnapshot(status),
           stateMessage: message
         });
-        if (status === "STOPPED") {
+        if (status === "STOPPED" || status === "ENDED") {
           streamingStatus.value = "idle";
         }
         break;
       }
+      case "ACK": {
+        handleAck(Number(parsed.payload?.sequence ?? 0), Boolean(parsed.payload?.resumed));
+        break;
+      }
       case "ERROR": {
         const message = parsed.payload?.message ?? "推流出现未知错误";
         lastError.value = message;
@@ -317,6 +387,14 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
         streamingStatus.value = "idle";
         break;
       }
+      case "AUDIO_GAP": {
+        const missing = Number(parsed.payload?.missing ?? 0);
+        console.warn(
+          parsed.payload?.outOfOrder ? "音频块乱序，已丢弃" : `音频块丢失 ${missing} 个`,
+          parsed.payload
+        );
+        break;
+      }
       case "SUBTITLE": {
         const payload = parsed.payload ?? {};
         const subtitle: SubtitleItem = {
@@ -334,8 +412,7 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
   }
 
   function handleAudioFrame(channelData: Float32Array, sampleRate: number) {
-    const ws = websocket.value;
-    if (!ws || ws.readyState !== WebSocket.OPEN || streamingStatus.value !== "streaming") {
+    if (streamingStatus.value !== "streaming") {
       return;
     }
 
@@ -345,21 +422,17 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
     }
 
     const pcm16 = floatTo16BitPCM(downsampled);
-    const base64 = pcm16ToBase64(pcm16);
-    if (!base64) {
-      return;
+    sequence.value += 1;
+    const frame = encodeAudioFrame(sequence.value, pcm16);
+    pendingFrames.push({ sequence: sequence.value, data: frame });
+    if (pendingFrames.length > MAX_PENDING_FRAMES) {
+      pendingFrames.splice(0, pendingFrames.length - MAX_PENDING_FRAMES);
     }
 
-    sequence.value += 1;
-    ws.send(
-      JSON.stringify({
-        type: "AUDIO",
-        payload: {
-          chunk: base64,
-          sequence: sequence.value
-        }
-      })
-    );
+    const ws = websocket.value;
+    if (ws && ws.readyState === WebSocket.OPEN && !resendOnAck) {
+      ws.send(frame);
+    }
 
     const rms = calculateRMS(downsampled);
     updateMicLevel(Math.min(1, rms * 8));
@@ -373,7 +446,10 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
     const params = new URLSearchParams({
       activityId,
       token,
-      language
+      language,
+      encoding: "LINEAR16",
+      sampleRate: "16000",
+      channels: "1"
     });
     return `${base}/ws/speaker?${params.toString()}`;
   }
@@ -382,15 +458,19 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
     switch (state) {
       case "READY":
       case "STREAMING":
+      case "PAUSED":
         return "connected";
       case "STOPPED":
+      case "ENDED":
         return "idle";
+      case "RECONNECTING":
+        return "reconnecting";
       default:
         return "degraded";
     }
   }
 
-  function sendControl(ws: WebSocket, action: "START" | "STOP") {
+  function sendControl(ws: WebSocket, action: "START" | "PAUSE" | "RESUME" | "STOP") {
     if (ws.readyState !== WebSocket.OPEN) return;
     ws.send(
       JSON.stringify({
@@ -401,6 +481,13 @@ export const useSpeakerSessionStore = defineStore("speakerSession", () => {
   }
 
   function cleanupStreamingResources() {
+    if (reconnectTimer) {
+      clearTimeout(reconnectTimer);
+      reconnectTimer = null;
+    }
+    pendingFrames = [];
+    resendOnAck = false;
+
     if (processorNode) {
       processorNode.disconnect();
       processorNode.onaudioprocess = null;